@@ -0,0 +1,271 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package abi
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/riceriley59/goanywhere/internal/core"
+	"github.com/riceriley59/goanywhere/plugins/cgo"
+)
+
+func TestABI(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ABI Suite")
+}
+
+func intType() core.ParsedType {
+	return core.ParsedType{Kind: core.KindPrimitive, Name: "int"}
+}
+
+func testPackage() *core.ParsedPackage {
+	return &core.ParsedPackage{
+		Name:       "geo",
+		ImportPath: "example.com/geo",
+		Dir:        "/src/geo",
+		Functions: []core.ParsedFunc{
+			{
+				Name:    "Add",
+				Params:  []core.ParsedParam{{Name: "a", Type: intType()}, {Name: "b", Type: intType()}},
+				Results: []core.ParsedResult{{Type: intType()}},
+			},
+		},
+		Structs: []core.ParsedStruct{
+			{
+				Name:   "Point",
+				Fields: []core.ParsedField{{Name: "X", Type: intType(), Exported: true}},
+			},
+		},
+	}
+}
+
+func snapshotOf(pkg *core.ParsedPackage) *Snapshot {
+	snapshot, err := NewSnapshot(cgo.NewPlugin(false), pkg)
+	Expect(err).NotTo(HaveOccurred())
+	return snapshot
+}
+
+var _ = Describe("Snapshot", func() {
+	It("records the lowered exports", func() {
+		snapshot := snapshotOf(testPackage())
+		Expect(snapshot.Plugin).To(Equal("cgo"))
+		Expect(snapshot.Package.Dir).To(BeEmpty())
+
+		var symbols []string
+		for _, export := range snapshot.Exports {
+			symbols = append(symbols, export.Symbol)
+		}
+		Expect(symbols).To(Equal([]string{
+			"Free_String", "Free_Bytes", "Retain_Handle", "geo_Add", "Point_New", "Point_Free", "Point_GetX", "Point_SetX",
+			"Point_ToJSON", "Point_FromJSON",
+		}))
+	})
+
+	It("round-trips through JSON", func() {
+		tmpDir := GinkgoT().TempDir()
+		path := filepath.Join(tmpDir, "abi.json")
+
+		snapshot := snapshotOf(testPackage())
+		Expect(snapshot.Write(path)).To(Succeed())
+
+		loaded, err := Load(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded).To(Equal(snapshot))
+	})
+
+	It("rejects unknown versions", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "abi.json")
+		Expect(os.WriteFile(path, []byte(`{"version": 99}`), 0644)).To(Succeed())
+
+		_, err := Load(path)
+		Expect(err).To(MatchError(ContainSubstring("unsupported version 99")))
+	})
+
+	It("requires a plugin describing its ABI", func() {
+		_, err := NewSnapshot(fakePlugin{}, testPackage())
+		Expect(err).To(MatchError(ContainSubstring("cannot describe its ABI")))
+	})
+})
+
+var _ = Describe("Diff", func() {
+	var (
+		oldPkg *core.ParsedPackage
+		newPkg *core.ParsedPackage
+	)
+
+	BeforeEach(func() {
+		oldPkg = testPackage()
+		newPkg = testPackage()
+	})
+
+	diff := func() []Change {
+		changes, err := Diff(snapshotOf(oldPkg), snapshotOf(newPkg))
+		Expect(err).NotTo(HaveOccurred())
+		return changes
+	}
+
+	It("reports nothing for identical packages", func() {
+		Expect(diff()).To(BeEmpty())
+	})
+
+	It("marks removed exports as breaking", func() {
+		newPkg.Structs[0].Fields = nil
+
+		// Without data fields the struct also loses its JSON conversions
+		changes := diff()
+		Expect(changes).To(HaveLen(4))
+		Expect(changes[0]).To(Equal(Change{
+			Severity: Breaking, Kind: Removed, Symbol: "Point_GetX", ExportKind: core.ExportGetter, Source: "Point.X",
+		}))
+		Expect(changes[1].Symbol).To(Equal("Point_SetX"))
+		Expect(changes[2].Symbol).To(Equal("Point_ToJSON"))
+		Expect(changes[3].Symbol).To(Equal("Point_FromJSON"))
+		Expect(CountBreaking(changes)).To(Equal(4))
+	})
+
+	It("marks added exports as compatible", func() {
+		newPkg.Functions = append(newPkg.Functions, core.ParsedFunc{Name: "Zero", Results: []core.ParsedResult{{Type: intType()}}})
+
+		changes := diff()
+		Expect(changes).To(HaveLen(1))
+		Expect(changes[0].Severity).To(Equal(Compatible))
+		Expect(changes[0].Kind).To(Equal(Added))
+		Expect(changes[0].Symbol).To(Equal("geo_Zero"))
+	})
+
+	It("marks changed parameter and field types as breaking", func() {
+		float := core.ParsedType{Kind: core.KindPrimitive, Name: "float64"}
+		newPkg.Functions[0].Params[1].Type = float
+		newPkg.Structs[0].Fields[0].Type = float
+
+		changes := diff()
+		Expect(changes).To(HaveLen(3))
+		Expect(changes[0].Symbol).To(Equal("geo_Add"))
+		Expect(changes[0].Severity).To(Equal(Breaking))
+		Expect(changes[0].Details).To(Equal([]string{"parameter b: C.longlong (int) -> C.double (float64)"}))
+		Expect(changes[1].Symbol).To(Equal("Point_GetX"))
+		Expect(changes[1].Details).To(Equal([]string{"result: C.longlong (int) -> C.double (float64)"}))
+		Expect(changes[2].Symbol).To(Equal("Point_SetX"))
+	})
+
+	It("marks added parameters and results as breaking", func() {
+		newPkg.Functions[0].Params = append(newPkg.Functions[0].Params, core.ParsedParam{Name: "c", Type: intType()})
+		newPkg.Functions[0].Results = append(newPkg.Functions[0].Results, core.ParsedResult{Type: core.ParsedType{Kind: core.KindError, Name: "error"}})
+
+		changes := diff()
+		Expect(changes).To(HaveLen(1))
+		Expect(changes[0].Severity).To(Equal(Breaking))
+		Expect(changes[0].Details[0]).To(HavePrefix("parameters: (a C.longlong (int), b C.longlong (int)) -> "))
+	})
+
+	It("marks renamed parameters as compatible", func() {
+		newPkg.Functions[0].Params[0].Name = "x"
+
+		changes := diff()
+		Expect(changes).To(HaveLen(1))
+		Expect(changes[0].Severity).To(Equal(Compatible))
+		Expect(changes[0].Details).To(Equal([]string{"parameter 1 renamed: a -> x"}))
+	})
+
+	It("marks changed handle types as breaking", func() {
+		line := core.ParsedStruct{Name: "Line"}
+		oldPkg.Structs = append(oldPkg.Structs, line)
+		newPkg.Structs = append(newPkg.Structs, line)
+		pointer := func(name string) core.ParsedType {
+			elem := core.ParsedType{Kind: core.KindStruct, Name: name}
+			return core.ParsedType{Kind: core.KindPointer, Name: "*" + name, ElemType: &elem, IsPointer: true}
+		}
+		oldPkg.Functions[0].Params[0].Type = pointer("Point")
+		newPkg.Functions[0].Params[0].Type = pointer("Line")
+
+		changes := diff()
+		Expect(changes).To(HaveLen(1))
+		Expect(changes[0].Severity).To(Equal(Breaking))
+		Expect(changes[0].Details).To(Equal([]string{"parameter a: handle type *Point -> *Line"}))
+	})
+
+	It("marks changed ownership of returned handles as breaking", func() {
+		elem := core.ParsedType{Kind: core.KindStruct, Name: "Point"}
+		point := core.ParsedType{Kind: core.KindPointer, Name: "*Point", ElemType: &elem, IsPointer: true}
+		method := func(ownership core.Ownership) []core.ParsedMethod {
+			return []core.ParsedMethod{{Name: "Scale", ReceiverType: "Point", ReceiverIsPtr: true, Results: []core.ParsedResult{{Type: point, Ownership: ownership}}}}
+		}
+		oldPkg.Structs[0].Methods = method(core.OwnershipBorrowed)
+		newPkg.Structs[0].Methods = method(core.OwnershipOwned)
+
+		changes := diff()
+		Expect(changes).To(HaveLen(1))
+		Expect(changes[0].Severity).To(Equal(Breaking))
+		Expect(changes[0].Details).To(Equal([]string{"result: ownership borrowed -> owned"}))
+	})
+
+	It("marks Go type changes with the same lowering as compatible", func() {
+		newPkg.Functions[0].Params[0].Type.Named = "Meters"
+
+		changes := diff()
+		Expect(changes).To(HaveLen(1))
+		Expect(changes[0].Severity).To(Equal(Compatible))
+		Expect(changes[0].Details).To(Equal([]string{"parameter a: Go type int -> Meters, same C type C.longlong"}))
+	})
+
+	It("lists breaking changes first", func() {
+		newPkg.Functions[0].Name = "Plus"
+
+		changes := diff()
+		Expect(changes).To(HaveLen(2))
+		Expect(changes[0].Kind).To(Equal(Removed))
+		Expect(changes[1].Kind).To(Equal(Added))
+	})
+
+	It("rejects snapshots of different plugins", func() {
+		other := snapshotOf(newPkg)
+		other.Plugin = "python"
+		_, err := Diff(snapshotOf(oldPkg), other)
+		Expect(err).To(MatchError(ContainSubstring("different plugins")))
+	})
+
+	Describe("WriteReport", func() {
+		It("prints changes with a summary", func() {
+			newPkg.Functions[0].Params[1].Type = core.ParsedType{Kind: core.KindPrimitive, Name: "float64"}
+
+			var out bytes.Buffer
+			WriteReport(&out, diff())
+			Expect(out.String()).To(Equal("BREAKING    changed  geo_Add (function Add)\n" +
+				"            parameter b: C.longlong (int) -> C.double (float64)\n" +
+				"\n1 breaking, 0 compatible changes\n"))
+		})
+
+		It("says when nothing changed", func() {
+			var out bytes.Buffer
+			WriteReport(&out, nil)
+			Expect(out.String()).To(Equal("No ABI changes\n"))
+		})
+	})
+})
+
+// fakePlugin is a plugin without ABI description
+type fakePlugin struct{}
+
+func (fakePlugin) Name() string { return "fake" }
+
+func (fakePlugin) Generate(*core.ParsedPackage) ([]byte, error) { return nil, nil }
+
+func (fakePlugin) Build(*core.ParsedPackage, string, *core.BuildOptions) error { return nil }
@@ -0,0 +1,203 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package abi
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/riceriley59/goanywhere/internal/core"
+)
+
+// Severity tells whether a change breaks existing consumers
+type Severity string
+
+const (
+	Breaking   Severity = "breaking"
+	Compatible Severity = "compatible"
+)
+
+// ChangeKind describes what happened to an export
+type ChangeKind string
+
+const (
+	Removed ChangeKind = "removed"
+	Added   ChangeKind = "added"
+	Changed ChangeKind = "changed"
+)
+
+// handleType is the lowered type of struct handles; handles of different Go
+// types are not interchangeable although their C type is the same
+const handleType = "C.uintptr_t"
+
+// Change is a difference in one exported symbol
+type Change struct {
+	Severity   Severity        `json:"severity"`
+	Kind       ChangeKind      `json:"kind"`
+	Symbol     string          `json:"symbol"`
+	ExportKind core.ExportKind `json:"export_kind"`
+	Source     string          `json:"source,omitempty"`
+	Details    []string        `json:"details,omitempty"`
+}
+
+// Diff compares the exports of two snapshots. Breaking changes come first.
+func Diff(old, new *Snapshot) ([]Change, error) {
+	if old.Plugin != new.Plugin {
+		return nil, fmt.Errorf("snapshots were lowered by different plugins (%s, %s)", old.Plugin, new.Plugin)
+	}
+
+	newExports := make(map[string]*core.Export, len(new.Exports))
+	for i := range new.Exports {
+		newExports[new.Exports[i].Symbol] = &new.Exports[i]
+	}
+	oldExports := make(map[string]bool, len(old.Exports))
+
+	var changes []Change
+	for i := range old.Exports {
+		before := &old.Exports[i]
+		oldExports[before.Symbol] = true
+
+		after, ok := newExports[before.Symbol]
+		if !ok {
+			changes = append(changes, newChange(Breaking, Removed, before))
+			continue
+		}
+		if change, ok := compareExports(before, after); ok {
+			changes = append(changes, change)
+		}
+	}
+	for i := range new.Exports {
+		if !oldExports[new.Exports[i].Symbol] {
+			changes = append(changes, newChange(Compatible, Added, &new.Exports[i]))
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Severity == Breaking && changes[j].Severity != Breaking
+	})
+	return changes, nil
+}
+
+// CountBreaking returns the number of breaking changes
+func CountBreaking(changes []Change) int {
+	n := 0
+	for _, change := range changes {
+		if change.Severity == Breaking {
+			n++
+		}
+	}
+	return n
+}
+
+func newChange(severity Severity, kind ChangeKind, export *core.Export) Change {
+	return Change{
+		Severity:   severity,
+		Kind:       kind,
+		Symbol:     export.Symbol,
+		ExportKind: export.Kind,
+		Source:     export.Source,
+	}
+}
+
+// compareExports reports the differences between two versions of a symbol
+func compareExports(before, after *core.Export) (Change, bool) {
+	change := newChange(Compatible, Changed, after)
+	note := func(severity Severity, format string, args ...interface{}) {
+		if severity == Breaking {
+			change.Severity = Breaking
+		}
+		change.Details = append(change.Details, fmt.Sprintf(format, args...))
+	}
+
+	if len(before.Params) != len(after.Params) {
+		note(Breaking, "parameters: (%s) -> (%s)", formatParams(before.Params), formatParams(after.Params))
+	} else {
+		for i := range before.Params {
+			comparePart(note, "parameter "+after.Params[i].Name, &before.Params[i], &after.Params[i])
+			if before.Params[i].Name != after.Params[i].Name {
+				note(Compatible, "parameter %d renamed: %s -> %s", i+1, before.Params[i].Name, after.Params[i].Name)
+			}
+		}
+	}
+
+	switch {
+	case before.Result == nil && after.Result != nil:
+		note(Breaking, "result: void -> %s", formatParam(after.Result))
+	case before.Result != nil && after.Result == nil:
+		note(Breaking, "result: %s -> void", formatParam(before.Result))
+	case before.Result != nil:
+		comparePart(note, "result", before.Result, after.Result)
+	}
+
+	return change, len(change.Details) > 0
+}
+
+// comparePart compares the types of a parameter or result
+func comparePart(note func(Severity, string, ...interface{}), what string, before, after *core.ExportParam) {
+	switch {
+	case before.Type != after.Type:
+		note(Breaking, "%s: %s -> %s", what, formatParam(before), formatParam(after))
+	case before.GoType != after.GoType && after.Type == handleType:
+		note(Breaking, "%s: handle type %s -> %s", what, before.GoType, after.GoType)
+	case before.GoType != after.GoType:
+		note(Compatible, "%s: Go type %s -> %s, same C type %s", what, before.GoType, after.GoType, after.Type)
+	case before.Ownership != after.Ownership && before.Ownership != core.OwnershipNone && after.Ownership != core.OwnershipNone:
+		// Callers release owned handles but not borrowed ones
+		note(Breaking, "%s: ownership %s -> %s", what, before.Ownership, after.Ownership)
+	}
+}
+
+func formatParam(p *core.ExportParam) string {
+	if p.GoType == "" {
+		return p.Type
+	}
+	return fmt.Sprintf("%s (%s)", p.Type, p.GoType)
+}
+
+func formatParams(params []core.ExportParam) string {
+	parts := make([]string, len(params))
+	for i := range params {
+		parts[i] = params[i].Name + " " + formatParam(&params[i])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// WriteReport prints the changes for humans
+func WriteReport(w io.Writer, changes []Change) {
+	if len(changes) == 0 {
+		_, _ = fmt.Fprintln(w, "No ABI changes")
+		return
+	}
+
+	for _, change := range changes {
+		label := string(change.Severity)
+		if change.Severity == Breaking {
+			label = "BREAKING"
+		}
+		source := string(change.ExportKind)
+		if change.Source != "" {
+			source += " " + change.Source
+		}
+		_, _ = fmt.Fprintf(w, "%-10s  %-7s  %s (%s)\n", label, change.Kind, change.Symbol, source)
+		for _, detail := range change.Details {
+			_, _ = fmt.Fprintf(w, "            %s\n", detail)
+		}
+	}
+
+	breaking := CountBreaking(changes)
+	_, _ = fmt.Fprintf(w, "\n%d breaking, %d compatible changes\n", breaking, len(changes)-breaking)
+}
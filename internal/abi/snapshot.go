@@ -0,0 +1,89 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package abi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/riceriley59/goanywhere/internal/core"
+)
+
+// FormatVersion is the version of the snapshot file format
+const FormatVersion = 1
+
+// Snapshot is the ABI of a package as lowered by a plugin: the parsed Go
+// declarations and the symbols generated for them
+type Snapshot struct {
+	Version int                 `json:"version"`
+	Plugin  string              `json:"plugin"`
+	Package *core.ParsedPackage `json:"package"`
+	Exports []core.Export       `json:"exports"`
+}
+
+// NewSnapshot lowers pkg with the plugin and records its exports
+func NewSnapshot(plugin core.Plugin, pkg *core.ParsedPackage) (*Snapshot, error) {
+	describer, ok := plugin.(core.ABIDescriber)
+	if !ok {
+		return nil, fmt.Errorf("plugin %s cannot describe its ABI", plugin.Name())
+	}
+
+	exports, err := describer.Exports(pkg)
+	if err != nil {
+		return nil, fmt.Errorf("lowering error: %w", err)
+	}
+
+	// The source directory differs between checkouts and machines
+	recorded := *pkg
+	recorded.Dir = ""
+
+	return &Snapshot{
+		Version: FormatVersion,
+		Plugin:  plugin.Name(),
+		Package: &recorded,
+		Exports: exports,
+	}, nil
+}
+
+// Load reads a snapshot written by Write
+func Load(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read ABI snapshot: %w", err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("invalid ABI snapshot %s: %w", path, err)
+	}
+	if snapshot.Version != FormatVersion {
+		return nil, fmt.Errorf("invalid ABI snapshot %s: unsupported version %d", path, snapshot.Version)
+	}
+
+	return &snapshot, nil
+}
+
+// Write saves the snapshot as indented JSON
+func (s *Snapshot) Write(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot encode ABI snapshot: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("write error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Cache stores build outputs under a content-addressed key
+type Cache struct {
+	Root string
+}
+
+// Dir returns the cache directory: $XDG_CACHE_HOME/goanywhere, or goanywhere
+// under the user cache directory when XDG_CACHE_HOME is unset
+func Dir() (string, error) {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "goanywhere"), nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine cache directory: %w", err)
+	}
+	return filepath.Join(dir, "goanywhere"), nil
+}
+
+// Open returns the cache in Dir
+func Open() (*Cache, error) {
+	root, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	return &Cache{Root: root}, nil
+}
+
+// CachedBuild is a cached build: the files it wrote and its metadata
+type CachedBuild struct {
+	Dir string
+}
+
+// metaFile and filesDir are the contents of an entry directory
+const (
+	metaFile = "meta.json"
+	filesDir = "files"
+)
+
+// entryDir returns the directory of the entry for key
+func (c *Cache) entryDir(key string) string {
+	return filepath.Join(c.Root, "builds", key[:2], key)
+}
+
+// Get returns the entry stored for key
+func (c *Cache) Get(key string) (*CachedBuild, bool) {
+	dir := c.entryDir(key)
+	if _, err := os.Stat(filepath.Join(dir, metaFile)); err != nil {
+		return nil, false
+	}
+	return &CachedBuild{Dir: dir}, true
+}
+
+// Put stores the files of srcDir, given relative to it, and meta as the
+// entry for key. Entries are written to a temporary directory and renamed
+// into place, so concurrent builds never see a partial entry.
+func (c *Cache) Put(key, srcDir string, files []string, meta []byte) error {
+	dir := c.entryDir(key)
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return fmt.Errorf("cannot create cache directory: %w", err)
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), "tmp-")
+	if err != nil {
+		return fmt.Errorf("cannot create cache entry: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	for _, file := range files {
+		if err := copyFile(filepath.Join(srcDir, file), filepath.Join(tmp, filesDir, file)); err != nil {
+			return fmt.Errorf("cannot cache %s: %w", file, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(tmp, metaFile), meta, 0644); err != nil {
+		return fmt.Errorf("cannot write cache entry: %w", err)
+	}
+
+	if err := os.Rename(tmp, dir); err != nil {
+		if _, ok := c.Get(key); ok {
+			// Stored by a concurrent build
+			return nil
+		}
+		return fmt.Errorf("cannot store cache entry: %w", err)
+	}
+	return nil
+}
+
+// Meta returns the metadata stored with the entry
+func (b *CachedBuild) Meta() ([]byte, error) {
+	return os.ReadFile(filepath.Join(b.Dir, metaFile))
+}
+
+// Restore copies the files of the entry to dstDir and returns their paths
+// relative to it
+func (b *CachedBuild) Restore(dstDir string) ([]string, error) {
+	root := filepath.Join(b.Dir, filesDir)
+	var restored []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && path == root {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if err := copyFile(path, filepath.Join(dstDir, rel)); err != nil {
+			return err
+		}
+		restored = append(restored, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot restore cached build: %w", err)
+	}
+	return restored, nil
+}
+
+// Clean removes the cache directory
+func (c *Cache) Clean() error {
+	if err := os.RemoveAll(c.Root); err != nil {
+		return fmt.Errorf("cannot remove cache: %w", err)
+	}
+	return nil
+}
+
+// copyFile copies src to dst with its permissions, creating the parent
+// directories of dst
+func copyFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
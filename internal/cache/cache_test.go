@@ -0,0 +1,165 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCache(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cache Suite")
+}
+
+func writeFile(path, content string) {
+	Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+	Expect(os.WriteFile(path, []byte(content), 0644)).To(Succeed())
+}
+
+var _ = Describe("Cache", func() {
+	Describe("Dir", func() {
+		It("uses XDG_CACHE_HOME", func() {
+			GinkgoT().Setenv("XDG_CACHE_HOME", "/tmp/xdg")
+			Expect(Dir()).To(Equal(filepath.Join("/tmp/xdg", "goanywhere")))
+		})
+	})
+
+	Describe("Put and Get", func() {
+		var c *Cache
+		var srcDir string
+
+		BeforeEach(func() {
+			c = &Cache{Root: GinkgoT().TempDir()}
+			srcDir = GinkgoT().TempDir()
+			writeFile(filepath.Join(srcDir, "libgeo.so"), "library")
+			writeFile(filepath.Join(srcDir, "include", "geo.h"), "header")
+		})
+
+		It("misses an unknown key", func() {
+			_, ok := c.Get(NewKey().Sum())
+			Expect(ok).To(BeFalse())
+		})
+
+		It("restores the stored files and metadata", func() {
+			key := NewKey().Sum()
+			Expect(c.Put(key, srcDir, []string{"libgeo.so", filepath.Join("include", "geo.h")}, []byte("{}"))).To(Succeed())
+
+			entry, ok := c.Get(key)
+			Expect(ok).To(BeTrue())
+			Expect(entry.Meta()).To(Equal([]byte("{}")))
+
+			dstDir := GinkgoT().TempDir()
+			Expect(entry.Restore(dstDir)).To(ConsistOf("libgeo.so", filepath.Join("include", "geo.h")))
+			Expect(os.ReadFile(filepath.Join(dstDir, "include", "geo.h"))).To(Equal([]byte("header")))
+		})
+
+		It("keeps the first entry stored for a key", func() {
+			key := NewKey().Sum()
+			Expect(c.Put(key, srcDir, []string{"libgeo.so"}, []byte("first"))).To(Succeed())
+			Expect(c.Put(key, srcDir, []string{"libgeo.so"}, []byte("second"))).To(Succeed())
+
+			entry, _ := c.Get(key)
+			Expect(entry.Meta()).To(Equal([]byte("first")))
+		})
+
+		It("removes every entry on Clean", func() {
+			key := NewKey().Sum()
+			Expect(c.Put(key, srcDir, []string{"libgeo.so"}, []byte("{}"))).To(Succeed())
+			Expect(c.Clean()).To(Succeed())
+
+			_, ok := c.Get(key)
+			Expect(ok).To(BeFalse())
+			Expect(c.Root).NotTo(BeADirectory())
+		})
+	})
+
+	Describe("Key", func() {
+		It("depends on labels and the boundaries between inputs", func() {
+			a := NewKey()
+			a.AddString("a", "bc")
+			b := NewKey()
+			b.AddString("a", "b")
+			b.AddString("", "c")
+			c := NewKey()
+			c.AddString("b", "bc")
+
+			Expect(a.Sum()).NotTo(Equal(b.Sum()))
+			Expect(a.Sum()).NotTo(Equal(c.Sum()))
+		})
+
+		It("depends on the sources of a package", func() {
+			dir := GinkgoT().TempDir()
+			writeFile(filepath.Join(dir, "go.mod"), "module example.com/geo\n\ngo 1.21\n")
+			writeFile(filepath.Join(dir, "geo.go"), "package geo\n\nfunc Area() int { return 1 }\n")
+
+			sum := func() string {
+				key := NewKey()
+				Expect(key.AddPackageSources(dir, nil)).To(Succeed())
+				return key.Sum()
+			}
+			before := sum()
+			Expect(sum()).To(Equal(before))
+
+			writeFile(filepath.Join(dir, "geo.go"), "package geo\n\nfunc Area() int { return 2 }\n")
+			Expect(sum()).NotTo(Equal(before))
+		})
+	})
+
+	Describe("AddPackageSources", func() {
+		It("does not depend on where the package is checked out", func() {
+			sum := func(dir string) string {
+				writeFile(filepath.Join(dir, "go.mod"), "module example.com/geo\n\ngo 1.21\n")
+				writeFile(filepath.Join(dir, "geo.go"), "package geo\n\nfunc Area() int { return 1 }\n")
+				key := NewKey()
+				Expect(key.AddPackageSources(dir, nil)).To(Succeed())
+				return key.Sum()
+			}
+			root := GinkgoT().TempDir()
+			Expect(sum(filepath.Join(root, "a", "geo"))).To(Equal(sum(filepath.Join(root, "checkout", "geo"))))
+		})
+	})
+
+	Describe("Snapshot", func() {
+		It("reports the files written since it was taken", func() {
+			dir := filepath.Join(GinkgoT().TempDir(), "build")
+			snapshot, err := TakeSnapshot(dir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(snapshot).To(BeEmpty())
+
+			writeFile(filepath.Join(dir, "kept.txt"), "kept")
+			snapshot, err = TakeSnapshot(dir)
+			Expect(err).NotTo(HaveOccurred())
+
+			writeFile(filepath.Join(dir, "lib", "libgeo.so"), "library")
+			writeFile(filepath.Join(dir, "kept.txt"), "changed")
+			Expect(snapshot.Written(dir)).To(Equal([]string{"kept.txt", filepath.Join("lib", "libgeo.so")}))
+		})
+
+		It("reports added, modified and removed files", func() {
+			now := time.Now()
+			old := Snapshot{"a.go": {now, 1}, "b.go": {now, 2}}
+			current := Snapshot{"a.go": {now, 1}, "b.go": {now, 3}, "c.go": {now, 1}}
+			Expect(old.Changed(current)).To(Equal([]string{"b.go", "c.go"}))
+			Expect(current.Changed(old)).To(Equal([]string{"b.go", "c.go"}))
+			Expect(old.Changed(old)).To(BeEmpty())
+		})
+	})
+})
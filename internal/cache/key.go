@@ -0,0 +1,178 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Key accumulates the inputs of a build into a content-addressed key
+type Key struct {
+	h hash.Hash
+}
+
+// NewKey returns an empty key
+func NewKey() *Key {
+	return &Key{h: sha256.New()}
+}
+
+// Add hashes a labeled input. Labels and lengths are hashed too, so inputs
+// cannot run into each other.
+func (k *Key) Add(label string, data []byte) {
+	_, _ = fmt.Fprintf(k.h, "%s %d\n", label, len(data))
+	_, _ = k.h.Write(data)
+}
+
+// AddString hashes a labeled string input
+func (k *Key) AddString(label, value string) {
+	k.Add(label, []byte(value))
+}
+
+// AddJSON hashes the JSON encoding of v
+func (k *Key) AddJSON(label string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("cannot hash %s: %w", label, err)
+	}
+	k.Add(label, data)
+	return nil
+}
+
+// AddFile hashes the contents of the file at path under name, which keeps
+// the key independent of where the file lives
+func (k *Key) AddFile(name, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	sum := sha256.New()
+	if _, err := io.Copy(sum, f); err != nil {
+		return err
+	}
+	k.Add("file "+name, sum.Sum(nil))
+	return nil
+}
+
+// Sum returns the key as a hex string
+func (k *Key) Sum() string {
+	return hex.EncodeToString(k.h.Sum(nil))
+}
+
+// listedPackage is the part of go list -json output that identifies the
+// sources of a package
+type listedPackage struct {
+	ImportPath string
+	Dir        string
+	Standard   bool
+	Module     *struct {
+		Path    string
+		Version string
+		GoMod   string
+		Replace *struct{ Path string }
+	}
+	GoFiles, CgoFiles, CFiles, CXXFiles, HFiles, SFiles, SysoFiles, EmbedFiles []string
+}
+
+// AddPackageSources hashes the sources of the package in dir and of its
+// non-standard dependencies, as selected by go list with the build tags.
+// Dependencies from the module cache are hashed by module version, since
+// their sources cannot change; all others by the contents of their files.
+func (k *Key) AddPackageSources(dir string, tags []string) error {
+	args := []string{"list", "-deps", "-json"}
+	if len(tags) > 0 {
+		args = append(args, "-tags", strings.Join(tags, ","))
+	}
+	cmd := exec.Command("go", append(args, ".")...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("go list failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	modDirs := make(map[string]string) // module path to module directory
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	for decoder.More() {
+		var pkg listedPackage
+		if err := decoder.Decode(&pkg); err != nil {
+			return fmt.Errorf("cannot read go list output: %w", err)
+		}
+		if pkg.Standard {
+			continue
+		}
+
+		if mod := pkg.Module; mod != nil {
+			if mod.Version != "" && mod.Replace == nil {
+				k.AddString("module "+pkg.ImportPath, mod.Path+"@"+mod.Version)
+				continue
+			}
+			if mod.GoMod != "" {
+				modDirs[mod.Path] = filepath.Dir(mod.GoMod)
+			}
+		}
+
+		var files []string
+		for _, group := range [][]string{pkg.GoFiles, pkg.CgoFiles, pkg.CFiles, pkg.CXXFiles, pkg.HFiles, pkg.SFiles, pkg.SysoFiles, pkg.EmbedFiles} {
+			files = append(files, group...)
+		}
+		sort.Strings(files)
+		k.AddString("package", pkg.ImportPath)
+		for _, file := range files {
+			if err := k.AddFile(pkg.ImportPath+"/"+file, filepath.Join(pkg.Dir, file)); err != nil {
+				return err
+			}
+		}
+	}
+
+	// go.mod and go.sum select the versions of all dependencies
+	var mods []string
+	for mod := range modDirs {
+		mods = append(mods, mod)
+	}
+	sort.Strings(mods)
+	for _, mod := range mods {
+		for _, name := range []string{"go.mod", "go.sum"} {
+			if err := k.AddFile(mod+"/"+name, filepath.Join(modDirs[mod], name)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// AddVCSState hashes the git commit and nearest tag of dir, which builds
+// stamp into the library and derive package versions from. Directories
+// outside a git repository add nothing.
+func (k *Key) AddVCSState(dir string) {
+	cmd := exec.Command("git", "describe", "--tags", "--long", "--always", "--dirty")
+	cmd.Dir = dir
+	if out, err := cmd.Output(); err == nil {
+		k.Add("vcs", bytes.TrimSpace(out))
+	}
+}
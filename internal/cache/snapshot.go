@@ -0,0 +1,107 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// FileStamp identifies a version of a file by its size and modification time
+type FileStamp struct {
+	ModTime time.Time
+	Size    int64
+}
+
+// Stamp returns the stamp of the file described by info
+func Stamp(info fs.FileInfo) FileStamp {
+	return FileStamp{ModTime: info.ModTime(), Size: info.Size()}
+}
+
+// Equal reports whether both stamps identify the same version of a file
+func (s FileStamp) Equal(other FileStamp) bool {
+	return s.Size == other.Size && s.ModTime.Equal(other.ModTime)
+}
+
+// Snapshot records the stamps of a set of files by name, such as the files of
+// a directory tree, to find the files a build writes
+type Snapshot map[string]FileStamp
+
+// TakeSnapshot records the regular files under dir; a missing dir is empty
+func TakeSnapshot(dir string) (Snapshot, error) {
+	snapshot := make(Snapshot)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir && errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		snapshot[rel] = Stamp(info)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// Written returns the sorted files under dir that were created or modified
+// since the snapshot was taken
+func (s Snapshot) Written(dir string) ([]string, error) {
+	current, err := TakeSnapshot(dir)
+	if err != nil {
+		return nil, err
+	}
+	var written []string
+	for _, file := range s.Changed(current) {
+		if _, ok := current[file]; ok {
+			written = append(written, file)
+		}
+	}
+	return written, nil
+}
+
+// Changed returns the sorted files added, modified or removed between the
+// snapshot and current
+func (s Snapshot) Changed(current Snapshot) []string {
+	var changed []string
+	for file, stamp := range current {
+		if prev, ok := s[file]; !ok || !prev.Equal(stamp) {
+			changed = append(changed, file)
+		}
+	}
+	for file := range s {
+		if _, ok := current[file]; !ok {
+			changed = append(changed, file)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
@@ -0,0 +1,264 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/riceriley59/goanywhere/internal/abi"
+	"github.com/riceriley59/goanywhere/internal/core/factory"
+)
+
+type abiDiffOptions struct {
+	Package    string
+	ImportPath string
+	Prefix     string
+	Plugin     string
+	OutputFile string
+	Verbose    bool
+}
+
+// NewABIDiffCmd creates the abi-diff subcommand
+func NewABIDiffCmd() *cobra.Command {
+	opts := &abiDiffOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "abi-diff <old> [new]",
+		Short: "Report ABI changes between two versions of a package",
+		Long: `Compare the symbols exported for two versions of a Go package and report
+removed exports, signature changes and field changes, marked breaking or
+compatible. The exit status is non-zero when a breaking change is found.
+
+Each version is one of:
+  - an ABI snapshot (JSON) written with --output
+  - a package directory
+  - a git ref (commit, tag or branch); the --package directory is read at that ref
+
+With a single version and --output, its ABI snapshot is written without
+comparing, e.g. to record a baseline for the next release.
+
+Examples:
+  goanywhere abi-diff ./mypackage -o abi.json
+  goanywhere abi-diff abi.json ./mypackage
+  goanywhere abi-diff v1.2.0 HEAD --package ./mypackage
+  goanywhere abi-diff v1.2.0 ./mypackage -o abi.json`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				if opts.OutputFile == "" {
+					return fmt.Errorf("a single version needs --output to write its ABI snapshot")
+				}
+				return runABISnapshot(args[0], opts)
+			}
+			return runABIDiff(args[0], args[1], opts, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Package, "package", ".",
+		"Package directory read at git refs")
+	cmd.Flags().StringVarP(&opts.ImportPath, "import-path", "i", "",
+		"Import path for the package (default: from go.mod)")
+	cmd.Flags().StringVar(&opts.Prefix, "prefix", "",
+		"Export prefix the library is built with")
+	cmd.Flags().StringVarP(&opts.Plugin, "plugin", "p", "cgo",
+		"Plugin whose lowering is compared")
+	cmd.Flags().StringVarP(&opts.OutputFile, "output", "o", "",
+		"Write the ABI snapshot of the new version to this file")
+	cmd.Flags().BoolVarP(&opts.Verbose, "verbose", "v", false,
+		"Verbose output")
+
+	return cmd
+}
+
+// runABISnapshot writes the ABI snapshot of one version
+func runABISnapshot(version string, opts *abiDiffOptions) error {
+	snapshot, err := loadSnapshot(version, opts)
+	if err != nil {
+		return err
+	}
+	if err := snapshot.Write(opts.OutputFile); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote ABI snapshot: %s\n", opts.OutputFile)
+	return nil
+}
+
+// runABIDiff reports the ABI changes from oldVersion to newVersion and fails
+// on breaking changes
+func runABIDiff(oldVersion, newVersion string, opts *abiDiffOptions, out io.Writer) error {
+	oldSnapshot, err := loadSnapshot(oldVersion, opts)
+	if err != nil {
+		return fmt.Errorf("%s: %w", oldVersion, err)
+	}
+	newSnapshot, err := loadSnapshot(newVersion, opts)
+	if err != nil {
+		return fmt.Errorf("%s: %w", newVersion, err)
+	}
+
+	changes, err := abi.Diff(oldSnapshot, newSnapshot)
+	if err != nil {
+		return err
+	}
+	abi.WriteReport(out, changes)
+
+	if opts.OutputFile != "" {
+		if err := newSnapshot.Write(opts.OutputFile); err != nil {
+			return err
+		}
+	}
+
+	if breaking := abi.CountBreaking(changes); breaking > 0 {
+		return fmt.Errorf("%d breaking ABI changes", breaking)
+	}
+	return nil
+}
+
+// loadSnapshot reads a snapshot file, or lowers a package directory or the
+// --package directory at a git ref
+func loadSnapshot(version string, opts *abiDiffOptions) (*abi.Snapshot, error) {
+	info, err := os.Stat(version)
+	switch {
+	case err == nil && !info.IsDir():
+		snapshot, err := abi.Load(version)
+		if err != nil {
+			return nil, err
+		}
+		if snapshot.Plugin != opts.Plugin {
+			return nil, fmt.Errorf("snapshot was lowered by plugin %s, not %s", snapshot.Plugin, opts.Plugin)
+		}
+		return snapshot, nil
+	case err == nil:
+		inputPath, err := resolveInputDir(version)
+		if err != nil {
+			return nil, err
+		}
+		return packageSnapshot(inputPath, opts.ImportPath, opts)
+	default:
+		return refSnapshot(version, opts)
+	}
+}
+
+// packageSnapshot lowers the package in inputPath
+func packageSnapshot(inputPath, importPath string, opts *abiDiffOptions) (*abi.Snapshot, error) {
+	settings := packageSettings{ImportPath: importPath, ExportPrefix: opts.Prefix}
+	pkg, err := loadPackage(inputPath, settings, opts.Verbose)
+	if err != nil {
+		return nil, err
+	}
+
+	plugin, err := factory.Get(opts.Plugin, opts.Verbose)
+	if err != nil {
+		return nil, err
+	}
+	return abi.NewSnapshot(plugin, pkg)
+}
+
+// refSnapshot lowers the --package directory as of a git ref
+func refSnapshot(ref string, opts *abiDiffOptions) (*abi.Snapshot, error) {
+	pkgDir, err := resolveInputDir(opts.Package)
+	if err != nil {
+		return nil, fmt.Errorf("not a file, directory or git ref of --package: %w", err)
+	}
+
+	// The checkout has no go.mod, so resolve the import path in the work tree
+	importPath := opts.ImportPath
+	if importPath == "" {
+		if importPath, err = inferImportPath(pkgDir); err != nil {
+			return nil, fmt.Errorf("could not determine import path: use --import-path flag")
+		}
+	}
+
+	toplevel, err := git(pkgDir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	prefix, err := git(pkgDir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, err
+	}
+	treeish := ref + ":" + strings.TrimSuffix(strings.TrimSpace(string(prefix)), "/")
+	archive, err := git(strings.TrimSpace(string(toplevel)), "archive", "--format=tar", treeish)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "goanywhere-abi-")
+	if err != nil {
+		return nil, fmt.Errorf("cannot create temporary directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	if err := extractFiles(bytes.NewReader(archive), tmpDir); err != nil {
+		return nil, fmt.Errorf("%s: %w", treeish, err)
+	}
+	if opts.Verbose {
+		fmt.Printf("Checked out %s to %s\n", treeish, tmpDir)
+	}
+
+	return packageSnapshot(tmpDir, importPath, opts)
+}
+
+// git runs a git command in dir and returns its output
+func git(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
+
+// extractFiles writes the top-level regular files of a tar archive to dir;
+// a package is made of the files of a single directory
+func extractFiles(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid archive: %w", err)
+		}
+
+		name := strings.TrimPrefix(header.Name, "./")
+		if header.Typeflag != tar.TypeReg || strings.Contains(name, "/") {
+			continue
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return fmt.Errorf("invalid archive: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			return fmt.Errorf("write error: %w", err)
+		}
+	}
+}
@@ -21,17 +21,61 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/riceriley59/goanywhere/internal/config"
 	"github.com/riceriley59/goanywhere/internal/core"
-	"github.com/riceriley59/goanywhere/internal/core/factory"
 )
 
 type buildOptions struct {
-	OutputDir   string
-	ImportPath  string
-	Plugin      string
-	BuildSystem string
-	LibraryName string
-	Verbose     bool
+	OutputDir     string
+	ImportPath    string
+	Plugins       []string
+	PluginOptions []string
+	Include       []string
+	Exclude       []string
+	ConfigFile    string
+	BuildSystem   string
+	LibraryName   string
+	Verbose       bool
+	Link          string
+	Install       bool
+	Strict        bool
+	NoCache       bool
+
+	// go build settings
+	Tags         []string
+	LDFlags      string
+	TrimPath     bool
+	Race         bool
+	BuildVCS     string
+	CC           string
+	CGOCFlags    string
+	CGOLDFlags   string
+	Reproducible bool
+}
+
+// filter returns the symbol filter given by --include and --exclude
+func (o *buildOptions) filter() core.SymbolFilter {
+	return core.SymbolFilter{Include: o.Include, Exclude: o.Exclude}
+}
+
+// coreOptions returns the plugin build options for one output directory
+func (o *buildOptions) coreOptions(outputDir, libraryName string) *core.BuildOptions {
+	return &core.BuildOptions{
+		OutputDir:    outputDir,
+		LibraryName:  libraryName,
+		Verbose:      o.Verbose,
+		Link:         o.Link,
+		Install:      o.Install,
+		Tags:         o.Tags,
+		LDFlags:      o.LDFlags,
+		TrimPath:     o.TrimPath,
+		Race:         o.Race,
+		BuildVCS:     o.BuildVCS,
+		CC:           o.CC,
+		CGOCFlags:    o.CGOCFlags,
+		CGOLDFlags:   o.CGOLDFlags,
+		Reproducible: o.Reproducible,
+	}
 }
 
 // NewBuildCmd creates the build subcommand
@@ -39,7 +83,7 @@ func NewBuildCmd() *cobra.Command {
 	opts := &buildOptions{}
 
 	cmd := &cobra.Command{
-		Use:   "build <input-directory>",
+		Use:   "build [input-directory...]",
 		Short: "Generate and build plugin code for a Go package",
 		Long: `Generate plugin code and build it as a shared library or package.
 
@@ -50,12 +94,50 @@ For Python plugin:
   Generates CGO shared library, Python bindings, and creates a Python package
   with the specified build system configuration.
 
+Several input directories are bundled into one shared library with a single
+Go runtime, exporting each package's symbols with its name as prefix
+(e.g., geo_Point_New). Struct handles can be passed between bundled packages.
+
+Without an input directory, every target listed in goanywhere.yaml is built
+with the plugins, filters and overrides configured for it.
+
 Examples:
   goanywhere build ./mypackage --plugin cgo
   goanywhere build ./mypackage --plugin cgo -o ./dist
-  goanywhere build ./mypackage --plugin python --build-system setuptools`,
-		Args: cobra.ExactArgs(1),
+  goanywhere build ./mypackage --plugin python --build-system setuptools
+  goanywhere build ./mypackage --plugin python --opt build-system=hatch
+  goanywhere build ./geo ./shapes --plugin python --lib-name libshapes
+  goanywhere build --config goanywhere.yaml
+  goanywhere build ./mypackage --tags netgo --ldflags "-s -w"
+  goanywhere build ./mypackage --reproducible
+  goanywhere build ./mypackage --link static
+
+With --link static the CGO plugin builds a static archive (.a) with its header
+and a pkg-config file listing the system libraries the Go runtime needs.
+Every CGO build writes <lib>.pc and CMake package files (<name>Config.cmake,
+<name>Targets.cmake) next to the library; --install also lays them out as
+include/, lib/, lib/pkgconfig/ and lib/cmake/ under <output>/install.
+
+Each build writes a SHA256SUMS file with the checksums of the built library
+and header. With --reproducible, identical sources and toolchains produce
+byte-identical libraries.
+
+Plugin-specific options are listed by: goanywhere build --plugin <name> --help`,
+		Args: cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			// --build-system only overrides the config file when given explicitly
+			if !cmd.Flags().Changed("build-system") {
+				opts.BuildSystem = ""
+			}
+			if len(args) == 0 {
+				if err := rejectTargetFlags(cmd, "output", "import-path", "plugin", "lib-name", "include", "exclude"); err != nil {
+					return err
+				}
+				return runBuildTargets(opts)
+			}
+			if len(args) > 1 {
+				return runBuildBundle(args, opts)
+			}
 			return runBuild(args[0], opts)
 		},
 	}
@@ -64,92 +146,321 @@ Examples:
 		"Output directory (default: <input>/<plugin>_build)")
 	cmd.Flags().StringVarP(&opts.ImportPath, "import-path", "i", "",
 		"Import path for the target package (required for proper imports)")
-	cmd.Flags().StringVarP(&opts.Plugin, "plugin", "p", "cgo",
-		"Plugin type to build (cgo, python)")
+	cmd.Flags().StringSliceVarP(&opts.Plugins, "plugin", "p", []string{"cgo"},
+		"Plugins to build, comma-separated or repeatable (cgo, python)")
+	cmd.Flags().StringArrayVar(&opts.PluginOptions, "opt", nil,
+		"Plugin-specific option as key=value (repeatable)")
+	cmd.Flags().StringArrayVar(&opts.Include, "include", nil,
+		"Only bind symbols matching a glob or /regexp/ pattern (repeatable)")
+	cmd.Flags().StringArrayVar(&opts.Exclude, "exclude", nil,
+		"Skip symbols matching a glob or /regexp/ pattern (repeatable)")
+	cmd.Flags().StringVar(&opts.ConfigFile, "config", "",
+		"Config file path (default: ./"+config.DefaultFileName+" if present)")
 	cmd.Flags().StringVar(&opts.BuildSystem, "build-system", "setuptools",
-		"Python build system (setuptools, hatch, poetry, uv)")
+		"Python build system (setuptools, hatch, poetry, uv); shorthand for --opt build-system=<value>")
 	cmd.Flags().StringVar(&opts.LibraryName, "lib-name", "",
 		"Override the shared library name (default: lib<package>)")
 	cmd.Flags().BoolVarP(&opts.Verbose, "verbose", "v", false,
 		"Verbose output")
+	cmd.Flags().StringVar(&opts.Link, "link", core.LinkShared,
+		"Library kind: shared (c-shared) or static (c-archive, CGO plugin only)")
+	cmd.Flags().BoolVar(&opts.Strict, "strict", false,
+		"Fail before compiling when an exported symbol is skipped")
+	cmd.Flags().BoolVar(&opts.Install, "install", false,
+		"Also lay out include/, lib/, lib/pkgconfig/ and lib/cmake/ under <output>/install")
+	cmd.Flags().StringSliceVar(&opts.Tags, "tags", nil,
+		"Build tags passed to go build (comma-separated or repeatable)")
+	cmd.Flags().StringVar(&opts.LDFlags, "ldflags", "",
+		"Linker flags passed to go build")
+	cmd.Flags().BoolVar(&opts.TrimPath, "trimpath", false,
+		"Remove file system paths from the compiled library")
+	cmd.Flags().BoolVar(&opts.Race, "race", false,
+		"Enable the race detector")
+	cmd.Flags().StringVar(&opts.BuildVCS, "buildvcs", "",
+		"Stamp version control information (true, false, auto; default: go default)")
+	cmd.Flags().StringVar(&opts.CC, "cc", "",
+		"C compiler used by cgo (sets CC)")
+	cmd.Flags().StringVar(&opts.CGOCFlags, "cgo-cflags", "",
+		"C compiler flags for cgo (sets CGO_CFLAGS)")
+	cmd.Flags().StringVar(&opts.CGOLDFlags, "cgo-ldflags", "",
+		"C linker flags for cgo (sets CGO_LDFLAGS)")
+	cmd.Flags().BoolVar(&opts.Reproducible, "reproducible", false,
+		"Build byte-identical libraries (implies --trimpath, --buildvcs=false and an empty build ID)")
+	cmd.Flags().BoolVar(&opts.NoCache, "no-cache", false,
+		"Always build, without reading or writing the build cache")
+
+	addPluginOptionsHelp(cmd)
 
 	return cmd
 }
 
 func runBuild(inputDir string, opts *buildOptions) error {
-	// Resolve input path
-	inputPath, err := filepath.Abs(inputDir)
+	inputPath, err := resolveInputDir(inputDir)
 	if err != nil {
-		return fmt.Errorf("invalid input path: %w", err)
+		return err
 	}
 
-	// Check if input directory exists
-	info, err := os.Stat(inputPath)
+	settings := packageSettings{ImportPath: opts.ImportPath, Filter: opts.filter()}
+	pkg, err := loadPackage(inputPath, settings, opts.Verbose)
 	if err != nil {
-		return fmt.Errorf("cannot access input directory: %w", err)
-	}
-	if !info.IsDir() {
-		return fmt.Errorf("input path is not a directory: %s", inputPath)
+		return err
 	}
 
-	// Parse the Go package
-	parser := core.NewParser(opts.Verbose)
-	if opts.Verbose {
-		fmt.Printf("Parsing package at: %s\n", inputPath)
+	bc, err := newBuildCache(opts)
+	if err != nil {
+		return err
 	}
 
-	pkg, err := parser.ParsePackage(inputPath)
+	// Get the plugins
+	plugins, err := opts.plugins(bc)
 	if err != nil {
-		return fmt.Errorf("parse error: %w", err)
+		return err
+	}
+
+	diags := newDiagnosticPrinter(os.Stderr, opts.Strict)
+	options := func(plugin core.Plugin) *core.BuildOptions {
+		buildOpts := opts.coreOptions(opts.outputDir(inputPath, plugin, len(plugins)), opts.LibraryName)
+		buildOpts.Diagnostics = diags.handler(pkg)
+		return buildOpts
 	}
+	return buildWithPlugins(bc, plugins, []*core.ParsedPackage{pkg}, []string{inputPath}, options, func(plugin core.Plugin, buildOpts *core.BuildOptions) error {
+		return buildPackage(plugin, pkg, inputPath, buildOpts)
+	})
+}
 
-	// Set import path
+// runBuildBundle builds several packages into one shared library
+func runBuildBundle(inputDirs []string, opts *buildOptions) error {
 	if opts.ImportPath != "" {
-		pkg.ImportPath = opts.ImportPath
-	} else {
-		importPath, err := inferImportPath(inputPath)
-		if err == nil {
-			pkg.ImportPath = importPath
-		} else {
-			return fmt.Errorf("could not determine import path: use --import-path flag")
+		return fmt.Errorf("--import-path cannot be used when bundling several packages")
+	}
+
+	var pkgs []*core.ParsedPackage
+	var inputPaths []string
+	for _, inputDir := range inputDirs {
+		inputPath, err := resolveInputDir(inputDir)
+		if err != nil {
+			return err
+		}
+		pkg, err := loadPackage(inputPath, packageSettings{Filter: opts.filter()}, opts.Verbose)
+		if err != nil {
+			return fmt.Errorf("%s: %w", inputDir, err)
 		}
+		pkgs = append(pkgs, pkg)
+		inputPaths = append(inputPaths, inputPath)
+	}
+	if err := core.PrepareBundle(pkgs); err != nil {
+		return err
 	}
 
-	if opts.Verbose {
-		fmt.Printf("Package: %s\n", pkg.Name)
-		fmt.Printf("Import path: %s\n", pkg.ImportPath)
-		fmt.Printf("Functions: %d\n", len(pkg.Functions))
-		fmt.Printf("Structs: %d\n", len(pkg.Structs))
+	bc, err := newBuildCache(opts)
+	if err != nil {
+		return err
+	}
+	plugins, err := opts.plugins(bc)
+	if err != nil {
+		return err
+	}
+	for _, plugin := range plugins {
+		if _, ok := plugin.(core.Bundler); !ok {
+			return fmt.Errorf("plugin %s cannot bundle several packages", plugin.Name())
+		}
 	}
 
-	// Determine output directory
-	outputDir := opts.OutputDir
-	if outputDir == "" {
-		outputDir = filepath.Join(inputPath, opts.Plugin+"_build")
+	diags := newDiagnosticPrinter(os.Stderr, opts.Strict)
+	options := func(plugin core.Plugin) *core.BuildOptions {
+		buildOpts := opts.coreOptions(opts.outputDir("", plugin, len(plugins)), opts.LibraryName)
+		buildOpts.Diagnostics = diags.handler(pkgs...)
+		return buildOpts
 	}
-	outputDir, err = filepath.Abs(outputDir)
+	return buildWithPlugins(bc, plugins, pkgs, inputPaths, options, func(plugin core.Plugin, buildOpts *core.BuildOptions) error {
+		outputDir, err := createOutputDir(buildOpts.OutputDir)
+		if err != nil {
+			return err
+		}
+		buildOpts.OutputDir = outputDir
+		return plugin.(core.Bundler).BuildBundle(pkgs, inputPaths, buildOpts)
+	})
+}
+
+// runBuildTargets builds every target in the config file
+func runBuildTargets(opts *buildOptions) error {
+	cfg, err := loadTargets(opts.ConfigFile)
 	if err != nil {
-		return fmt.Errorf("invalid output path: %w", err)
+		return err
 	}
 
-	// Create output directory
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("cannot create output directory: %w", err)
+	bc, err := newBuildCache(opts)
+	if err != nil {
+		return err
 	}
 
-	// Get the plugin
-	plugin, err := factory.Get(opts.Plugin, opts.Verbose)
+	diags := newDiagnosticPrinter(os.Stderr, opts.Strict)
+	for i := range cfg.Targets {
+		target := &cfg.Targets[i]
+
+		inputPath, err := resolveInputDir(target.Package)
+		if err != nil {
+			return fmt.Errorf("target %s: %w", target.Package, err)
+		}
+
+		pkg, err := loadPackage(inputPath, targetSettings(target), opts.Verbose)
+		if err != nil {
+			return fmt.Errorf("target %s: %w", target.Package, err)
+		}
+
+		outputRoot := target.Output
+		if outputRoot == "" {
+			outputRoot = inputPath
+		}
+
+		plugins, err := newPlugins(target.Plugins, opts.Verbose)
+		if err != nil {
+			return fmt.Errorf("target %s: unsupported plugin for build: %w", target.Package, err)
+		}
+		if err := rejectUnclaimedOptions(plugins, opts.PluginOptions); err != nil {
+			return fmt.Errorf("target %s: %w", target.Package, err)
+		}
+		for _, plugin := range plugins {
+			options := cfg.TargetPluginOptions(target, plugin.Name())
+			if err := bc.configure(plugin, options, forwardOptions(plugin, buildAssignments(plugin, opts))); err != nil {
+				return fmt.Errorf("target %s: %w", target.Package, err)
+			}
+		}
+
+		options := func(plugin core.Plugin) *core.BuildOptions {
+			buildOpts := opts.coreOptions(filepath.Join(outputRoot, plugin.Name()+"_build"), target.LibName)
+			buildOpts.Diagnostics = diags.handler(pkg)
+			return buildOpts
+		}
+		err = buildWithPlugins(bc, plugins, []*core.ParsedPackage{pkg}, []string{inputPath}, options, func(plugin core.Plugin, buildOpts *core.BuildOptions) error {
+			return buildPackage(plugin, pkg, inputPath, buildOpts)
+		})
+		if err != nil {
+			return fmt.Errorf("target %s: %w", target.Package, err)
+		}
+	}
+
+	return nil
+}
+
+// plugins returns the plugins selected by --plugin, configured from the
+// config file and --opt flags
+func (o *buildOptions) plugins(bc *buildCache) ([]core.Plugin, error) {
+	plugins, err := newPlugins(o.Plugins, o.Verbose)
 	if err != nil {
-		return fmt.Errorf("unsupported plugin for build: %s (supported: cgo, python)", opts.Plugin)
+		return nil, fmt.Errorf("unsupported plugin for build: %w", err)
 	}
 
-	// Build using the plugin
-	buildOpts := &core.BuildOptions{
-		OutputDir:   outputDir,
-		LibraryName: opts.LibraryName,
-		BuildSystem: opts.BuildSystem,
-		Verbose:     opts.Verbose,
+	// Apply plugin-specific options from the config file and --opt flags
+	cfg, err := loadConfig(o.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	if len(plugins) > 1 {
+		if err := rejectUnclaimedOptions(plugins, o.PluginOptions); err != nil {
+			return nil, err
+		}
 	}
+	for _, plugin := range plugins {
+		assignments := buildAssignments(plugin, o)
+		if len(plugins) > 1 {
+			assignments = forwardOptions(plugin, assignments)
+		}
+		if err := bc.configure(plugin, cfg.PluginOptions(plugin.Name()), assignments); err != nil {
+			return nil, err
+		}
+	}
+	return plugins, nil
+}
+
+// outputDir returns the output directory of a plugin: --output, or
+// <outputRoot>/<plugin>_build by default and when building several plugins
+func (o *buildOptions) outputDir(outputRoot string, plugin core.Plugin, numPlugins int) string {
+	if o.OutputDir != "" && numPlugins == 1 {
+		return o.OutputDir
+	}
+	if o.OutputDir != "" {
+		outputRoot = o.OutputDir
+	}
+	return filepath.Join(outputRoot, plugin.Name()+"_build")
+}
 
+// buildWithPlugins builds pkgs, loaded from inputPaths, with every plugin
+// through the build cache. A library built by one plugin is built first and
+// reused by the others, which then build concurrently.
+func buildWithPlugins(bc *buildCache, plugins []core.Plugin, pkgs []*core.ParsedPackage, inputPaths []string, options func(core.Plugin) *core.BuildOptions, build func(core.Plugin, *core.BuildOptions) error) error {
+	cachedBuild := func(plugin core.Plugin, buildOpts *core.BuildOptions) error {
+		return bc.build(plugin, pkgs, inputPaths, buildOpts, func() error { return build(plugin, buildOpts) })
+	}
+
+	var libraryFile string
+	rest := plugins
+	if len(plugins) > 1 {
+		rest = nil
+		for _, plugin := range plugins {
+			builder, ok := plugin.(core.LibraryBuilder)
+			if !ok || libraryFile != "" {
+				rest = append(rest, plugin)
+				continue
+			}
+
+			buildOpts := options(plugin)
+			if err := cachedBuild(plugin, buildOpts); err != nil {
+				return pluginError(plugin, len(plugins), err)
+			}
+			if buildOpts.Link == "" || buildOpts.Link == core.LinkShared {
+				file, err := builder.LibraryFile(pkgs, buildOpts)
+				if err != nil {
+					return err
+				}
+				libraryFile = file
+			}
+		}
+	}
+
+	return forEachPlugin(rest, func(_ int, plugin core.Plugin) error {
+		buildOpts := options(plugin)
+		buildOpts.SharedLibrary = libraryFile
+		return pluginError(plugin, len(plugins), cachedBuild(plugin, buildOpts))
+	})
+}
+
+// buildAssignments returns the --opt assignments for a plugin, with
+// --build-system forwarded to plugins declaring it
+func buildAssignments(plugin core.Plugin, opts *buildOptions) []string {
+	if opts.BuildSystem != "" && declaresOption(plugin, "build-system") {
+		return append([]string{"build-system=" + opts.BuildSystem}, opts.PluginOptions...)
+	}
+	return opts.PluginOptions
+}
+
+// buildPackage builds pkg with the plugin into buildOpts.OutputDir
+func buildPackage(plugin core.Plugin, pkg *core.ParsedPackage, inputPath string, buildOpts *core.BuildOptions) error {
+	if buildOpts.Verbose {
+		fmt.Printf("Package: %s\n", pkg.Name)
+		fmt.Printf("Import path: %s\n", pkg.ImportPath)
+		fmt.Printf("Functions: %d\n", len(pkg.Functions))
+		fmt.Printf("Structs: %d\n", len(pkg.Structs))
+	}
+
+	outputDir, err := createOutputDir(buildOpts.OutputDir)
+	if err != nil {
+		return err
+	}
+	buildOpts.OutputDir = outputDir
+
+	// Build using the plugin
 	return plugin.Build(pkg, inputPath, buildOpts)
 }
+
+// createOutputDir creates the output directory and returns its absolute path
+func createOutputDir(outputDir string) (string, error) {
+	outputDir, err := filepath.Abs(outputDir)
+	if err != nil {
+		return "", fmt.Errorf("invalid output path: %w", err)
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", fmt.Errorf("cannot create output directory: %w", err)
+	}
+	return outputDir, nil
+}
@@ -0,0 +1,294 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/riceriley59/goanywhere/internal/cache"
+	"github.com/riceriley59/goanywhere/internal/core"
+	"github.com/riceriley59/goanywhere/internal/version"
+)
+
+// NewCacheCmd creates the cache subcommand
+func NewCacheCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the build cache",
+		Long: `goanywhere build caches its outputs under $XDG_CACHE_HOME/goanywhere (or the
+user cache directory), keyed on the parsed package, the sources of the package
+and its dependencies, the plugin and its options, the build options and the Go
+toolchain. A build whose inputs are all unchanged restores the cached outputs
+instead of compiling.`,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "clean",
+		Short: "Remove all cached builds",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCacheClean(cmd.OutOrStdout())
+		},
+	})
+
+	return cmd
+}
+
+func runCacheClean(out io.Writer) error {
+	c, err := cache.Open()
+	if err != nil {
+		return err
+	}
+	if err := c.Clean(); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(out, "Removed build cache: %s\n", c.Root)
+	return nil
+}
+
+// buildCache skips plugin builds whose inputs match a cached build and
+// restores the cached outputs instead
+type buildCache struct {
+	cache   *cache.Cache // nil builds without caching
+	verbose bool
+
+	mu      sync.Mutex
+	options map[core.Plugin]map[string]string // Plugin options, part of the key
+
+	envOnce sync.Once
+	env     *cache.Key // Inputs shared by every build of this process
+	envErr  error
+}
+
+// newBuildCache returns a cache for the builds of opts
+func newBuildCache(opts *buildOptions) (*buildCache, error) {
+	bc := &buildCache{verbose: opts.Verbose, options: make(map[core.Plugin]map[string]string)}
+	if opts.NoCache {
+		return bc, nil
+	}
+	c, err := cache.Open()
+	if err != nil {
+		return nil, err
+	}
+	bc.cache = c
+	return bc, nil
+}
+
+// configure applies options to the plugin like configurePlugin and
+// remembers them for the cache key
+func (c *buildCache) configure(plugin core.Plugin, raw map[string]string, assignments []string) error {
+	settings, err := pluginSettings(plugin, raw, assignments)
+	if err != nil {
+		return err
+	}
+	if err := core.ApplyOptions(plugin, settings); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.options[plugin] = settings
+	return nil
+}
+
+// cacheMeta is stored with a cached build
+type cacheMeta struct {
+	Plugin      string            `json:"plugin"`
+	Diagnostics []core.Diagnostic `json:"diagnostics"`
+}
+
+// build runs build for plugin into buildOpts.OutputDir, or restores the
+// outputs of a cached build of the same inputs. The diagnostics of a cached
+// build are reported again, so --strict behaves the same on a cache hit.
+func (c *buildCache) build(plugin core.Plugin, pkgs []*core.ParsedPackage, inputPaths []string, buildOpts *core.BuildOptions, build func() error) error {
+	if c.cache == nil {
+		return build()
+	}
+
+	outputDir, err := createOutputDir(buildOpts.OutputDir)
+	if err != nil {
+		return err
+	}
+	buildOpts.OutputDir = outputDir
+
+	key, err := c.key(plugin, pkgs, inputPaths, buildOpts)
+	if err != nil {
+		if c.verbose {
+			fmt.Printf("Build cache disabled for %s: %v\n", plugin.Name(), err)
+		}
+		return build()
+	}
+	if c.verbose {
+		fmt.Printf("Build cache key for %s: %s\n", plugin.Name(), key)
+	}
+
+	if entry, ok := c.cache.Get(key); ok {
+		return c.restore(plugin, entry, buildOpts)
+	}
+
+	snapshot, err := cache.TakeSnapshot(outputDir)
+	if err != nil {
+		return build()
+	}
+	var diags []core.Diagnostic
+	handler := buildOpts.Diagnostics
+	buildOpts.Diagnostics = func(reported []core.Diagnostic) error {
+		diags = append(diags, reported...)
+		if handler == nil {
+			return nil
+		}
+		return handler(reported)
+	}
+	if err := build(); err != nil {
+		return err
+	}
+
+	// A build that cannot be cached still succeeded
+	if err := c.store(key, plugin, outputDir, snapshot, diags); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: cannot cache %s build: %v\n", plugin.Name(), err)
+	}
+	return nil
+}
+
+// restore reports the diagnostics of a cached build and copies its outputs
+func (c *buildCache) restore(plugin core.Plugin, entry *cache.CachedBuild, buildOpts *core.BuildOptions) error {
+	data, err := entry.Meta()
+	if err != nil {
+		return fmt.Errorf("cannot read cached build: %w", err)
+	}
+	var meta cacheMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return fmt.Errorf("cannot read cached build: %w", err)
+	}
+
+	if err := buildOpts.HandleDiagnostics(meta.Diagnostics); err != nil {
+		return err
+	}
+
+	files, err := entry.Restore(buildOpts.OutputDir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Restored cached %s build: %s (%d files)\n", plugin.Name(), buildOpts.OutputDir, len(files))
+	return nil
+}
+
+// store caches the files written to outputDir since snapshot
+func (c *buildCache) store(key string, plugin core.Plugin, outputDir string, snapshot cache.Snapshot, diags []core.Diagnostic) error {
+	files, err := snapshot.Written(outputDir)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(cacheMeta{Plugin: plugin.Name(), Diagnostics: diags})
+	if err != nil {
+		return err
+	}
+	return c.cache.Put(key, outputDir, files, data)
+}
+
+// key hashes the inputs of a plugin build
+func (c *buildCache) key(plugin core.Plugin, pkgs []*core.ParsedPackage, inputPaths []string, buildOpts *core.BuildOptions) (string, error) {
+	c.envOnce.Do(func() { c.env, c.envErr = buildEnvironment() })
+	if c.envErr != nil {
+		return "", c.envErr
+	}
+
+	key := cache.NewKey()
+	key.AddString("environment", c.env.Sum())
+	key.AddString("plugin", plugin.Name())
+
+	c.mu.Lock()
+	options := c.options[plugin]
+	c.mu.Unlock()
+	if err := key.AddJSON("plugin options", options); err != nil {
+		return "", err
+	}
+
+	// The output directory and verbosity do not change the outputs
+	keyOpts := *buildOpts
+	keyOpts.OutputDir = ""
+	keyOpts.SharedLibrary = ""
+	keyOpts.Verbose = false
+	if err := key.AddJSON("build options", keyOpts); err != nil {
+		return "", err
+	}
+	if buildOpts.SharedLibrary != "" {
+		if err := key.AddFile("shared library", buildOpts.SharedLibrary); err != nil {
+			return "", err
+		}
+	}
+
+	if err := key.AddJSON("packages", keyPackages(pkgs)); err != nil {
+		return "", err
+	}
+	for _, inputPath := range inputPaths {
+		if err := key.AddPackageSources(inputPath, buildOpts.Tags); err != nil {
+			return "", err
+		}
+		key.AddVCSState(inputPath)
+	}
+	return key.Sum(), nil
+}
+
+// keyPackages returns copies of pkgs without their directories, and with
+// diagnostic positions relative to them, so that checkouts of the same tree
+// share cache entries
+func keyPackages(pkgs []*core.ParsedPackage) []core.ParsedPackage {
+	out := make([]core.ParsedPackage, len(pkgs))
+	for i, pkg := range pkgs {
+		out[i] = *pkg
+		out[i].Dir = ""
+		out[i].Diagnostics = make([]core.Diagnostic, len(pkg.Diagnostics))
+		for j, diag := range pkg.Diagnostics {
+			if rel, err := filepath.Rel(pkg.Dir, diag.Pos.Filename); err == nil {
+				diag.Pos.Filename = filepath.ToSlash(rel)
+			}
+			out[i].Diagnostics[j] = diag
+		}
+	}
+	return out
+}
+
+// buildEnvironment hashes the inputs shared by every build: the goanywhere
+// binary, which holds the plugins, and the Go toolchain and its settings
+func buildEnvironment() (*cache.Key, error) {
+	key := cache.NewKey()
+	key.AddString("version", version.GetVersion()+" "+version.GIT_SHA)
+
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("cannot locate goanywhere binary: %w", err)
+	}
+	if err := key.AddFile("goanywhere", exe); err != nil {
+		return nil, fmt.Errorf("cannot hash goanywhere binary: %w", err)
+	}
+
+	out, err := exec.Command("go", "env", "GOVERSION", "GOOS", "GOARCH", "GOFLAGS", "GOEXPERIMENT",
+		"CGO_ENABLED", "CC", "CXX", "CGO_CFLAGS", "CGO_CPPFLAGS", "CGO_CXXFLAGS", "CGO_LDFLAGS").Output()
+	if err != nil {
+		return nil, fmt.Errorf("go env failed: %w", err)
+	}
+	key.Add("go env", out)
+	return key, nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/riceriley59/goanywhere/internal/config"
+	"github.com/riceriley59/goanywhere/internal/core"
+	"github.com/riceriley59/goanywhere/internal/diff"
+)
+
+// NewCheckCmd creates the check subcommand
+func NewCheckCmd() *cobra.Command {
+	opts := &generateOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "check [input-directory]",
+		Short: "Verify that generated plugin code is up to date",
+		Long: `Regenerate plugin code in memory and compare it with the files on disk.
+
+Stale or missing files are printed as a unified diff and the command exits
+with a non-zero status, so it can run in pre-commit hooks and CI. Nothing is
+written; run goanywhere generate with the same arguments to update the files.
+
+Without an input directory, every target listed in goanywhere.yaml is checked.
+
+Examples:
+  goanywhere check ./mypackage
+  goanywhere check ./mypackage --plugin cgo,python
+  goanywhere check --config goanywhere.yaml`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			checker := &staleChecker{
+				out:     cmd.OutOrStdout(),
+				diags:   newDiagnosticPrinter(cmd.ErrOrStderr(), opts.Strict),
+				verbose: opts.Verbose,
+			}
+			if len(args) == 0 {
+				if err := rejectTargetFlags(cmd, "output", "import-path", "plugin", "include", "exclude"); err != nil {
+					return err
+				}
+				if err := visitTargets(opts, checker.check); err != nil {
+					return err
+				}
+			} else if err := visitPackage(args[0], opts, checker.check); err != nil {
+				return err
+			}
+			return checker.result()
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.OutputFile, "output", "o", "",
+		"Generated file to check (default: <input>/<plugin>_plugin/main.go)")
+	cmd.Flags().StringVarP(&opts.ImportPath, "import-path", "i", "",
+		"Import path for the target package (required for proper imports)")
+	cmd.Flags().StringSliceVarP(&opts.Plugins, "plugin", "p", []string{"cgo"},
+		"Plugin type to check (cgo, python)")
+	cmd.Flags().StringArrayVar(&opts.PluginOptions, "opt", nil,
+		"Plugin-specific option as key=value (repeatable)")
+	cmd.Flags().StringArrayVar(&opts.Include, "include", nil,
+		"Only bind symbols matching a glob or /regexp/ pattern (repeatable)")
+	cmd.Flags().StringArrayVar(&opts.Exclude, "exclude", nil,
+		"Skip symbols matching a glob or /regexp/ pattern (repeatable)")
+	cmd.Flags().StringVar(&opts.ConfigFile, "config", "",
+		"Config file path (default: ./"+config.DefaultFileName+" if present)")
+	cmd.Flags().BoolVarP(&opts.Verbose, "verbose", "v", false,
+		"Verbose output")
+	cmd.Flags().BoolVar(&opts.Strict, "strict", false,
+		"Fail when an exported symbol is skipped")
+
+	addPluginOptionsHelp(cmd)
+
+	return cmd
+}
+
+// staleChecker compares generated code with the files on disk
+type staleChecker struct {
+	out     io.Writer
+	diags   *diagnosticPrinter
+	verbose bool
+	checked int
+	stale   int
+}
+
+// check prints the diff of one generated file against the file on disk
+func (c *staleChecker) check(plugin core.Plugin, pkg *core.ParsedPackage, file generatedFile) error {
+	if err := c.diags.reportGenerated(plugin, pkg); err != nil {
+		return err
+	}
+	c.checked++
+
+	name := displayPath(file.Path)
+	current, err := os.ReadFile(file.Path)
+	oldName := "a/" + name
+	if os.IsNotExist(err) {
+		oldName = "/dev/null"
+	} else if err != nil {
+		return fmt.Errorf("cannot read generated file: %w", err)
+	}
+
+	unified := diff.Unified(oldName, "b/"+name, current, file.Code)
+	if unified == "" {
+		if c.verbose {
+			_, _ = fmt.Fprintf(c.out, "%s: up to date\n", name)
+		}
+		return nil
+	}
+
+	c.stale++
+	_, _ = fmt.Fprint(c.out, unified)
+	return nil
+}
+
+// result fails when a checked file is stale
+func (c *staleChecker) result() error {
+	if c.stale > 0 {
+		return fmt.Errorf("%d of %d generated files are out of date; run goanywhere generate to update them", c.stale, c.checked)
+	}
+	_, _ = fmt.Fprintf(c.out, "%d generated files are up to date\n", c.checked)
+	return nil
+}
+
+// displayPath returns path relative to the working directory when it is
+// below it, with forward slashes
+func displayPath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
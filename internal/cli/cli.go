@@ -47,6 +47,11 @@ func NewGoAnywhereCmd() *cobra.Command {
 	// Add subcommands
 	goAnywhereCmd.AddCommand(NewGenerateCmd())
 	goAnywhereCmd.AddCommand(NewBuildCmd())
+	goAnywhereCmd.AddCommand(NewCheckCmd())
+	goAnywhereCmd.AddCommand(NewInspectCmd())
+	goAnywhereCmd.AddCommand(NewWatchCmd())
+	goAnywhereCmd.AddCommand(NewABIDiffCmd())
+	goAnywhereCmd.AddCommand(NewCacheCmd())
 
 	return goAnywhereCmd
 }
@@ -15,12 +15,27 @@
 package cli
 
 import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/token"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+
+	"github.com/riceriley59/goanywhere/internal/config"
+	"github.com/riceriley59/goanywhere/internal/core"
+	"github.com/riceriley59/goanywhere/plugins/cgo"
+	"github.com/riceriley59/goanywhere/plugins/python"
 )
 
 func TestCli(t *testing.T) {
@@ -29,6 +44,11 @@ func TestCli(t *testing.T) {
 }
 
 var _ = Describe("CLI", func() {
+	BeforeEach(func() {
+		// Keep builds out of the user's cache
+		GinkgoT().Setenv("XDG_CACHE_HOME", GinkgoT().TempDir())
+	})
+
 	Describe("ExitCode", func() {
 		It("converts to int correctly", func() {
 			Expect(ExitCodeSuccess.ToInt()).To(Equal(0))
@@ -50,6 +70,27 @@ var _ = Describe("CLI", func() {
 			Expect(generateCmd.Use).To(ContainSubstring("generate"))
 		})
 
+		It("has check subcommand", func() {
+			cmd := NewGoAnywhereCmd()
+			checkCmd, _, err := cmd.Find([]string{"check"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(checkCmd.Use).To(ContainSubstring("check"))
+		})
+
+		It("has watch subcommand", func() {
+			cmd := NewGoAnywhereCmd()
+			watchCmd, _, err := cmd.Find([]string{"watch"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(watchCmd.Use).To(ContainSubstring("watch"))
+		})
+
+		It("has cache clean subcommand", func() {
+			cmd := NewGoAnywhereCmd()
+			cleanCmd, _, err := cmd.Find([]string{"cache", "clean"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cleanCmd.Use).To(Equal("clean"))
+		})
+
 		It("has build subcommand", func() {
 			cmd := NewGoAnywhereCmd()
 			buildCmd, _, err := cmd.Find([]string{"build"})
@@ -79,7 +120,33 @@ var _ = Describe("CLI", func() {
 		It("has correct default plugin value", func() {
 			cmd := NewGenerateCmd()
 			pluginFlag := cmd.Flags().Lookup("plugin")
-			Expect(pluginFlag.DefValue).To(Equal("cgo"))
+			Expect(pluginFlag.DefValue).To(Equal("[cgo]"))
+		})
+
+		It("has plugin option and config flags", func() {
+			cmd := NewGenerateCmd()
+			Expect(cmd.Flags().Lookup("opt")).NotTo(BeNil())
+			Expect(cmd.Flags().Lookup("config")).NotTo(BeNil())
+		})
+
+		It("lists plugin options in help", func() {
+			cmd := NewGoAnywhereCmd()
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+			cmd.SetArgs([]string{"generate", "--plugin", "python", "--help"})
+			Expect(cmd.Execute()).To(Succeed())
+			Expect(out.String()).To(ContainSubstring("Plugin options for python"))
+			Expect(out.String()).To(ContainSubstring("build-system"))
+		})
+
+		It("lists the options of the selected plugin in help", func() {
+			cmd := NewGoAnywhereCmd()
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+			cmd.SetArgs([]string{"generate", "--plugin", "cgo", "--help"})
+			Expect(cmd.Execute()).To(Succeed())
+			Expect(out.String()).To(ContainSubstring("Plugin options for cgo"))
+			Expect(out.String()).To(ContainSubstring("async bool"))
 		})
 	})
 
@@ -103,6 +170,113 @@ var _ = Describe("CLI", func() {
 			buildSystemFlag := cmd.Flags().Lookup("build-system")
 			Expect(buildSystemFlag.DefValue).To(Equal("setuptools"))
 		})
+
+		It("passes go build flags to the plugin", func() {
+			cmd := NewBuildCmd()
+			for _, name := range []string{"link", "install", "tags", "ldflags", "trimpath", "race", "buildvcs", "cc", "cgo-cflags", "cgo-ldflags", "reproducible"} {
+				Expect(cmd.Flags().Lookup(name)).NotTo(BeNil(), name)
+			}
+
+			opts := &buildOptions{
+				Verbose:      true,
+				Link:         core.LinkStatic,
+				Install:      true,
+				Tags:         []string{"netgo"},
+				LDFlags:      "-s -w",
+				CGOCFlags:    "-O2",
+				Reproducible: true,
+			}
+			buildOpts := opts.coreOptions("/out", "libfoo")
+			Expect(buildOpts).To(Equal(&core.BuildOptions{
+				OutputDir:    "/out",
+				LibraryName:  "libfoo",
+				Verbose:      true,
+				Link:         core.LinkStatic,
+				Install:      true,
+				Tags:         []string{"netgo"},
+				LDFlags:      "-s -w",
+				CGOCFlags:    "-O2",
+				Reproducible: true,
+			}))
+		})
+	})
+
+	Describe("configurePlugin", func() {
+		It("lets --opt override the config file", func() {
+			cfg, err := config.Parse([]byte("plugins:\n  python:\n    build-system: maven\n"))
+			Expect(err).NotTo(HaveOccurred())
+
+			// The invalid config value is rejected unless overridden on the command line
+			Expect(configurePlugin(python.NewPlugin(false), cfg.PluginOptions("python"), nil)).NotTo(Succeed())
+			Expect(configurePlugin(python.NewPlugin(false), cfg.PluginOptions("python"), []string{"build-system=uv"})).To(Succeed())
+		})
+
+		It("appends repeated --opt values of list options", func() {
+			settings, err := pluginSettings(python.NewPlugin(false), map[string]string{"authors": "C <c@x>"},
+				[]string{"authors=A <a@x>", "authors=B <b@x>", "build-system=poetry", "build-system=uv"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(settings).To(HaveKeyWithValue("authors", "A <a@x>\nB <b@x>"))
+			Expect(settings).To(HaveKeyWithValue("build-system", "uv"))
+		})
+
+		It("describes plugin options", func() {
+			var out bytes.Buffer
+			writePluginOptions(&out, python.NewPlugin(false))
+			Expect(out.String()).To(ContainSubstring("build-system string"))
+			Expect(out.String()).To(ContainSubstring("default: setuptools"))
+		})
+
+		It("rejects malformed assignments", func() {
+			err := configurePlugin(python.NewPlugin(false), nil, []string{"build-system"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("expected key=value"))
+		})
+
+		It("rejects options a plugin does not declare", func() {
+			err := configurePlugin(cgo.NewPlugin(false), nil, []string{"foo=bar"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`unknown option "foo" for plugin cgo`))
+		})
+	})
+
+	Describe("rejectUnclaimedOptions", func() {
+		It("fails on options no selected plugin declares", func() {
+			plugins := []core.Plugin{python.NewPlugin(false), cgo.NewPlugin(false)}
+			Expect(rejectUnclaimedOptions(plugins, []string{"build-system=uv", "async=true"})).To(Succeed())
+
+			err := rejectUnclaimedOptions(plugins, []string{"build-system=uv", "biuld-sytem=uv"})
+			Expect(err).To(MatchError(`unknown option "biuld-sytem" for plugins python, cgo`))
+			Expect(configurePlugins(plugins, &config.Config{}, []string{"typo=1"})).To(MatchError(`unknown option "typo" for plugins python, cgo`))
+		})
+	})
+
+	Describe("declaresOption", func() {
+		It("detects declared options", func() {
+			Expect(declaresOption(python.NewPlugin(false), "build-system")).To(BeTrue())
+			Expect(declaresOption(python.NewPlugin(false), "missing")).To(BeFalse())
+			Expect(declaresOption(cgo.NewPlugin(false), "build-system")).To(BeFalse())
+		})
+	})
+
+	Describe("loadConfig", func() {
+		It("loads an explicit config file", func() {
+			tmpDir, err := os.MkdirTemp("", "config")
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { _ = os.RemoveAll(tmpDir) }()
+
+			path := filepath.Join(tmpDir, "custom.yaml")
+			Expect(os.WriteFile(path, []byte("plugins:\n  python:\n    build-system: uv\n"), 0644)).To(Succeed())
+
+			cfg, err := loadConfig(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.PluginOptions("python")["build-system"]).To(Equal("uv"))
+		})
+
+		It("returns an empty config when no file is present", func() {
+			cfg, err := loadConfig("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.PluginOptions("python")).To(BeEmpty())
+		})
 	})
 
 	Describe("inferImportPath", func() {
@@ -183,7 +357,7 @@ var _ = Describe("CLI", func() {
 		})
 
 		It("returns error for non-existent directory", func() {
-			opts := &generateOptions{Plugin: "cgo"}
+			opts := &generateOptions{Plugins: []string{"cgo"}}
 			err := runGenerate("/nonexistent/path", opts)
 			Expect(err).To(HaveOccurred())
 		})
@@ -194,14 +368,14 @@ var _ = Describe("CLI", func() {
 			defer func() { _ = os.Remove(tmpFile.Name()) }()
 			_ = tmpFile.Close()
 
-			opts := &generateOptions{Plugin: "cgo"}
+			opts := &generateOptions{Plugins: []string{"cgo"}}
 			err = runGenerate(tmpFile.Name(), opts)
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("not a directory"))
 		})
 
 		It("returns error for invalid plugin", func() {
-			opts := &generateOptions{Plugin: "invalid"}
+			opts := &generateOptions{Plugins: []string{"invalid"}}
 			err := runGenerate(fixtureDir, opts)
 			Expect(err).To(HaveOccurred())
 		})
@@ -212,7 +386,7 @@ var _ = Describe("CLI", func() {
 			defer func() { _ = os.RemoveAll(tmpDir) }()
 
 			opts := &generateOptions{
-				Plugin:     "cgo",
+				Plugins:    []string{"cgo"},
 				OutputFile: filepath.Join(tmpDir, "main.go"),
 				ImportPath: "github.com/test/simple",
 			}
@@ -224,13 +398,86 @@ var _ = Describe("CLI", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 
+		It("applies --include and --exclude patterns", func() {
+			outputFile := filepath.Join(GinkgoT().TempDir(), "main.go")
+			opts := &generateOptions{
+				Plugins:    []string{"cgo"},
+				OutputFile: outputFile,
+				ImportPath: "github.com/test/simple",
+				Include:    []string{"/^(Add|Greet)/"},
+				Exclude:    []string{"Gr*"},
+			}
+			Expect(runGenerate(fixtureDir, opts)).To(Succeed())
+
+			content, err := os.ReadFile(outputFile)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("func simple_Add("))
+			Expect(string(content)).NotTo(ContainSubstring("simple_Greet"))
+			Expect(string(content)).NotTo(ContainSubstring("simple_Divide"))
+		})
+
+		It("fails when the filter matches no symbols", func() {
+			opts := &generateOptions{Plugins: []string{"cgo"}, ImportPath: "github.com/test/simple", Include: []string{"Nothing*"}}
+			Expect(runGenerate(fixtureDir, opts)).To(MatchError("filter matched no symbols in package simple"))
+		})
+
+		It("rejects malformed filter patterns", func() {
+			opts := &generateOptions{Plugins: []string{"cgo"}, ImportPath: "github.com/test/simple", Exclude: []string{"/(/"}}
+			Expect(runGenerate(fixtureDir, opts)).To(MatchError(ContainSubstring("invalid symbol pattern")))
+		})
+
+		It("generates several plugins from one parse", func() {
+			outputDir := GinkgoT().TempDir()
+			cfgFile := filepath.Join(outputDir, "goanywhere.yaml")
+			Expect(os.WriteFile(cfgFile, []byte("plugins:\n  python:\n    build-system: hatch\n"), 0644)).To(Succeed())
+
+			opts := &generateOptions{
+				Plugins:       []string{"cgo", "python"},
+				ImportPath:    "github.com/test/simple",
+				ConfigFile:    cfgFile,
+				PluginOptions: []string{"build-system=uv"},
+			}
+			var files []generatedFile
+			err := visitPackage(fixtureDir, opts, func(_ core.Plugin, _ *core.ParsedPackage, file generatedFile) error {
+				files = append(files, file)
+				return nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(HaveLen(2))
+			Expect(files[0].Path).To(Equal(filepath.Join(fixtureDir, "cgo_plugin", "main.go")))
+			Expect(string(files[0].Code)).To(ContainSubstring("func simple_Add("))
+			Expect(files[1].Path).To(Equal(filepath.Join(fixtureDir, "python_plugin", "simple.py")))
+			Expect(string(files[1].Code)).To(ContainSubstring("def add("))
+		})
+
+		It("rejects --output with several plugins", func() {
+			opts := &generateOptions{Plugins: []string{"cgo", "python"}, ImportPath: "github.com/test/simple", OutputFile: "out.go"}
+			Expect(runGenerate(fixtureDir, opts)).To(MatchError("--output cannot be used with several plugins"))
+		})
+
+		It("rejects a plugin given twice", func() {
+			opts := &generateOptions{Plugins: []string{"cgo", "cgo"}}
+			Expect(runGenerate(fixtureDir, opts)).To(MatchError("plugin cgo given more than once"))
+		})
+
+		It("returns error for invalid plugin options", func() {
+			opts := &generateOptions{
+				Plugins:       []string{"python"},
+				ImportPath:    "github.com/test/simple",
+				PluginOptions: []string{"build-system=maven"},
+			}
+			err := runGenerate(fixtureDir, opts)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("build-system"))
+		})
+
 		It("generates Python code successfully", func() {
 			tmpDir, err := os.MkdirTemp("", "output")
 			Expect(err).NotTo(HaveOccurred())
 			defer func() { _ = os.RemoveAll(tmpDir) }()
 
 			opts := &generateOptions{
-				Plugin:     "python",
+				Plugins:    []string{"python"},
 				OutputFile: filepath.Join(tmpDir, "simple.py"),
 				ImportPath: "github.com/test/simple",
 			}
@@ -243,6 +490,80 @@ var _ = Describe("CLI", func() {
 		})
 	})
 
+	Describe("runGenerateTargets", func() {
+		var fixtureDir, tmpDir string
+
+		BeforeEach(func() {
+			wd, _ := os.Getwd()
+			fixtureDir = filepath.Join(wd, "..", "..", "tests", "fixtures", "simple")
+
+			var err error
+			tmpDir, err = os.MkdirTemp("", "targets")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			_ = os.RemoveAll(tmpDir)
+		})
+
+		writeConfig := func(content string) string {
+			path := filepath.Join(tmpDir, config.DefaultFileName)
+			Expect(os.WriteFile(path, []byte(content), 0644)).To(Succeed())
+			return path
+		}
+
+		It("generates every plugin of every target", func() {
+			path := writeConfig(fmt.Sprintf(`targets:
+  - package: %s
+    import-path: github.com/test/simple
+    plugins: [cgo, python]
+    output: out
+    prefix: smp
+    exclude: [Greet, "Point.Scale"]
+`, fixtureDir))
+
+			Expect(runGenerateTargets(&generateOptions{ConfigFile: path})).To(Succeed())
+
+			code, err := os.ReadFile(filepath.Join(tmpDir, "out", "cgo_plugin", "main.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(code)).To(ContainSubstring("//export smp_Add"))
+			Expect(string(code)).To(ContainSubstring("//export smp_Point_Distance"))
+			Expect(string(code)).NotTo(ContainSubstring("Greet"))
+			Expect(string(code)).NotTo(ContainSubstring("Point_Scale"))
+
+			_, err = os.Stat(filepath.Join(tmpDir, "out", "python_plugin", "simple.py"))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns error when the config has no targets", func() {
+			path := writeConfig("plugins:\n  python:\n    build-system: uv\n")
+			err := runGenerateTargets(&generateOptions{ConfigFile: path})
+			Expect(err).To(MatchError(ContainSubstring("no targets defined")))
+		})
+
+		It("reports the failing target", func() {
+			path := writeConfig("targets:\n  - package: ./missing\n")
+			err := runGenerateTargets(&generateOptions{ConfigFile: path})
+			Expect(err).To(MatchError(ContainSubstring(filepath.Join(tmpDir, "missing"))))
+		})
+
+		It("rejects per-package flags without an input directory", func() {
+			cmd := NewGenerateCmd()
+			cmd.SetArgs([]string{"--output", "x.go"})
+			cmd.SilenceErrors = true
+			cmd.SilenceUsage = true
+			Expect(cmd.Execute()).To(MatchError(ContainSubstring("--output requires an input directory")))
+		})
+	})
+
+	Describe("forwardOptions", func() {
+		It("keeps only options the plugin declares", func() {
+			assignments := []string{"build-system=uv", "bogus"}
+			Expect(forwardOptions(python.NewPlugin(false), assignments)).To(Equal(assignments))
+			Expect(forwardOptions(cgo.NewPlugin(false), assignments)).To(Equal([]string{"bogus"}))
+		})
+	})
+
 	Describe("runBuild", func() {
 		var fixtureDir string
 
@@ -252,7 +573,7 @@ var _ = Describe("CLI", func() {
 		})
 
 		It("returns error for non-existent directory", func() {
-			opts := &buildOptions{Plugin: "cgo"}
+			opts := &buildOptions{Plugins: []string{"cgo"}}
 			err := runBuild("/nonexistent/path", opts)
 			Expect(err).To(HaveOccurred())
 		})
@@ -263,18 +584,456 @@ var _ = Describe("CLI", func() {
 			defer func() { _ = os.Remove(tmpFile.Name()) }()
 			_ = tmpFile.Close()
 
-			opts := &buildOptions{Plugin: "cgo"}
+			opts := &buildOptions{Plugins: []string{"cgo"}}
 			err = runBuild(tmpFile.Name(), opts)
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("not a directory"))
 		})
 
 		It("returns error for unsupported plugin", func() {
-			opts := &buildOptions{Plugin: "rust"}
+			opts := &buildOptions{Plugins: []string{"rust"}}
 			err := runBuild(fixtureDir, opts)
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("unsupported plugin"))
 		})
+
+		It("builds without the functions using an excluded struct", func() {
+			outputDir := GinkgoT().TempDir()
+			opts := &buildOptions{Plugins: []string{"cgo"}, OutputDir: outputDir, Link: core.LinkShared, Exclude: []string{"Point"}}
+			Expect(runBuild(fixtureDir, opts)).To(Succeed())
+
+			content, err := os.ReadFile(filepath.Join(outputDir, "cgo_plugin", "main.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("func simple_Add("))
+			Expect(string(content)).NotTo(ContainSubstring("NewPoint"))
+		})
+
+		It("builds the shared library once for several plugins", func() {
+			outputDir := GinkgoT().TempDir()
+			opts := &buildOptions{Plugins: []string{"python", "cgo"}, OutputDir: outputDir, Link: core.LinkShared}
+			Expect(runBuild(fixtureDir, opts)).To(Succeed())
+
+			libFile := "libsimple.so"
+			if runtime.GOOS == "darwin" {
+				libFile = "libsimple.dylib"
+			}
+			Expect(filepath.Join(outputDir, "cgo_build", libFile)).To(BeAnExistingFile())
+			Expect(filepath.Join(outputDir, "python_build", "simple", "lib", libFile)).To(BeAnExistingFile())
+			Expect(filepath.Join(outputDir, "python_build", libFile)).NotTo(BeAnExistingFile())
+
+			if runtime.GOOS == "linux" && runtime.GOARCH == "amd64" {
+				wheels, err := filepath.Glob(filepath.Join(outputDir, "python_build", "dist", "simple-0.1.0-py3-none-manylinux_2_*_x86_64.whl"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(wheels).To(HaveLen(1))
+			}
+		})
+
+		It("restores an unchanged build from the cache", func() {
+			opts := &buildOptions{Plugins: []string{"cgo"}, OutputDir: GinkgoT().TempDir(), Link: core.LinkShared}
+			Expect(runBuild(fixtureDir, opts)).To(Succeed())
+
+			// Mark the cached header to tell a restored build from a compiled one
+			headers, err := filepath.Glob(filepath.Join(os.Getenv("XDG_CACHE_HOME"), "goanywhere", "builds", "*", "*", "files", "libsimple.h"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(headers).To(HaveLen(1))
+			Expect(os.WriteFile(headers[0], []byte("cached"), 0644)).To(Succeed())
+
+			opts.OutputDir = GinkgoT().TempDir()
+			Expect(runBuild(fixtureDir, opts)).To(Succeed())
+			Expect(os.ReadFile(filepath.Join(opts.OutputDir, "libsimple.h"))).To(Equal([]byte("cached")))
+
+			opts.OutputDir = GinkgoT().TempDir()
+			opts.NoCache = true
+			Expect(runBuild(fixtureDir, opts)).To(Succeed())
+			Expect(os.ReadFile(filepath.Join(opts.OutputDir, "libsimple.h"))).NotTo(Equal([]byte("cached")))
+
+			opts.OutputDir = GinkgoT().TempDir()
+			opts.NoCache = false
+			opts.Tags = []string{"extra"}
+			Expect(runBuild(fixtureDir, opts)).To(Succeed())
+			Expect(os.ReadFile(filepath.Join(opts.OutputDir, "libsimple.h"))).NotTo(Equal([]byte("cached")))
+		})
+	})
+
+	Describe("buildCache.key", func() {
+		It("ignores verbosity and where the package is checked out", func() {
+			bc, err := newBuildCache(&buildOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			plugin := cgo.NewPlugin(false)
+
+			key := func(dir string, verbose bool) string {
+				pkg := &core.ParsedPackage{
+					Name:       "geo",
+					ImportPath: "example.com/geo",
+					Dir:        dir,
+					Diagnostics: []core.Diagnostic{{
+						Pos:  token.Position{Filename: filepath.Join(dir, "geo.go"), Line: 3, Column: 6},
+						Decl: core.Decl{Symbol: "Watch", Kind: core.DeclFunction},
+					}},
+				}
+				sum, err := bc.key(plugin, []*core.ParsedPackage{pkg}, nil, &core.BuildOptions{Verbose: verbose})
+				Expect(err).NotTo(HaveOccurred())
+				return sum
+			}
+			base := key("/a/geo", false)
+			Expect(key("/a/geo", true)).To(Equal(base))
+			Expect(key("/checkout/b/geo", false)).To(Equal(base))
+		})
+	})
+
+	Describe("runCacheClean", func() {
+		It("removes the cache directory", func() {
+			cacheDir := filepath.Join(os.Getenv("XDG_CACHE_HOME"), "goanywhere")
+			Expect(os.MkdirAll(filepath.Join(cacheDir, "builds"), 0755)).To(Succeed())
+
+			out := gbytes.NewBuffer()
+			Expect(runCacheClean(out)).To(Succeed())
+			Expect(out).To(gbytes.Say(regexp.QuoteMeta("Removed build cache: " + cacheDir)))
+			Expect(cacheDir).NotTo(BeADirectory())
+		})
+	})
+
+	Describe("runBuildBundle", func() {
+		var simpleDir, complexDir string
+
+		BeforeEach(func() {
+			wd, _ := os.Getwd()
+			simpleDir = filepath.Join(wd, "..", "..", "tests", "fixtures", "simple")
+			complexDir = filepath.Join(wd, "..", "..", "tests", "fixtures", "complex")
+		})
+
+		It("rejects --import-path", func() {
+			opts := &buildOptions{Plugins: []string{"cgo"}, ImportPath: "github.com/test/simple"}
+			err := runBuildBundle([]string{simpleDir, complexDir}, opts)
+			Expect(err).To(MatchError(ContainSubstring("--import-path")))
+		})
+
+		It("rejects the same package twice", func() {
+			opts := &buildOptions{Plugins: []string{"cgo"}}
+			err := runBuildBundle([]string{simpleDir, simpleDir}, opts)
+			Expect(err).To(MatchError(ContainSubstring("bundled more than once")))
+		})
+
+		It("returns error for non-existent directory", func() {
+			opts := &buildOptions{Plugins: []string{"cgo"}}
+			err := runBuildBundle([]string{simpleDir, "/nonexistent/path"}, opts)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("runABIDiff", func() {
+		var oldDir, newDir string
+
+		writePackage := func(dir, source string) {
+			Expect(os.MkdirAll(dir, 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/geo\n\ngo 1.22\n"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "geo.go"), []byte("package geo\n\n"+source), 0644)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			tmpDir := GinkgoT().TempDir()
+			oldDir = filepath.Join(tmpDir, "old")
+			newDir = filepath.Join(tmpDir, "new")
+			writePackage(oldDir, "func Add(a, b int) int { return a + b }\n")
+		})
+
+		It("fails on breaking changes", func() {
+			writePackage(newDir, "func Add(a int, b float64) int { return a }\n")
+
+			var out bytes.Buffer
+			err := runABIDiff(oldDir, newDir, &abiDiffOptions{Plugin: "cgo"}, &out)
+			Expect(err).To(MatchError("1 breaking ABI changes"))
+			Expect(out.String()).To(ContainSubstring("BREAKING    changed  geo_Add (function Add)"))
+		})
+
+		It("accepts compatible changes", func() {
+			writePackage(newDir, "func Add(a, b int) int { return a + b }\nfunc Sub(a, b int) int { return a - b }\n")
+
+			var out bytes.Buffer
+			Expect(runABIDiff(oldDir, newDir, &abiDiffOptions{Plugin: "cgo"}, &out)).To(Succeed())
+			Expect(out.String()).To(ContainSubstring("compatible  added    geo_Sub (function Sub)"))
+		})
+
+		It("compares against a saved snapshot", func() {
+			snapshotFile := filepath.Join(GinkgoT().TempDir(), "abi.json")
+			Expect(runABISnapshot(oldDir, &abiDiffOptions{Plugin: "cgo", OutputFile: snapshotFile})).To(Succeed())
+
+			var out bytes.Buffer
+			Expect(runABIDiff(snapshotFile, oldDir, &abiDiffOptions{Plugin: "cgo"}, &out)).To(Succeed())
+			Expect(out.String()).To(Equal("No ABI changes\n"))
+
+			err := runABIDiff(snapshotFile, oldDir, &abiDiffOptions{Plugin: "python"}, &out)
+			Expect(err).To(MatchError(ContainSubstring("lowered by plugin cgo, not python")))
+		})
+
+		It("requires --output for a single version", func() {
+			cmd := NewABIDiffCmd()
+			cmd.SetArgs([]string{oldDir})
+			cmd.SetOut(&bytes.Buffer{})
+			cmd.SetErr(&bytes.Buffer{})
+			Expect(cmd.Execute()).To(MatchError(ContainSubstring("needs --output")))
+		})
+	})
+
+	Describe("extractFiles", func() {
+		It("extracts the top-level files of an archive", func() {
+			var archive bytes.Buffer
+			tw := tar.NewWriter(&archive)
+			for name, content := range map[string]string{"geo.go": "package geo\n", "sub/sub.go": "package sub\n"} {
+				Expect(tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})).To(Succeed())
+				_, err := tw.Write([]byte(content))
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(tw.Close()).To(Succeed())
+
+			dir := GinkgoT().TempDir()
+			Expect(extractFiles(&archive, dir)).To(Succeed())
+
+			entries, err := os.ReadDir(dir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].Name()).To(Equal("geo.go"))
+		})
+	})
+
+	Describe("diagnosticPrinter", func() {
+		diag := func(line int, symbol string) core.Diagnostic {
+			pos := token.Position{Filename: "geo.go", Offset: line * 10, Line: line, Column: 6}
+			return core.Skipped(pos, symbol, core.DeclFunction, fmt.Errorf("variadic parameters are not supported"))
+		}
+
+		It("prints each diagnostic once in source order", func() {
+			var out bytes.Buffer
+			printer := newDiagnosticPrinter(&out, false)
+			Expect(printer.report([]core.Diagnostic{diag(7, "Sum"), diag(3, "Max")})).To(Succeed())
+			Expect(printer.report([]core.Diagnostic{diag(7, "Sum")})).To(Succeed())
+			Expect(out.String()).To(Equal(
+				"geo.go:3:6: warning: function Max skipped: variadic parameters are not supported\n" +
+					"geo.go:7:6: warning: function Sum skipped: variadic parameters are not supported\n"))
+		})
+
+		It("fails in strict mode", func() {
+			var out bytes.Buffer
+			printer := newDiagnosticPrinter(&out, true)
+			Expect(printer.report(nil)).To(Succeed())
+			Expect(printer.report([]core.Diagnostic{diag(7, "Sum")})).To(MatchError("1 exported symbols have no bindings (--strict)"))
+			Expect(out.String()).To(Equal("geo.go:7:6: error: function Sum skipped: variadic parameters are not supported\n"))
+		})
+
+		It("stops generate and build in strict mode before writing output", func() {
+			wd, _ := os.Getwd()
+			fixtureDir := filepath.Join(wd, "..", "..", "tests", "fixtures", "simple")
+			outputDir := GinkgoT().TempDir()
+
+			opts := &generateOptions{Plugins: []string{"cgo"}, OutputFile: filepath.Join(outputDir, "main.go"), Strict: true}
+			Expect(runGenerate(fixtureDir, opts)).To(MatchError(ContainSubstring("--strict")))
+			Expect(filepath.Join(outputDir, "main.go")).NotTo(BeAnExistingFile())
+
+			buildOpts := &buildOptions{Plugins: []string{"cgo"}, OutputDir: filepath.Join(outputDir, "build"), Link: core.LinkShared, Strict: true}
+			Expect(runBuild(fixtureDir, buildOpts)).To(MatchError(ContainSubstring("--strict")))
+			Expect(filepath.Join(outputDir, "build", "cgo_plugin")).NotTo(BeAnExistingFile())
+		})
+	})
+
+	Describe("runInspect", func() {
+		var pkgDir string
+
+		BeforeEach(func() {
+			pkgDir = GinkgoT().TempDir()
+			Expect(os.WriteFile(filepath.Join(pkgDir, "go.mod"), []byte("module example.com/geo\n\ngo 1.22\n"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(pkgDir, "geo.go"), []byte(`package geo
+
+func Add(a, b int) int { return a + b }
+
+func DivMod(a, b int) (int, int) { return a / b, a % b }
+
+func Sum(xs ...int) int { return 0 }
+
+func Watch(c chan int) {}
+`), 0644)).To(Succeed())
+		})
+
+		It("prints a table with skip reasons and coverage", func() {
+			var out bytes.Buffer
+			Expect(runInspect(pkgDir, &inspectOptions{Format: formatTable}, &out)).To(Succeed())
+
+			Expect(out.String()).To(Equal(`SYMBOL  KIND      PLUGIN  BINDING
+Add     function  cgo     long long geo_Add(long long a, long long b);
+                  python  add(a: int, b: int) -> int
+DivMod  function  cgo     void geo_DivMod(long long a, long long b, long long* out0, long long* out1);
+                  python  skipped: multiple return values are not supported
+Sum     function  cgo     skipped: variadic parameters are not supported
+                  python  skipped: variadic parameters are not supported
+Watch   function  cgo     skipped: unsupported type chan: channels cannot be exposed via CGO
+                  python  skipped: unsupported type chan: channels cannot be exposed via CGO
+
+Coverage:
+  cgo     2 of 4 symbols  (50.0%)
+  python  1 of 4 symbols  (25.0%)
+`))
+		})
+
+		It("writes a JSON report for the selected plugins", func() {
+			var out bytes.Buffer
+			Expect(runInspect(pkgDir, &inspectOptions{Format: formatJSON, Plugins: []string{"python"}}, &out)).To(Succeed())
+
+			var report inspectReport
+			Expect(json.Unmarshal(out.Bytes(), &report)).To(Succeed())
+			Expect(report.ImportPath).To(Equal("example.com/geo"))
+			Expect(report.Symbols).To(HaveLen(4))
+			Expect(report.Symbols[0].Plugins).To(Equal([]symbolSupport{{
+				Plugin:   "python",
+				Bindings: []generatedBinding{{Name: "add", Signature: "add(a: int, b: int) -> int"}},
+			}}))
+			Expect(report.Coverage).To(Equal([]pluginCoverage{{Plugin: "python", Bound: 1, Total: 4, Percent: 25}}))
+			Expect(out.String()).To(ContainSubstring(`"signature": "add(a: int, b: int) -> int"`))
+
+			// Skipped symbols carry the position of their diagnostic
+			Expect(out.String()).To(ContainSubstring(`"file": "` + filepath.ToSlash(filepath.Join(pkgDir, "geo.go")) + `"`))
+			sum := report.Symbols[2].Plugins[0]
+			Expect(sum.Skipped).To(Equal("variadic parameters are not supported"))
+			Expect(sum.Diagnostic).NotTo(BeNil())
+			Expect(sum.Diagnostic.Pos.Line).To(Equal(7))
+			Expect(sum.Diagnostic.Pos.Column).To(Equal(6))
+			Expect(sum.Diagnostic.Severity).To(Equal(core.SeverityWarning))
+		})
+
+		It("writes prototypes unescaped in JSON", func() {
+			var out bytes.Buffer
+			Expect(runInspect(pkgDir, &inspectOptions{Format: formatJSON, Plugins: []string{"cgo"}}, &out)).To(Succeed())
+			Expect(out.String()).To(ContainSubstring(`"signature": "void geo_DivMod(long long a, long long b, long long* out0, long long* out1);"`))
+			Expect(out.String()).NotTo(ContainSubstring(`\u00`))
+		})
+
+		It("lists filtered symbols outside the coverage", func() {
+			var out bytes.Buffer
+			opts := &inspectOptions{Format: formatTable, Plugins: []string{"cgo"}, Exclude: []string{"Sum", "Watch"}}
+			Expect(runInspect(pkgDir, opts, &out)).To(Succeed())
+
+			Expect(out.String()).To(Equal(`SYMBOL  KIND      PLUGIN  BINDING
+Add     function  cgo     long long geo_Add(long long a, long long b);
+DivMod  function  cgo     void geo_DivMod(long long a, long long b, long long* out0, long long* out1);
+Sum     function  -       filtered out
+Watch   function  -       filtered out
+
+Coverage:
+  cgo  2 of 2 symbols  (100.0%)
+`))
+		})
+
+		It("rejects unknown formats", func() {
+			err := runInspect(pkgDir, &inspectOptions{Format: "xml"}, &bytes.Buffer{})
+			Expect(err).To(MatchError(ContainSubstring(`unknown format "xml"`)))
+		})
+	})
+
+	Describe("watcher", func() {
+		var pkgDir, outputFile string
+
+		writeSource := func(source string) {
+			Expect(os.WriteFile(filepath.Join(pkgDir, "geo.go"), []byte("package geo\n\n"+source), 0644)).To(Succeed())
+		}
+		generated := func() string {
+			content, _ := os.ReadFile(outputFile)
+			return string(content)
+		}
+
+		BeforeEach(func() {
+			pkgDir = GinkgoT().TempDir()
+			outputFile = filepath.Join(GinkgoT().TempDir(), "main.go")
+			Expect(os.WriteFile(filepath.Join(pkgDir, "go.mod"), []byte("module example.com/geo\n\ngo 1.22\n"), 0644)).To(Succeed())
+			writeSource("func Add(a, b int) int { return a + b }\n")
+		})
+
+		It("regenerates on changes and keeps watching after errors", func() {
+			out, errOut := gbytes.NewBuffer(), gbytes.NewBuffer()
+			w := &watcher{out: out, errOut: errOut, inputPath: pkgDir, opts: &watchOptions{
+				generateOptions: generateOptions{Plugins: []string{"cgo"}, OutputFile: outputFile},
+				Interval:        10 * time.Millisecond,
+			}}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error)
+			go func() { done <- w.run(ctx) }()
+
+			Eventually(out).Should(gbytes.Say("Watching"))
+			Expect(generated()).To(ContainSubstring("func geo_Add("))
+
+			writeSource("func Add(a, b int) int { return a + b }\n\nfunc Sub(a, b int) int { return a - b }\n")
+			Eventually(out).Should(gbytes.Say("Changed: geo.go"))
+			Eventually(generated).Should(ContainSubstring("func geo_Sub("))
+
+			writeSource("func Add(a, b int) int {\n")
+			Eventually(errOut).Should(gbytes.Say("Error: parse error"))
+			Expect(generated()).To(ContainSubstring("func geo_Sub("))
+
+			writeSource("func Mul(a, b int) int { return a * b }\n")
+			Eventually(generated).Should(ContainSubstring("func geo_Mul("))
+
+			cancel()
+			Eventually(done).Should(Receive(BeNil()))
+		})
+
+		It("leaves unchanged outputs alone", func() {
+			w := &watcher{out: gbytes.NewBuffer(), errOut: gbytes.NewBuffer(), inputPath: pkgDir, opts: &watchOptions{
+				generateOptions: generateOptions{Plugins: []string{"cgo"}, OutputFile: outputFile},
+			}}
+			w.regenerate()
+			Expect(w.out).To(gbytes.Say("Updated"))
+
+			stale := time.Now().Add(-time.Hour)
+			Expect(os.Chtimes(outputFile, stale, stale)).To(Succeed())
+			w.regenerate()
+			Expect(w.out).NotTo(gbytes.Say("Updated"))
+			info, err := os.Stat(outputFile)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.ModTime()).To(BeTemporally("~", stale, time.Second))
+		})
+	})
+
+	Describe("NewCheckCmd", func() {
+		var pkgDir, outputFile string
+
+		check := func(out *bytes.Buffer) error {
+			cmd := NewCheckCmd()
+			cmd.SetArgs([]string{pkgDir, "-o", outputFile})
+			cmd.SetOut(out)
+			cmd.SetErr(&bytes.Buffer{})
+			return cmd.Execute()
+		}
+
+		BeforeEach(func() {
+			pkgDir = GinkgoT().TempDir()
+			outputFile = filepath.Join(pkgDir, "cgo_plugin", "main.go")
+			Expect(os.WriteFile(filepath.Join(pkgDir, "go.mod"), []byte("module example.com/geo\n\ngo 1.22\n"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(pkgDir, "geo.go"), []byte("package geo\n\nfunc Add(a, b int) int { return a + b }\n"), 0644)).To(Succeed())
+		})
+
+		It("reports a missing generated file", func() {
+			var out bytes.Buffer
+			Expect(check(&out)).To(MatchError(ContainSubstring("1 of 1 generated files are out of date")))
+			Expect(out.String()).To(ContainSubstring("--- /dev/null\n"))
+			Expect(out.String()).To(ContainSubstring("+//export geo_Add\n"))
+		})
+
+		It("passes when the generated file is up to date", func() {
+			Expect(runGenerate(pkgDir, &generateOptions{Plugins: []string{"cgo"}, OutputFile: outputFile})).To(Succeed())
+
+			var out bytes.Buffer
+			Expect(check(&out)).To(Succeed())
+			Expect(out.String()).To(Equal("1 generated files are up to date\n"))
+		})
+
+		It("prints a unified diff for stale files", func() {
+			Expect(runGenerate(pkgDir, &generateOptions{Plugins: []string{"cgo"}, OutputFile: outputFile})).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(pkgDir, "geo.go"), []byte("package geo\n\nfunc Add(a, b int) int { return a + b }\n\nfunc Sub(a, b int) int { return a - b }\n"), 0644)).To(Succeed())
+
+			var out bytes.Buffer
+			Expect(check(&out)).To(MatchError(ContainSubstring("run goanywhere generate")))
+			Expect(out.String()).To(ContainSubstring("main.go\n@@ "))
+			Expect(out.String()).To(ContainSubstring("+//export geo_Sub\n"))
+			Expect(out.String()).NotTo(ContainSubstring("-//export geo_Add"))
+		})
 	})
 
 	Describe("Execute", func() {
@@ -321,7 +1080,7 @@ var _ = Describe("CLI", func() {
 			defer func() { _ = os.RemoveAll(tmpDir) }()
 
 			opts := &generateOptions{
-				Plugin:     "cgo",
+				Plugins:    []string{"cgo"},
 				OutputFile: filepath.Join(tmpDir, "main.go"),
 				ImportPath: "github.com/test/simple",
 				Verbose:    true,
@@ -345,7 +1104,7 @@ var _ = Describe("CLI", func() {
 			defer func() { _ = os.RemoveAll(tmpDir) }()
 
 			opts := &buildOptions{
-				Plugin:     "cgo",
+				Plugins:    []string{"cgo"},
 				OutputDir:  tmpDir,
 				ImportPath: "github.com/test/simple",
 				Verbose:    true,
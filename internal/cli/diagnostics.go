@@ -0,0 +1,98 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/riceriley59/goanywhere/internal/core"
+)
+
+// diagnosticPrinter prints diagnostics once each and, in strict mode, turns
+// them into errors. It is safe for concurrent use by plugins building in
+// parallel.
+type diagnosticPrinter struct {
+	out    io.Writer
+	strict bool
+
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+func newDiagnosticPrinter(out io.Writer, strict bool) *diagnosticPrinter {
+	return &diagnosticPrinter{out: out, strict: strict, seen: make(map[string]bool)}
+}
+
+// report prints the diagnostics not printed before, in source order. In
+// strict mode they are printed as errors and report fails.
+func (p *diagnosticPrinter) report(diags []core.Diagnostic) error {
+	sorted := append([]core.Diagnostic(nil), diags...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].Pos, sorted[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		// Diagnostics restored from the build cache have no offset
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, diag := range sorted {
+		if p.strict {
+			diag.Severity = core.SeverityError
+		}
+		if diag.Pos.Filename != "" {
+			diag.Pos.Filename = displayPath(diag.Pos.Filename)
+		}
+		line := diag.String()
+		if !p.seen[line] {
+			p.seen[line] = true
+			_, _ = fmt.Fprintln(p.out, line)
+		}
+	}
+	if p.strict && len(diags) > 0 {
+		return fmt.Errorf("%d exported symbols have no bindings (--strict)", len(diags))
+	}
+	return nil
+}
+
+// reportGenerated reports the diagnostics of pkg and of the code the plugin
+// last generated for it
+func (p *diagnosticPrinter) reportGenerated(plugin core.Plugin, pkg *core.ParsedPackage) error {
+	diags := append([]core.Diagnostic(nil), pkg.Diagnostics...)
+	if diagnoser, ok := plugin.(core.Diagnoser); ok {
+		diags = append(diags, diagnoser.Diagnostics()...)
+	}
+	return p.report(diags)
+}
+
+// handler returns a build diagnostic handler that also reports the
+// diagnostics of the packages being built
+func (p *diagnosticPrinter) handler(pkgs ...*core.ParsedPackage) core.DiagnosticHandler {
+	return func(diags []core.Diagnostic) error {
+		var all []core.Diagnostic
+		for _, pkg := range pkgs {
+			all = append(all, pkg.Diagnostics...)
+		}
+		return p.report(append(all, diags...))
+	}
+}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/riceriley59/goanywhere/internal/config"
 	"github.com/riceriley59/goanywhere/internal/core"
 	"github.com/riceriley59/goanywhere/internal/core/factory"
 
@@ -31,10 +32,15 @@ import (
 )
 
 type generateOptions struct {
-	OutputFile string
-	ImportPath string
-	Plugin     string
-	Verbose    bool
+	OutputFile    string
+	ImportPath    string
+	Plugins       []string
+	PluginOptions []string
+	Include       []string
+	Exclude       []string
+	ConfigFile    string
+	Verbose       bool
+	Strict        bool
 }
 
 // NewGenerateCmd creates the generate subcommand
@@ -45,21 +51,37 @@ func NewGenerateCmd() *cobra.Command {
 	pluginList := strings.Join(factory.List(), ", ")
 
 	cmd := &cobra.Command{
-		Use:   "generate <input-directory>",
+		Use:   "generate [input-directory]",
 		Short: "Generate plugin code for a Go package",
 		Long: fmt.Sprintf(`Generate plugin code that exposes Go functions and structs to other languages.
 
 The generator processes all .go files in the specified directory and creates
-plugin code for the specified target language.
+plugin code for each selected plugin. Several plugins share one parse of the
+package and generate concurrently.
+
+Without an input directory, every target listed in goanywhere.yaml is
+generated with the plugins, filters and overrides configured for it.
 
 Supported plugins: %s
 
 Example:
   goanywhere generate ./mypackage -o plugin.go
   goanywhere generate ./mypackage --import-path github.com/user/mypackage
-  goanywhere generate ./mypackage --plugin cgo`, pluginList),
-		Args: cobra.ExactArgs(1),
+  goanywhere generate ./mypackage --plugin cgo
+  goanywhere generate ./mypackage --plugin cgo,python
+  goanywhere generate ./mypackage --plugin python --opt build-system=hatch
+  goanywhere generate ./mypackage --exclude 'Debug*' --exclude '*.String'
+  goanywhere generate --config goanywhere.yaml
+
+Plugin-specific options are listed by: goanywhere generate --plugin <name> --help`, pluginList),
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				if err := rejectTargetFlags(cmd, "output", "import-path", "plugin", "include", "exclude"); err != nil {
+					return err
+				}
+				return runGenerateTargets(opts)
+			}
 			return runGenerate(args[0], opts)
 		},
 	}
@@ -68,62 +90,145 @@ Example:
 		"Output file path (default: <input>/<plugin>_plugin/main.go)")
 	cmd.Flags().StringVarP(&opts.ImportPath, "import-path", "i", "",
 		"Import path for the target package (required for proper imports)")
-	cmd.Flags().StringVarP(&opts.Plugin, "plugin", "p", "cgo",
-		fmt.Sprintf("Plugin type to generate (%s)", pluginList))
+	cmd.Flags().StringSliceVarP(&opts.Plugins, "plugin", "p", []string{"cgo"},
+		fmt.Sprintf("Plugins to generate, comma-separated or repeatable (%s)", pluginList))
+	cmd.Flags().StringArrayVar(&opts.PluginOptions, "opt", nil,
+		"Plugin-specific option as key=value (repeatable)")
+	cmd.Flags().StringArrayVar(&opts.Include, "include", nil,
+		"Only bind symbols matching a glob or /regexp/ pattern (repeatable)")
+	cmd.Flags().StringArrayVar(&opts.Exclude, "exclude", nil,
+		"Skip symbols matching a glob or /regexp/ pattern (repeatable)")
+	cmd.Flags().StringVar(&opts.ConfigFile, "config", "",
+		"Config file path (default: ./"+config.DefaultFileName+" if present)")
 	cmd.Flags().BoolVarP(&opts.Verbose, "verbose", "v", false,
 		"Verbose output showing parsed constructs and skipped items")
+	cmd.Flags().BoolVar(&opts.Strict, "strict", false,
+		"Fail when an exported symbol is skipped")
+
+	addPluginOptionsHelp(cmd)
 
 	return cmd
 }
 
+// packageEmitter receives the code generated by a plugin for a package
+type packageEmitter func(plugin core.Plugin, pkg *core.ParsedPackage, file generatedFile) error
+
 func runGenerate(inputDir string, opts *generateOptions) error {
-	// Resolve input path
-	inputPath, err := filepath.Abs(inputDir)
+	diags := newDiagnosticPrinter(os.Stderr, opts.Strict)
+	return visitPackage(inputDir, opts, func(plugin core.Plugin, pkg *core.ParsedPackage, file generatedFile) error {
+		return generatePackage(plugin, pkg, file, opts.Verbose, diags)
+	})
+}
+
+// runGenerateTargets generates code for every target in the config file
+func runGenerateTargets(opts *generateOptions) error {
+	diags := newDiagnosticPrinter(os.Stderr, opts.Strict)
+	return visitTargets(opts, func(plugin core.Plugin, pkg *core.ParsedPackage, file generatedFile) error {
+		return generatePackage(plugin, pkg, file, opts.Verbose, diags)
+	})
+}
+
+// visitPackage loads the package in inputDir once, generates its code with
+// every plugin selected by opts and passes each file to emit
+func visitPackage(inputDir string, opts *generateOptions, emit packageEmitter) error {
+	inputPath, err := resolveInputDir(inputDir)
+	if err != nil {
+		return err
+	}
+
+	// Get the plugins from factory
+	plugins, err := newPlugins(opts.Plugins, opts.Verbose)
 	if err != nil {
-		return fmt.Errorf("invalid input path: %w", err)
+		return err
 	}
 
-	// Check if input directory exists
-	info, err := os.Stat(inputPath)
+	// Apply plugin-specific options from the config file and --opt flags
+	cfg, err := loadConfig(opts.ConfigFile)
 	if err != nil {
-		return fmt.Errorf("cannot access input directory: %w", err)
+		return err
+	}
+	if err := configurePlugins(plugins, cfg, opts.PluginOptions); err != nil {
+		return err
+	}
+
+	settings := packageSettings{
+		ImportPath: opts.ImportPath,
+		Filter:     core.SymbolFilter{Include: opts.Include, Exclude: opts.Exclude},
 	}
-	if !info.IsDir() {
-		return fmt.Errorf("input path is not a directory: %s", inputPath)
+	pkg, err := loadPackage(inputPath, settings, opts.Verbose)
+	if err != nil {
+		return err
 	}
 
-	// Get the plugin from factory
-	plugin, err := factory.Get(opts.Plugin, opts.Verbose)
+	return emitFiles(plugins, pkg, inputPath, opts.OutputFile, emit)
+}
+
+// visitTargets passes every target of the config file to emit, once per plugin
+func visitTargets(opts *generateOptions, emit packageEmitter) error {
+	cfg, err := loadTargets(opts.ConfigFile)
 	if err != nil {
 		return err
 	}
 
-	// Create parser and parse package
-	parser := core.NewParser(opts.Verbose)
+	for i := range cfg.Targets {
+		target := &cfg.Targets[i]
+
+		inputPath, err := resolveInputDir(target.Package)
+		if err != nil {
+			return fmt.Errorf("target %s: %w", target.Package, err)
+		}
+
+		pkg, err := loadPackage(inputPath, targetSettings(target), opts.Verbose)
+		if err != nil {
+			return fmt.Errorf("target %s: %w", target.Package, err)
+		}
+
+		outputRoot := target.Output
+		if outputRoot == "" {
+			outputRoot = inputPath
+		}
+
+		plugins, err := newPlugins(target.Plugins, opts.Verbose)
+		if err != nil {
+			return fmt.Errorf("target %s: %w", target.Package, err)
+		}
+		if err := rejectUnclaimedOptions(plugins, opts.PluginOptions); err != nil {
+			return fmt.Errorf("target %s: %w", target.Package, err)
+		}
+		for _, plugin := range plugins {
+			options := cfg.TargetPluginOptions(target, plugin.Name())
+			if err := configurePlugin(plugin, options, forwardOptions(plugin, opts.PluginOptions)); err != nil {
+				return fmt.Errorf("target %s: %w", target.Package, err)
+			}
+		}
 
-	if opts.Verbose {
-		fmt.Printf("Parsing package at: %s\n", inputPath)
+		if err := emitFiles(plugins, pkg, outputRoot, "", emit); err != nil {
+			return fmt.Errorf("target %s: %w", target.Package, err)
+		}
 	}
 
-	pkg, err := parser.ParsePackage(inputPath)
+	return nil
+}
+
+// emitFiles generates the code of every plugin for pkg concurrently and
+// passes it to emit in plugin order
+func emitFiles(plugins []core.Plugin, pkg *core.ParsedPackage, outputRoot, outputFile string, emit packageEmitter) error {
+	files, err := generateFiles(plugins, pkg, outputRoot, outputFile)
 	if err != nil {
-		return fmt.Errorf("parse error: %w", err)
-	}
-
-	// Set import path if provided
-	if opts.ImportPath != "" {
-		pkg.ImportPath = opts.ImportPath
-	} else {
-		// Try to infer import path from go.mod
-		importPath, err := inferImportPath(inputPath)
-		if err == nil {
-			pkg.ImportPath = importPath
-		} else {
-			return fmt.Errorf("could not determine import path: use --import-path flag")
+		return err
+	}
+	for i, plugin := range plugins {
+		if err := emit(plugin, pkg, files[i]); err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	if opts.Verbose {
+// generatePackage writes the code generated by plugin for pkg, after
+// reporting its diagnostics to diags
+func generatePackage(plugin core.Plugin, pkg *core.ParsedPackage, file generatedFile, verbose bool, diags *diagnosticPrinter) error {
+	if verbose {
 		fmt.Printf("Package: %s\n", pkg.Name)
 		fmt.Printf("Import path: %s\n", pkg.ImportPath)
 		fmt.Printf("Plugin: %s\n", plugin.Name())
@@ -137,47 +242,27 @@ func runGenerate(inputDir string, opts *generateOptions) error {
 		}
 	}
 
-	// Generate plugin code using the plugin interface
-	code, err := plugin.Generate(pkg)
-	if err != nil {
-		return fmt.Errorf("generation error: %w", err)
-	}
-
-	// Determine output path
-	outputPath := opts.OutputFile
-	if outputPath == "" {
-		// Default to a subdirectory based on plugin type
-		switch plugin.Name() {
-		case "python":
-			outputPath = filepath.Join(inputPath, plugin.Name()+"_plugin", pkg.Name+".py")
-		default:
-			outputPath = filepath.Join(inputPath, plugin.Name()+"_plugin", "main.go")
-		}
-	}
-
-	// Make output path absolute
-	outputPath, err = filepath.Abs(outputPath)
-	if err != nil {
-		return fmt.Errorf("invalid output path: %w", err)
+	if err := diags.reportGenerated(plugin, pkg); err != nil {
+		return err
 	}
 
 	// Create output directory if needed
-	outputDir := filepath.Dir(outputPath)
+	outputDir := filepath.Dir(file.Path)
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		return fmt.Errorf("cannot create output directory: %w", err)
 	}
 
 	// Write output file
-	if err := os.WriteFile(outputPath, code, 0644); err != nil {
+	if err := os.WriteFile(file.Path, file.Code, 0644); err != nil {
 		return fmt.Errorf("write error: %w", err)
 	}
 
-	fmt.Printf("Generated %s plugin: %s\n", plugin.Name(), outputPath)
+	fmt.Printf("Generated %s plugin: %s\n", plugin.Name(), file.Path)
 
 	// Print build instructions based on plugin type
 	if plugin.Name() == "cgo" {
 		fmt.Println("\nTo build as shared library:")
-		fmt.Printf("  CGO_ENABLED=1 go build -buildmode=c-shared -o lib%s.so %s\n", pkg.Name, outputPath)
+		fmt.Printf("  CGO_ENABLED=1 go build -buildmode=c-shared -o lib%s.so %s\n", pkg.Name, file.Path)
 	}
 
 	return nil
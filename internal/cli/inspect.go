@@ -0,0 +1,303 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/riceriley59/goanywhere/internal/core"
+	"github.com/riceriley59/goanywhere/internal/core/factory"
+)
+
+type inspectOptions struct {
+	ImportPath string
+	Prefix     string
+	Plugins    []string
+	Include    []string
+	Exclude    []string
+	Format     string
+}
+
+// Output formats of the inspect command
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+// inspectReport is the support matrix of a package
+type inspectReport struct {
+	Package    string           `json:"package"`
+	ImportPath string           `json:"import_path"`
+	Symbols    []inspectSymbol  `json:"symbols"`
+	Coverage   []pluginCoverage `json:"coverage"`
+}
+
+// inspectSymbol is an exported declaration and what each plugin makes of it
+type inspectSymbol struct {
+	core.Decl
+	Filtered bool            `json:"filtered,omitempty"`
+	Plugins  []symbolSupport `json:"plugins"`
+}
+
+// symbolSupport is what one plugin generates for a declaration, or why it
+// generates nothing
+type symbolSupport struct {
+	Plugin     string             `json:"plugin"`
+	Bindings   []generatedBinding `json:"bindings,omitempty"`
+	Skipped    string             `json:"skipped,omitempty"`
+	Diagnostic *core.Diagnostic   `json:"diagnostic,omitempty"` // Where and why the declaration was skipped
+}
+
+// generatedBinding is a generated name and its signature
+type generatedBinding struct {
+	Name      string `json:"name"`
+	Signature string `json:"signature"`
+}
+
+// pluginCoverage counts the declarations a plugin binds
+type pluginCoverage struct {
+	Plugin  string  `json:"plugin"`
+	Bound   int     `json:"bound"`
+	Total   int     `json:"total"`
+	Percent float64 `json:"percent"`
+}
+
+// NewInspectCmd creates the inspect subcommand
+func NewInspectCmd() *cobra.Command {
+	opts := &inspectOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "inspect <input-directory>",
+		Short: "Show what each plugin generates for the symbols of a package",
+		Long: `List every exported function, struct, field and method of a Go package with
+what it becomes in each plugin: the C prototype of the exported symbol, as
+declared in the library header, or the Python function, class, property or
+method. Declarations that are dropped show
+the reason, such as an unsupported type, variadic parameters or multiple
+return values. Declarations removed by --include or --exclude are listed as
+filtered out and do not count towards coverage. A coverage summary per plugin
+follows the table.
+
+Examples:
+  goanywhere inspect ./mypackage
+  goanywhere inspect ./mypackage --plugin python
+  goanywhere inspect ./mypackage --format json
+  goanywhere inspect ./mypackage --exclude '/^Debug/'`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runInspect(args[0], opts, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.ImportPath, "import-path", "i", "",
+		"Import path for the package (default: inferred from go.mod)")
+	cmd.Flags().StringVar(&opts.Prefix, "prefix", "",
+		"Export prefix the library is built with")
+	cmd.Flags().StringSliceVarP(&opts.Plugins, "plugin", "p", nil,
+		"Plugins to inspect (default: all)")
+	cmd.Flags().StringArrayVar(&opts.Include, "include", nil,
+		"Only bind symbols matching a glob or /regexp/ pattern (repeatable)")
+	cmd.Flags().StringArrayVar(&opts.Exclude, "exclude", nil,
+		"Skip symbols matching a glob or /regexp/ pattern (repeatable)")
+	cmd.Flags().StringVar(&opts.Format, "format", formatTable,
+		"Output format (table, json)")
+
+	return cmd
+}
+
+func runInspect(inputDir string, opts *inspectOptions, out io.Writer) error {
+	if opts.Format != formatTable && opts.Format != formatJSON {
+		return fmt.Errorf("unknown format %q (expected %s or %s)", opts.Format, formatTable, formatJSON)
+	}
+
+	inputPath, err := resolveInputDir(inputDir)
+	if err != nil {
+		return err
+	}
+
+	settings := packageSettings{
+		ImportPath:   opts.ImportPath,
+		ExportPrefix: opts.Prefix,
+		Filter:       core.SymbolFilter{Include: opts.Include, Exclude: opts.Exclude},
+	}
+	pkg, err := loadPackage(inputPath, settings, false)
+	if err != nil {
+		return err
+	}
+
+	reporters, err := bindingReporters(opts.Plugins)
+	if err != nil {
+		return err
+	}
+
+	report, err := inspectPackage(pkg, reporters)
+	if err != nil {
+		return err
+	}
+
+	if opts.Format == formatJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(report)
+	}
+	return writeInspectTable(out, report)
+}
+
+// bindingReporters returns the named plugins, or every registered plugin that
+// can report its bindings
+func bindingReporters(names []string) ([]core.Plugin, error) {
+	explicit := len(names) > 0
+	if !explicit {
+		names = factory.List()
+	}
+
+	var plugins []core.Plugin
+	for _, name := range names {
+		plugin, err := factory.Get(name, false)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := plugin.(core.BindingReporter); !ok {
+			if explicit {
+				return nil, fmt.Errorf("plugin %s cannot report its bindings", name)
+			}
+			continue
+		}
+		plugins = append(plugins, plugin)
+	}
+	return plugins, nil
+}
+
+// inspectPackage builds the support matrix of pkg for the given plugins,
+// which must implement core.BindingReporter
+func inspectPackage(pkg *core.ParsedPackage, plugins []core.Plugin) (*inspectReport, error) {
+	report := &inspectReport{Package: pkg.Name, ImportPath: pkg.ImportPath}
+
+	parserSkipped := diagnosticsBySymbol(pkg.Diagnostics)
+
+	decls := pkg.Decls()
+	for _, decl := range decls {
+		report.Symbols = append(report.Symbols, inspectSymbol{Decl: decl})
+	}
+	for _, decl := range pkg.Filtered {
+		report.Symbols = append(report.Symbols, inspectSymbol{Decl: decl, Filtered: true})
+	}
+	sort.SliceStable(report.Symbols, func(i, j int) bool { return report.Symbols[i].Symbol < report.Symbols[j].Symbol })
+
+	for _, plugin := range plugins {
+		bindings, err := plugin.(core.BindingReporter).ReportBindings(pkg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", plugin.Name(), err)
+		}
+
+		bound := make(map[string][]generatedBinding)
+		for _, binding := range bindings.Bindings {
+			bound[binding.Symbol] = append(bound[binding.Symbol], generatedBinding{Name: binding.Name, Signature: binding.Signature})
+		}
+		skipped := diagnosticsBySymbol(bindings.Diagnostics)
+
+		coverage := pluginCoverage{Plugin: plugin.Name(), Total: len(decls)}
+		for i := range report.Symbols {
+			if report.Symbols[i].Filtered {
+				continue
+			}
+			symbol := report.Symbols[i].Symbol
+			support := symbolSupport{Plugin: plugin.Name()}
+			if diag, ok := parserSkipped[symbol]; ok {
+				support.Skipped, support.Diagnostic = diag.Reason, diag
+			} else if generated, ok := bound[symbol]; ok {
+				support.Bindings = generated
+				coverage.Bound++
+			} else if diag, ok := skipped[symbol]; ok {
+				support.Skipped, support.Diagnostic = diag.Reason, diag
+			} else if diag, ok := skipped[parentSymbol(symbol)]; ok {
+				support.Skipped, support.Diagnostic = "struct not bound: "+diag.Reason, diag
+			} else {
+				support.Skipped = "not generated"
+			}
+			report.Symbols[i].Plugins = append(report.Symbols[i].Plugins, support)
+		}
+
+		coverage.Percent = 100
+		if coverage.Total > 0 {
+			coverage.Percent = float64(coverage.Bound) * 100 / float64(coverage.Total)
+		}
+		report.Coverage = append(report.Coverage, coverage)
+	}
+
+	return report, nil
+}
+
+// diagnosticsBySymbol indexes diagnostics by symbol, with file names shown
+// like the diagnostics printed by generate and build
+func diagnosticsBySymbol(diags []core.Diagnostic) map[string]*core.Diagnostic {
+	bySymbol := make(map[string]*core.Diagnostic, len(diags))
+	for _, diag := range diags {
+		if diag.Pos.Filename != "" {
+			diag.Pos.Filename = displayPath(diag.Pos.Filename)
+		}
+		bySymbol[diag.Symbol] = &diag
+	}
+	return bySymbol
+}
+
+// parentSymbol returns the struct of a field or method symbol
+func parentSymbol(symbol string) string {
+	parent, _, _ := strings.Cut(symbol, ".")
+	return parent
+}
+
+// writeInspectTable prints the support matrix with one row per binding
+func writeInspectTable(out io.Writer, report *inspectReport) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "SYMBOL\tKIND\tPLUGIN\tBINDING")
+	for _, symbol := range report.Symbols {
+		symbolCol, kindCol := symbol.Symbol, string(symbol.Kind)
+		if symbol.Filtered {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t-\tfiltered out\n", symbolCol, kindCol)
+			continue
+		}
+		for _, support := range symbol.Plugins {
+			pluginCol := support.Plugin
+			if support.Skipped != "" {
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\tskipped: %s\n", symbolCol, kindCol, pluginCol, support.Skipped)
+				symbolCol, kindCol = "", ""
+				continue
+			}
+			for _, binding := range support.Bindings {
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", symbolCol, kindCol, pluginCol, binding.Signature)
+				symbolCol, kindCol, pluginCol = "", "", ""
+			}
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintln(out, "\nCoverage:")
+	w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, coverage := range report.Coverage {
+		_, _ = fmt.Fprintf(w, "  %s\t%d of %d symbols\t(%.1f%%)\n", coverage.Plugin, coverage.Bound, coverage.Total, coverage.Percent)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,138 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/riceriley59/goanywhere/internal/config"
+	"github.com/riceriley59/goanywhere/internal/core"
+	"github.com/riceriley59/goanywhere/internal/core/factory"
+)
+
+// loadConfig loads the config file at path. With an empty path it falls back
+// to goanywhere.yaml in the working directory, or an empty config if absent.
+func loadConfig(path string) (*config.Config, error) {
+	if path == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("cannot determine working directory: %w", err)
+		}
+		path = config.Find(wd)
+		if path == "" {
+			return &config.Config{}, nil
+		}
+	}
+	return config.Load(path)
+}
+
+// configurePlugin merges options from the config file with --opt
+// assignments (flags take precedence) and applies them to the plugin
+func configurePlugin(plugin core.Plugin, raw map[string]string, assignments []string) error {
+	settings, err := pluginSettings(plugin, raw, assignments)
+	if err != nil {
+		return err
+	}
+	return core.ApplyOptions(plugin, settings)
+}
+
+// pluginSettings merges options from the config file with --opt assignments,
+// which take precedence. Repeated assignments of a list option append.
+func pluginSettings(plugin core.Plugin, raw map[string]string, assignments []string) (map[string]string, error) {
+	settings := make(map[string]string, len(raw)+len(assignments))
+	for key, value := range raw {
+		settings[key] = value
+	}
+
+	var specs []core.OptionSpec
+	if configurable, ok := plugin.(core.Configurable); ok {
+		specs = configurable.Options()
+	}
+	flagOpts, err := core.ParseOptionAssignments(specs, assignments)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range flagOpts {
+		settings[key] = value
+	}
+	return settings, nil
+}
+
+// declaresOption reports whether a plugin accepts the named option
+func declaresOption(plugin core.Plugin, name string) bool {
+	configurable, ok := plugin.(core.Configurable)
+	if !ok {
+		return false
+	}
+	for _, spec := range configurable.Options() {
+		if spec.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// addPluginOptionsHelp appends the options of the plugins selected with
+// --plugin to the command's help output
+func addPluginOptionsHelp(cmd *cobra.Command) {
+	defaultHelp := cmd.HelpFunc()
+	cmd.SetHelpFunc(func(c *cobra.Command, args []string) {
+		defaultHelp(c, args)
+
+		names, err := c.Flags().GetStringSlice("plugin")
+		if err != nil {
+			return
+		}
+		for _, name := range names {
+			if !factory.Has(name) {
+				continue
+			}
+			plugin, err := factory.Get(name, false)
+			if err != nil {
+				continue
+			}
+			writePluginOptions(c.OutOrStdout(), plugin)
+		}
+	})
+}
+
+// writePluginOptions prints the options declared by a plugin
+func writePluginOptions(w io.Writer, plugin core.Plugin) {
+	configurable, ok := plugin.(core.Configurable)
+	if !ok || len(configurable.Options()) == 0 {
+		_, _ = fmt.Fprintf(w, "\nPlugin %s has no plugin-specific options.\n", plugin.Name())
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, "\nPlugin options for %s (set with --opt key=value):\n", plugin.Name())
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	for _, spec := range configurable.Options() {
+		description := spec.Description
+		if len(spec.Choices) > 0 {
+			description += " (" + strings.Join(spec.Choices, ", ") + ")"
+		}
+		if spec.Default != "" {
+			description += fmt.Sprintf(" (default: %s)", spec.Default)
+		}
+		_, _ = fmt.Fprintf(tw, "  %s %s\t%s\n", spec.Name, spec.Type, description)
+	}
+	_ = tw.Flush()
+}
@@ -0,0 +1,142 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/riceriley59/goanywhere/internal/config"
+	"github.com/riceriley59/goanywhere/internal/core"
+	"github.com/riceriley59/goanywhere/internal/core/factory"
+)
+
+// newPlugins returns a new instance of each named plugin
+func newPlugins(names []string, verbose bool) ([]core.Plugin, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no plugin given")
+	}
+
+	seen := make(map[string]bool)
+	plugins := make([]core.Plugin, 0, len(names))
+	for _, name := range names {
+		if seen[name] {
+			return nil, fmt.Errorf("plugin %s given more than once", name)
+		}
+		seen[name] = true
+
+		plugin, err := factory.Get(name, verbose)
+		if err != nil {
+			return nil, err
+		}
+		plugins = append(plugins, plugin)
+	}
+	return plugins, nil
+}
+
+// configurePlugins applies the config file and --opt assignments to each
+// plugin. With several plugins, each receives only the options it declares.
+func configurePlugins(plugins []core.Plugin, cfg *config.Config, assignments []string) error {
+	if len(plugins) > 1 {
+		if err := rejectUnclaimedOptions(plugins, assignments); err != nil {
+			return err
+		}
+	}
+	for _, plugin := range plugins {
+		forwarded := assignments
+		if len(plugins) > 1 {
+			forwarded = forwardOptions(plugin, assignments)
+		}
+		if err := configurePlugin(plugin, cfg.PluginOptions(plugin.Name()), forwarded); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// forEachPlugin calls fn for every plugin concurrently and returns the
+// errors in plugin order
+func forEachPlugin(plugins []core.Plugin, fn func(i int, plugin core.Plugin) error) error {
+	errs := make([]error, len(plugins))
+	var wg sync.WaitGroup
+	for i, plugin := range plugins {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = fn(i, plugin)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// pluginError prefixes err with the plugin name when several plugins run
+func pluginError(plugin core.Plugin, numPlugins int, err error) error {
+	if err == nil || numPlugins == 1 {
+		return err
+	}
+	return fmt.Errorf("%s: %w", plugin.Name(), err)
+}
+
+// generatedFile is the code a plugin generated for a package and the
+// absolute path of its output file
+type generatedFile struct {
+	Path string
+	Code []byte
+}
+
+// generateFiles runs the plugins on pkg concurrently and returns their code
+// in plugin order. Output files default to <outputRoot>/<plugin>_plugin/;
+// outputFile overrides the path of a single plugin.
+func generateFiles(plugins []core.Plugin, pkg *core.ParsedPackage, outputRoot, outputFile string) ([]generatedFile, error) {
+	if outputFile != "" && len(plugins) > 1 {
+		return nil, fmt.Errorf("--output cannot be used with several plugins")
+	}
+
+	files := make([]generatedFile, len(plugins))
+	err := forEachPlugin(plugins, func(i int, plugin core.Plugin) error {
+		code, err := plugin.Generate(pkg)
+		if err != nil {
+			return pluginError(plugin, len(plugins), fmt.Errorf("generation error: %w", err))
+		}
+
+		// Determine output path
+		outputPath := outputFile
+		if outputPath == "" {
+			// Default to a subdirectory based on plugin type
+			switch plugin.Name() {
+			case "python":
+				outputPath = filepath.Join(outputRoot, plugin.Name()+"_plugin", pkg.Name+".py")
+			default:
+				outputPath = filepath.Join(outputRoot, plugin.Name()+"_plugin", "main.go")
+			}
+		}
+
+		// Make output path absolute
+		outputPath, err = filepath.Abs(outputPath)
+		if err != nil {
+			return fmt.Errorf("invalid output path: %w", err)
+		}
+
+		files[i] = generatedFile{Path: outputPath, Code: code}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
@@ -0,0 +1,174 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/riceriley59/goanywhere/internal/config"
+	"github.com/riceriley59/goanywhere/internal/core"
+)
+
+// packageSettings controls how a package is prepared for binding
+type packageSettings struct {
+	ImportPath   string
+	ExportPrefix string
+	Filter       core.SymbolFilter
+	Types        map[string]string
+}
+
+// targetSettings returns the package settings declared by a config target
+func targetSettings(target *config.Target) packageSettings {
+	return packageSettings{
+		ImportPath:   target.ImportPath,
+		ExportPrefix: target.Prefix,
+		Filter:       core.SymbolFilter{Include: target.Include, Exclude: target.Exclude},
+		Types:        target.Types,
+	}
+}
+
+// resolveInputDir returns the absolute path of an existing package directory
+func resolveInputDir(inputDir string) (string, error) {
+	inputPath, err := filepath.Abs(inputDir)
+	if err != nil {
+		return "", fmt.Errorf("invalid input path: %w", err)
+	}
+
+	info, err := os.Stat(inputPath)
+	if err != nil {
+		return "", fmt.Errorf("cannot access input directory: %w", err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("input path is not a directory: %s", inputPath)
+	}
+
+	return inputPath, nil
+}
+
+// loadPackage parses the package at inputPath and applies the import path,
+// symbol filter, type overrides and export prefix from settings
+func loadPackage(inputPath string, settings packageSettings, verbose bool) (*core.ParsedPackage, error) {
+	if err := settings.Filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	parser := core.NewParser(verbose)
+	if verbose {
+		fmt.Printf("Parsing package at: %s\n", inputPath)
+	}
+
+	pkg, err := parser.ParsePackage(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("parse error: %w", err)
+	}
+
+	if settings.ImportPath != "" {
+		pkg.ImportPath = settings.ImportPath
+	} else {
+		importPath, err := inferImportPath(inputPath)
+		if err != nil {
+			return nil, fmt.Errorf("could not determine import path: use --import-path flag")
+		}
+		pkg.ImportPath = importPath
+	}
+
+	if err := core.ApplyTypeOverrides(pkg, settings.Types); err != nil {
+		return nil, err
+	}
+
+	removed := settings.Filter.Apply(pkg)
+	if verbose {
+		for _, decl := range removed {
+			fmt.Printf("  Filtered out: %s %s\n", decl.Kind, decl.Symbol)
+		}
+	}
+	if len(removed) > 0 && len(pkg.Functions) == 0 && len(pkg.Structs) == 0 {
+		return nil, fmt.Errorf("filter matched no symbols in package %s", pkg.Name)
+	}
+
+	pkg.ExportPrefix = settings.ExportPrefix
+
+	return pkg, nil
+}
+
+// loadTargets loads the config file and returns its targets, failing when
+// there is nothing to process
+func loadTargets(configFile string) (*config.Config, error) {
+	cfg, err := loadConfig(configFile)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Path() == "" {
+		return nil, fmt.Errorf("no input directory given and no %s found", config.DefaultFileName)
+	}
+	if len(cfg.Targets) == 0 {
+		return nil, fmt.Errorf("no input directory given and no targets defined in %s", cfg.Path())
+	}
+	return cfg, nil
+}
+
+// rejectTargetFlags fails if any of the named per-package flags were set,
+// since they are ambiguous when processing every config target
+func rejectTargetFlags(cmd *cobra.Command, names ...string) error {
+	for _, name := range names {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("--%s requires an input directory; set it per target in %s instead", name, config.DefaultFileName)
+		}
+	}
+	return nil
+}
+
+// forwardOptions keeps the --opt assignments whose key the plugin declares,
+// so options for one plugin do not break other plugins of the same target.
+// Malformed assignments are kept so configurePlugin reports them.
+func forwardOptions(plugin core.Plugin, assignments []string) []string {
+	var forwarded []string
+	for _, assignment := range assignments {
+		key, _, ok := strings.Cut(assignment, "=")
+		if !ok || declaresOption(plugin, strings.TrimSpace(key)) {
+			forwarded = append(forwarded, assignment)
+		}
+	}
+	return forwarded
+}
+
+// rejectUnclaimedOptions fails on --opt assignments whose key none of the
+// plugins declares, which forwardOptions would otherwise drop silently
+func rejectUnclaimedOptions(plugins []core.Plugin, assignments []string) error {
+	for _, assignment := range assignments {
+		key, _, ok := strings.Cut(assignment, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		claimed := false
+		for _, plugin := range plugins {
+			claimed = claimed || declaresOption(plugin, key)
+		}
+		if !claimed {
+			names := make([]string, len(plugins))
+			for i, plugin := range plugins {
+				names[i] = plugin.Name()
+			}
+			return fmt.Errorf("unknown option %q for plugins %s", key, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/riceriley59/goanywhere/internal/cache"
+	"github.com/riceriley59/goanywhere/internal/config"
+	"github.com/riceriley59/goanywhere/internal/core"
+	"github.com/riceriley59/goanywhere/internal/core/factory"
+)
+
+type watchOptions struct {
+	generateOptions
+	Build       bool
+	BuildOutput string
+	Interval    time.Duration
+}
+
+// NewWatchCmd creates the watch subcommand
+func NewWatchCmd() *cobra.Command {
+	opts := &watchOptions{}
+
+	// Build supported plugins list for help text
+	pluginList := strings.Join(factory.List(), ", ")
+
+	cmd := &cobra.Command{
+		Use:   "watch <input-directory>",
+		Short: "Regenerate plugin code whenever the package changes",
+		Long: `Watch the .go files of a package and regenerate plugin code when they change.
+
+The package is polled for added, modified and removed files. After a change it
+is parsed again and only the generated files whose content changed are
+rewritten. With --build the library is rebuilt as well. Parse, generate and
+build errors are printed and the command keeps watching until interrupted.
+
+Examples:
+  goanywhere watch ./mypackage
+  goanywhere watch ./mypackage --plugin python
+  goanywhere watch ./mypackage --build --build-output ./dist`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.Interval <= 0 {
+				return fmt.Errorf("--interval must be positive")
+			}
+			inputPath, err := resolveInputDir(args[0])
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+
+			w := &watcher{out: cmd.OutOrStdout(), errOut: cmd.ErrOrStderr(), inputPath: inputPath, opts: opts}
+			return w.run(ctx)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.OutputFile, "output", "o", "",
+		"Output file path (default: <input>/<plugin>_plugin/main.go)")
+	cmd.Flags().StringVarP(&opts.ImportPath, "import-path", "i", "",
+		"Import path for the target package (required for proper imports)")
+	cmd.Flags().StringSliceVarP(&opts.Plugins, "plugin", "p", []string{"cgo"},
+		fmt.Sprintf("Plugins to generate, comma-separated or repeatable (%s)", pluginList))
+	cmd.Flags().StringArrayVar(&opts.PluginOptions, "opt", nil,
+		"Plugin-specific option as key=value (repeatable)")
+	cmd.Flags().StringArrayVar(&opts.Include, "include", nil,
+		"Only bind symbols matching a glob or /regexp/ pattern (repeatable)")
+	cmd.Flags().StringArrayVar(&opts.Exclude, "exclude", nil,
+		"Skip symbols matching a glob or /regexp/ pattern (repeatable)")
+	cmd.Flags().StringVar(&opts.ConfigFile, "config", "",
+		"Config file path (default: ./"+config.DefaultFileName+" if present)")
+	cmd.Flags().BoolVarP(&opts.Verbose, "verbose", "v", false,
+		"Verbose output")
+	cmd.Flags().BoolVar(&opts.Build, "build", false,
+		"Also rebuild the library after each change")
+	cmd.Flags().StringVar(&opts.BuildOutput, "build-output", "",
+		"Output directory of --build (default: <input>/<plugin>_build)")
+	cmd.Flags().DurationVar(&opts.Interval, "interval", 500*time.Millisecond,
+		"How often the package is checked for changes")
+
+	addPluginOptionsHelp(cmd)
+
+	return cmd
+}
+
+// watcher regenerates the plugin code of a package when its sources change
+type watcher struct {
+	out       io.Writer
+	errOut    io.Writer
+	inputPath string
+	opts      *watchOptions
+	stamps    cache.Snapshot
+}
+
+// run generates once, then polls the package until ctx is done
+func (w *watcher) run(ctx context.Context) error {
+	stamps, err := sourceStamps(w.inputPath)
+	if err != nil {
+		return err
+	}
+	w.stamps = stamps
+	w.regenerate()
+	_, _ = fmt.Fprintf(w.out, "Watching %s for changes (Ctrl+C to stop)\n", displayPath(w.inputPath))
+
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		stamps, err := sourceStamps(w.inputPath)
+		if err != nil {
+			_, _ = fmt.Fprintln(w.errOut, "Error:", err)
+			continue
+		}
+		changed := w.stamps.Changed(stamps)
+		if len(changed) == 0 {
+			continue
+		}
+		w.stamps = stamps
+
+		_, _ = fmt.Fprintf(w.out, "Changed: %s\n", strings.Join(changed, ", "))
+		w.regenerate()
+	}
+}
+
+// regenerate rewrites the generated files that changed and rebuilds the
+// library when requested. Errors are printed, not returned, so watching
+// continues after a broken edit.
+func (w *watcher) regenerate() {
+	diags := newDiagnosticPrinter(w.errOut, false)
+	err := visitPackage(w.inputPath, &w.opts.generateOptions, func(plugin core.Plugin, pkg *core.ParsedPackage, file generatedFile) error {
+		return w.write(plugin, pkg, file, diags)
+	})
+	if err != nil {
+		_, _ = fmt.Fprintln(w.errOut, "Error:", err)
+		return
+	}
+
+	if w.opts.Build {
+		if err := runBuild(w.inputPath, w.buildOptions()); err != nil {
+			_, _ = fmt.Fprintln(w.errOut, "Error:", err)
+			return
+		}
+		_, _ = fmt.Fprintf(w.out, "Rebuilt %s\n", strings.Join(w.opts.Plugins, ", "))
+	}
+}
+
+// write writes the generated code for pkg unless the file on disk already
+// holds it
+func (w *watcher) write(plugin core.Plugin, pkg *core.ParsedPackage, file generatedFile, diags *diagnosticPrinter) error {
+	if err := diags.reportGenerated(plugin, pkg); err != nil {
+		return err
+	}
+
+	outputPath, code := file.Path, file.Code
+	current, err := os.ReadFile(outputPath)
+	if err == nil && bytes.Equal(current, code) {
+		if w.opts.Verbose {
+			_, _ = fmt.Fprintf(w.out, "%s: unchanged\n", displayPath(outputPath))
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("cannot create output directory: %w", err)
+	}
+	if err := os.WriteFile(outputPath, code, 0644); err != nil {
+		return fmt.Errorf("write error: %w", err)
+	}
+	_, _ = fmt.Fprintf(w.out, "Updated %s\n", displayPath(outputPath))
+	return nil
+}
+
+// buildOptions returns the build settings of --build
+func (w *watcher) buildOptions() *buildOptions {
+	return &buildOptions{
+		OutputDir:     w.opts.BuildOutput,
+		ImportPath:    w.opts.ImportPath,
+		Plugins:       w.opts.Plugins,
+		PluginOptions: w.opts.PluginOptions,
+		Include:       w.opts.Include,
+		Exclude:       w.opts.Exclude,
+		ConfigFile:    w.opts.ConfigFile,
+		Verbose:       w.opts.Verbose,
+		Link:          core.LinkShared,
+	}
+}
+
+// sourceStamps returns the stamps of the non-test .go files in dir
+func sourceStamps(dir string) (cache.Snapshot, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read package directory: %w", err)
+	}
+
+	stamps := make(cache.Snapshot)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			// Removed since the directory was read
+			continue
+		}
+		stamps[name] = cache.Stamp(info)
+	}
+	return stamps, nil
+}
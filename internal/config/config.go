@@ -0,0 +1,192 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// DefaultFileName is the config file looked up in the working directory
+const DefaultFileName = "goanywhere.yaml"
+
+// Config represents a goanywhere.yaml project file
+type Config struct {
+	// Plugins holds plugin-specific options keyed by plugin name
+	Plugins map[string]OptionValues `yaml:"plugins"`
+
+	// Targets lists the packages processed by generate and build without arguments
+	Targets []Target `yaml:"targets"`
+
+	// path is the file the config was loaded from
+	path string
+}
+
+// Target describes one Go package and the plugins it is bound with
+type Target struct {
+	// Package is the package directory, relative to the config file
+	Package string `yaml:"package"`
+	// ImportPath overrides the import path inferred from go.mod
+	ImportPath string `yaml:"import-path"`
+	// Plugins lists the plugins to run for the package (default: cgo)
+	Plugins []string `yaml:"plugins"`
+	// Output is the output root, relative to the config file (default: the package directory)
+	Output string `yaml:"output"`
+	// LibName overrides the shared library name (default: lib<package>)
+	LibName string `yaml:"lib-name"`
+	// Prefix replaces the package name in exported C symbols
+	Prefix string `yaml:"prefix"`
+	// Include keeps only symbols matching these glob or /regexp/ patterns
+	Include []string `yaml:"include"`
+	// Exclude drops symbols matching these glob or /regexp/ patterns
+	Exclude []string `yaml:"exclude"`
+	// Types maps package-level named types to the builtin type they are bound as
+	Types map[string]string `yaml:"types"`
+	// Options holds per-plugin options overriding the top-level plugins section
+	Options map[string]OptionValues `yaml:"options"`
+}
+
+// OptionValues holds the options of a plugin. A YAML sequence is stored as
+// its items on separate lines, the form of list options.
+type OptionValues map[string]string
+
+// UnmarshalYAML decodes a mapping of scalars or sequences of scalars
+func (v *OptionValues) UnmarshalYAML(node *yaml.Node) error {
+	var raw map[string]yaml.Node
+	if err := node.Decode(&raw); err != nil {
+		return err
+	}
+
+	values := make(OptionValues, len(raw))
+	for key, value := range raw {
+		if value.Kind != yaml.SequenceNode {
+			var scalar string
+			if err := value.Decode(&scalar); err != nil {
+				return err
+			}
+			values[key] = scalar
+			continue
+		}
+
+		var items []string
+		if err := value.Decode(&items); err != nil {
+			return err
+		}
+		values[key] = strings.Join(items, "\n")
+	}
+	*v = values
+	return nil
+}
+
+// Load reads and parses a config file
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read config file: %w", err)
+	}
+
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config path: %w", err)
+	}
+	cfg.path = absPath
+
+	// Resolve target paths against the config file's directory
+	dir := filepath.Dir(absPath)
+	for i := range cfg.Targets {
+		cfg.Targets[i].Package = resolvePath(dir, cfg.Targets[i].Package)
+		if cfg.Targets[i].Output != "" {
+			cfg.Targets[i].Output = resolvePath(dir, cfg.Targets[i].Output)
+		}
+	}
+
+	return cfg, nil
+}
+
+// Parse parses config file contents, rejecting unknown keys
+func Parse(data []byte) (*Config, error) {
+	cfg := &Config{}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	for i := range cfg.Targets {
+		target := &cfg.Targets[i]
+		if target.Package == "" {
+			return nil, fmt.Errorf("target %d: package is required", i+1)
+		}
+		if len(target.Plugins) == 0 {
+			target.Plugins = []string{"cgo"}
+		}
+	}
+
+	return cfg, nil
+}
+
+// Find returns the path of DefaultFileName in dir, or "" if it does not exist
+func Find(dir string) string {
+	path := filepath.Join(dir, DefaultFileName)
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		return path
+	}
+	return ""
+}
+
+// Path returns the file the config was loaded from
+func (c *Config) Path() string {
+	return c.path
+}
+
+// PluginOptions returns a copy of the options configured for a plugin
+func (c *Config) PluginOptions(plugin string) map[string]string {
+	opts := make(map[string]string, len(c.Plugins[plugin]))
+	for key, value := range c.Plugins[plugin] {
+		opts[key] = value
+	}
+	return opts
+}
+
+// TargetPluginOptions returns the options configured for a plugin: the config's
+// plugins section overridden by the target's own options
+func (c *Config) TargetPluginOptions(target *Target, plugin string) map[string]string {
+	opts := c.PluginOptions(plugin)
+	for key, value := range target.Options[plugin] {
+		opts[key] = value
+	}
+	return opts
+}
+
+// resolvePath makes path absolute relative to dir
+func resolvePath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,203 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestConfig(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Config Suite")
+}
+
+var _ = Describe("Config", func() {
+	var tmpDir string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "config")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		_ = os.RemoveAll(tmpDir)
+	})
+
+	Describe("Parse", func() {
+		It("parses plugin sections", func() {
+			cfg, err := Parse([]byte("plugins:\n  python:\n    build-system: hatch\n    flag: true\n"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.PluginOptions("python")).To(Equal(map[string]string{
+				"build-system": "hatch",
+				"flag":         "true",
+			}))
+		})
+
+		It("accepts an empty file", func() {
+			cfg, err := Parse(nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.PluginOptions("python")).To(BeEmpty())
+		})
+
+		It("rejects unknown keys", func() {
+			_, err := Parse([]byte("bogus: 1\n"))
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("parses targets", func() {
+			cfg, err := Parse([]byte(`targets:
+  - package: ./mathlib
+    import-path: example.com/mathlib
+    plugins: [cgo, python]
+    output: dist/mathlib
+    lib-name: libmath
+    prefix: math
+    include: ["Add*", "Point.*"]
+    exclude: ["Debug*"]
+    types:
+      Celsius: float64
+    options:
+      python:
+        build-system: uv
+`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.Targets).To(HaveLen(1))
+			target := cfg.Targets[0]
+			Expect(target.Package).To(Equal("./mathlib"))
+			Expect(target.ImportPath).To(Equal("example.com/mathlib"))
+			Expect(target.Plugins).To(Equal([]string{"cgo", "python"}))
+			Expect(target.Output).To(Equal("dist/mathlib"))
+			Expect(target.LibName).To(Equal("libmath"))
+			Expect(target.Prefix).To(Equal("math"))
+			Expect(target.Include).To(Equal([]string{"Add*", "Point.*"}))
+			Expect(target.Exclude).To(Equal([]string{"Debug*"}))
+			Expect(target.Types).To(Equal(map[string]string{"Celsius": "float64"}))
+			Expect(target.Options["python"]["build-system"]).To(Equal("uv"))
+		})
+
+		It("stores option sequences one item per line", func() {
+			cfg, err := Parse([]byte(`plugins:
+  python:
+    version: 1.2.0
+    dependencies: ["numpy>=1.20,<2", requests]
+`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.PluginOptions("python")).To(Equal(map[string]string{
+				"version":      "1.2.0",
+				"dependencies": "numpy>=1.20,<2\nrequests",
+			}))
+
+			_, err = Parse([]byte("plugins:\n  python:\n    authors: {name: x}\n"))
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("defaults target plugins to cgo", func() {
+			cfg, err := Parse([]byte("targets:\n  - package: ./a\n"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.Targets[0].Plugins).To(Equal([]string{"cgo"}))
+		})
+
+		It("requires a package for each target", func() {
+			_, err := Parse([]byte("targets:\n  - plugins: [cgo]\n"))
+			Expect(err).To(MatchError(ContainSubstring("target 1: package is required")))
+		})
+	})
+
+	Describe("TargetPluginOptions", func() {
+		It("overrides the plugins section with target options", func() {
+			cfg, err := Parse([]byte(`plugins:
+  python:
+    build-system: hatch
+    other: kept
+targets:
+  - package: ./a
+    options:
+      python:
+        build-system: uv
+`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.TargetPluginOptions(&cfg.Targets[0], "python")).To(Equal(map[string]string{
+				"build-system": "uv",
+				"other":        "kept",
+			}))
+		})
+	})
+
+	Describe("PluginOptions", func() {
+		It("returns a copy", func() {
+			cfg, err := Parse([]byte("plugins:\n  cgo:\n    a: b\n"))
+			Expect(err).NotTo(HaveOccurred())
+			opts := cfg.PluginOptions("cgo")
+			opts["a"] = "changed"
+			Expect(cfg.PluginOptions("cgo")["a"]).To(Equal("b"))
+		})
+	})
+
+	Describe("Load", func() {
+		It("loads a config file and records its path", func() {
+			path := filepath.Join(tmpDir, DefaultFileName)
+			Expect(os.WriteFile(path, []byte("plugins:\n  python:\n    build-system: uv\n"), 0644)).To(Succeed())
+
+			cfg, err := Load(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.Path()).To(Equal(path))
+			Expect(cfg.PluginOptions("python")["build-system"]).To(Equal("uv"))
+		})
+
+		It("resolves target paths against the config file directory", func() {
+			path := filepath.Join(tmpDir, DefaultFileName)
+			Expect(os.WriteFile(path, []byte("targets:\n  - package: ./pkg\n    output: ../out\n  - package: /abs/pkg\n"), 0644)).To(Succeed())
+
+			cfg, err := Load(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.Targets[0].Package).To(Equal(filepath.Join(tmpDir, "pkg")))
+			Expect(cfg.Targets[0].Output).To(Equal(filepath.Join(filepath.Dir(tmpDir), "out")))
+			Expect(cfg.Targets[1].Package).To(Equal("/abs/pkg"))
+			Expect(cfg.Targets[1].Output).To(BeEmpty())
+		})
+
+		It("returns error for missing file", func() {
+			_, err := Load(filepath.Join(tmpDir, "missing.yaml"))
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("returns error for invalid yaml", func() {
+			path := filepath.Join(tmpDir, DefaultFileName)
+			Expect(os.WriteFile(path, []byte("plugins: [\n"), 0644)).To(Succeed())
+
+			_, err := Load(path)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid config file"))
+		})
+	})
+
+	Describe("Find", func() {
+		It("finds goanywhere.yaml in a directory", func() {
+			path := filepath.Join(tmpDir, DefaultFileName)
+			Expect(os.WriteFile(path, nil, 0644)).To(Succeed())
+			Expect(Find(tmpDir)).To(Equal(path))
+		})
+
+		It("returns empty string when absent", func() {
+			Expect(Find(tmpDir)).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,97 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+// IsNumeric reports whether t is an integer or floating-point type.
+// Named types qualify by their underlying type.
+func (t ParsedType) IsNumeric() bool {
+	if t.Kind != KindPrimitive {
+		return false
+	}
+	switch t.Name {
+	case "int", "int8", "int16", "int32", "int64", "rune",
+		"uint", "uint8", "byte", "uint16", "uint32", "uint64",
+		"float32", "float64":
+		return true
+	}
+	return false
+}
+
+// IsBuffer reports whether values of t cross the C ABI as one contiguous
+// buffer of numbers: []T, [][]T and [N]T where T is numeric, byte included
+func (t ParsedType) IsBuffer() bool {
+	if t.ElemType == nil {
+		return false
+	}
+	switch t.Kind {
+	case KindSlice:
+		if t.ElemType.Kind == KindSlice {
+			return t.ElemType.ElemType != nil && t.ElemType.ElemType.IsNumeric()
+		}
+		return t.ElemType.IsNumeric()
+	case KindArray:
+		return t.ElemType.IsNumeric()
+	}
+	return false
+}
+
+// IsRows reports whether t is a buffer of rows, [][]T
+func (t ParsedType) IsRows() bool {
+	return t.IsBuffer() && t.ElemType.Kind == KindSlice
+}
+
+// BufferElem returns the numeric element type of a buffer
+func (t ParsedType) BufferElem() ParsedType {
+	if t.IsRows() {
+		return *t.ElemType.ElemType
+	}
+	return *t.ElemType
+}
+
+// UsesBuffers reports whether any function, method or field of the package
+// passes numbers in buffers
+func (p *ParsedPackage) UsesBuffers() bool {
+	usesBuffer := func(params []ParsedParam, results []ParsedResult) bool {
+		for _, param := range params {
+			if param.Type.IsBuffer() {
+				return true
+			}
+		}
+		for _, result := range results {
+			if result.Type.IsBuffer() {
+				return true
+			}
+		}
+		return false
+	}
+	for _, fn := range p.Functions {
+		if usesBuffer(fn.Params, fn.Results) {
+			return true
+		}
+	}
+	for _, st := range p.Structs {
+		for _, field := range st.Fields {
+			if field.Exported && field.Type.IsBuffer() {
+				return true
+			}
+		}
+		for _, method := range st.Methods {
+			if usesBuffer(method.Params, method.Results) {
+				return true
+			}
+		}
+	}
+	return false
+}
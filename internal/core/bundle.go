@@ -0,0 +1,66 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PrepareBundle checks that packages can be bound into one shared library and
+// defaults each export prefix to the package name so symbols do not collide
+func PrepareBundle(pkgs []*ParsedPackage) error {
+	if len(pkgs) == 0 {
+		return fmt.Errorf("no packages to bundle")
+	}
+
+	importPaths := make(map[string]bool, len(pkgs))
+	names := make(map[string]string, len(pkgs))
+	prefixes := make(map[string]string, len(pkgs))
+	for _, pkg := range pkgs {
+		if importPaths[pkg.ImportPath] {
+			return fmt.Errorf("package %s is bundled more than once", pkg.ImportPath)
+		}
+		importPaths[pkg.ImportPath] = true
+
+		if other, ok := names[pkg.Name]; ok {
+			return fmt.Errorf("packages %s and %s are both named %s", other, pkg.ImportPath, pkg.Name)
+		}
+		names[pkg.Name] = pkg.ImportPath
+
+		if pkg.ExportPrefix == "" {
+			pkg.ExportPrefix = pkg.Name
+		}
+		if other, ok := prefixes[pkg.ExportPrefix]; ok {
+			return fmt.Errorf("packages %s and %s share the export prefix %q", other, pkg.ImportPath, pkg.ExportPrefix)
+		}
+		prefixes[pkg.ExportPrefix] = pkg.ImportPath
+	}
+
+	return nil
+}
+
+// BundleLibraryName returns the library name of a bundle: libraryName if set,
+// otherwise lib followed by the package names joined with underscores
+func BundleLibraryName(pkgs []*ParsedPackage, libraryName string) string {
+	if libraryName != "" {
+		return libraryName
+	}
+	names := make([]string, len(pkgs))
+	for i, pkg := range pkgs {
+		names[i] = pkg.Name
+	}
+	return "lib" + strings.Join(names, "_")
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PrepareBundle", func() {
+	It("defaults export prefixes to package names", func() {
+		geo := &ParsedPackage{Name: "geo", ImportPath: "example.com/geo"}
+		shapes := &ParsedPackage{Name: "shapes", ImportPath: "example.com/shapes", ExportPrefix: "shp"}
+
+		Expect(PrepareBundle([]*ParsedPackage{geo, shapes})).To(Succeed())
+		Expect(geo.ExportPrefix).To(Equal("geo"))
+		Expect(shapes.ExportPrefix).To(Equal("shp"))
+		Expect(geo.StructPrefix("Point")).To(Equal("geo_Point"))
+	})
+
+	It("rejects an empty bundle", func() {
+		Expect(PrepareBundle(nil)).To(MatchError("no packages to bundle"))
+	})
+
+	It("rejects duplicate packages", func() {
+		pkgs := []*ParsedPackage{
+			{Name: "geo", ImportPath: "example.com/geo"},
+			{Name: "geo", ImportPath: "example.com/geo"},
+		}
+		Expect(PrepareBundle(pkgs)).To(MatchError(ContainSubstring("bundled more than once")))
+	})
+
+	It("rejects packages with the same name", func() {
+		pkgs := []*ParsedPackage{
+			{Name: "util", ImportPath: "example.com/a/util"},
+			{Name: "util", ImportPath: "example.com/b/util"},
+		}
+		Expect(PrepareBundle(pkgs)).To(MatchError(ContainSubstring("are both named util")))
+	})
+
+	It("rejects colliding export prefixes", func() {
+		pkgs := []*ParsedPackage{
+			{Name: "a", ImportPath: "example.com/a", ExportPrefix: "x"},
+			{Name: "b", ImportPath: "example.com/b", ExportPrefix: "x"},
+		}
+		Expect(PrepareBundle(pkgs)).To(MatchError(ContainSubstring(`share the export prefix "x"`)))
+	})
+})
+
+var _ = Describe("BundleLibraryName", func() {
+	pkgs := []*ParsedPackage{{Name: "geo"}, {Name: "shapes"}}
+
+	It("joins package names", func() {
+		Expect(BundleLibraryName(pkgs, "")).To(Equal("libgeo_shapes"))
+	})
+
+	It("prefers the configured name", func() {
+		Expect(BundleLibraryName(pkgs, "libcustom")).To(Equal("libcustom"))
+	})
+})
@@ -0,0 +1,49 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+// IsContext reports whether t is context.Context. Hosts pass contexts as
+// handles they can cancel, or 0 for context.Background.
+func (t ParsedType) IsContext() bool {
+	return t.Kind == KindStruct && t.PackagePath == "context" && t.Name == "Context"
+}
+
+// TakesContext reports whether any of params is a context.Context
+func TakesContext(params []ParsedParam) bool {
+	for _, param := range params {
+		if param.Type.IsContext() {
+			return true
+		}
+	}
+	return false
+}
+
+// TakesContexts reports whether any function or method of the package takes
+// a context.Context
+func (p *ParsedPackage) TakesContexts() bool {
+	for _, fn := range p.Functions {
+		if TakesContext(fn.Params) {
+			return true
+		}
+	}
+	for _, st := range p.Structs {
+		for _, method := range st.Methods {
+			if TakesContext(method.Params) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+// DataFields returns the exported fields of st that convert to plain data in
+// one call: numbers, bools and strings, slices and arrays of them, maps of
+// them with string keys, and struct values of the package made of such fields
+func (p *ParsedPackage) DataFields(st ParsedStruct) []ParsedField {
+	var fields []ParsedField
+	for _, field := range st.Fields {
+		if field.Exported && (IsData(field.Type) || p.NestedData(field.Type) != nil) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// HasData reports whether st has data fields and so gets bulk conversions.
+// A struct whose exported fields are all handles gets none, rather than
+// converting to an empty object.
+func (p *ParsedPackage) HasData(st ParsedStruct) bool {
+	return len(p.DataFields(st)) > 0
+}
+
+// NestedData returns the struct of the package that a struct-valued field of
+// type t holds, or nil when t is not one or that struct has no data fields
+func (p *ParsedPackage) NestedData(t ParsedType) *ParsedStruct {
+	if t.Kind != KindStruct || t.PackagePath != "" {
+		return nil
+	}
+	for i := range p.Structs {
+		if p.Structs[i].Name == t.Name {
+			if !p.HasData(p.Structs[i]) {
+				return nil
+			}
+			return &p.Structs[i]
+		}
+	}
+	return nil
+}
+
+// IsData reports whether values of type t convert to JSON and back without
+// loss: byte slices, which encode as base64, and elements of named types,
+// which may encode themselves, are not data
+func IsData(t ParsedType) bool {
+	switch t.Kind {
+	case KindPrimitive, KindString:
+		return true
+	case KindSlice:
+		return t.ElemType != nil && t.ElemType.Name != "byte" && t.ElemType.Name != "uint8" && isScalarData(*t.ElemType)
+	case KindArray:
+		return t.ElemType != nil && isScalarData(*t.ElemType)
+	case KindMap:
+		return t.KeyType != nil && t.KeyType.Kind == KindString && t.KeyType.Named == "" &&
+			t.ElemType != nil && isScalarData(*t.ElemType)
+	}
+	return false
+}
+
+// isScalarData reports whether t is an unnamed number, bool or string
+func isScalarData(t ParsedType) bool {
+	return (t.Kind == KindPrimitive || t.Kind == KindString) && t.Named == ""
+}
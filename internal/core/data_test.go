@@ -0,0 +1,70 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DataFields", func() {
+	prim := func(name string) *ParsedType {
+		return &ParsedType{Kind: KindPrimitive, Name: name}
+	}
+	str := &ParsedType{Kind: KindString, Name: "string"}
+
+	It("selects the exported fields that convert to plain data", func() {
+		point := ParsedType{Kind: KindStruct, Name: "Point"}
+		pkg := &ParsedPackage{Structs: []ParsedStruct{
+			{Name: "Point", Fields: []ParsedField{{Name: "X", Type: *prim("int"), Exported: true}}},
+			{Name: "Reading", Fields: []ParsedField{
+				{Name: "Level", Type: *prim("float64"), Exported: true},
+				{Name: "Temp", Type: ParsedType{Kind: KindPrimitive, Name: "float64", Named: "Celsius"}, Exported: true},
+				{Name: "Tags", Type: ParsedType{Kind: KindSlice, Name: "[]string", ElemType: str}, Exported: true},
+				{Name: "Counts", Type: ParsedType{Kind: KindMap, Name: "map[string]int", KeyType: str, ElemType: prim("int")}, Exported: true},
+				{Name: "Pos", Type: point, Exported: true},
+				{Name: "Owner", Type: ParsedType{Kind: KindPointer, Name: "*Point", ElemType: &point}, Exported: true},
+				{Name: "Raw", Type: ParsedType{Kind: KindSlice, Name: "[]byte", ElemType: prim("byte")}, Exported: true},
+				{Name: "ByID", Type: ParsedType{Kind: KindMap, Name: "map[int]int", KeyType: prim("int"), ElemType: prim("int")}, Exported: true},
+				{Name: "Other", Type: ParsedType{Kind: KindStruct, Name: "Point", PackagePath: "example.com/geo"}, Exported: true},
+				{Name: "hidden", Type: *prim("int")},
+			}},
+		}}
+
+		var names []string
+		for _, field := range pkg.DataFields(pkg.Structs[1]) {
+			names = append(names, field.Name)
+		}
+		Expect(names).To(Equal([]string{"Level", "Temp", "Tags", "Counts", "Pos"}))
+		Expect(pkg.NestedData(point)).To(Equal(&pkg.Structs[0]))
+	})
+
+	It("gives structs whose fields are all handles no data", func() {
+		point := ParsedType{Kind: KindStruct, Name: "Point"}
+		node := ParsedType{Kind: KindStruct, Name: "Node"}
+		pkg := &ParsedPackage{Structs: []ParsedStruct{
+			{Name: "Point", Fields: []ParsedField{{Name: "X", Type: *prim("int"), Exported: true}}},
+			{Name: "Node", Fields: []ParsedField{
+				{Name: "At", Type: ParsedType{Kind: KindPointer, Name: "*Point", ElemType: &point}, Exported: true},
+			}},
+			{Name: "Tree", Fields: []ParsedField{{Name: "Root", Type: node, Exported: true}}},
+		}}
+
+		Expect(pkg.HasData(pkg.Structs[0])).To(BeTrue())
+		Expect(pkg.HasData(pkg.Structs[1])).To(BeFalse())
+		Expect(pkg.NestedData(node)).To(BeNil())
+		Expect(pkg.HasData(pkg.Structs[2])).To(BeFalse(), "a nested struct without data is not data")
+	})
+})
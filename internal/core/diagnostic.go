@@ -0,0 +1,106 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+)
+
+// Severity classifies a diagnostic
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic reports an exported declaration that gets no binding. Its JSON
+// form spells out the position as file, line and column.
+type Diagnostic struct {
+	Pos      token.Position // Position of the declaration's name
+	Severity Severity
+	Decl
+	Reason string
+}
+
+// diagnosticJSON is the JSON encoding of a Diagnostic
+type diagnosticJSON struct {
+	File     string   `json:"file,omitempty"`
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+	Severity Severity `json:"severity"`
+	Decl
+	Reason string `json:"reason"`
+}
+
+// MarshalJSON encodes the diagnostic with its position as file, line and column
+func (d Diagnostic) MarshalJSON() ([]byte, error) {
+	return json.Marshal(diagnosticJSON{
+		File:     d.Pos.Filename,
+		Line:     d.Pos.Line,
+		Column:   d.Pos.Column,
+		Severity: d.Severity,
+		Decl:     d.Decl,
+		Reason:   d.Reason,
+	})
+}
+
+// UnmarshalJSON decodes a diagnostic written by MarshalJSON. The byte offset
+// is not encoded and stays zero.
+func (d *Diagnostic) UnmarshalJSON(data []byte) error {
+	var v diagnosticJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*d = Diagnostic{
+		Pos:      token.Position{Filename: v.File, Line: v.Line, Column: v.Column},
+		Severity: v.Severity,
+		Decl:     v.Decl,
+		Reason:   v.Reason,
+	}
+	return nil
+}
+
+// String formats the diagnostic like the go tools: file:line:col: message
+func (d Diagnostic) String() string {
+	msg := fmt.Sprintf("%s: %s %s skipped: %s", d.Severity, d.Kind, d.Symbol, d.Reason)
+	if d.Pos.IsValid() {
+		return d.Pos.String() + ": " + msg
+	}
+	return msg
+}
+
+// Skipped returns a warning that the declaration at pos gets no binding
+func Skipped(pos token.Position, symbol string, kind DeclKind, err error) Diagnostic {
+	return Diagnostic{
+		Pos:      pos,
+		Severity: SeverityWarning,
+		Decl:     Decl{Symbol: symbol, Kind: kind},
+		Reason:   err.Error(),
+	}
+}
+
+// Diagnoser is implemented by plugins that report the declarations dropped
+// from the code they generate
+type Diagnoser interface {
+	// Diagnostics returns the diagnostics of the last Generate or Build
+	Diagnostics() []Diagnostic
+}
+
+// DiagnosticHandler receives the diagnostics of generated code before it is
+// compiled; an error stops the build
+type DiagnosticHandler func(diags []Diagnostic) error
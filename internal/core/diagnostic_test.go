@@ -0,0 +1,46 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"go/token"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Diagnostic", func() {
+	It("encodes its position as file, line and column", func() {
+		pos := token.Position{Filename: "geo.go", Offset: 42, Line: 7, Column: 6}
+		diag := Skipped(pos, "Sum", DeclFunction, errors.New("variadic parameters are not supported"))
+
+		data, err := json.Marshal(diag)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal(`{"file":"geo.go","line":7,"column":6,"severity":"warning","symbol":"Sum","kind":"function","reason":"variadic parameters are not supported"}`))
+
+		var decoded Diagnostic
+		Expect(json.Unmarshal(data, &decoded)).To(Succeed())
+		pos.Offset = 0
+		Expect(decoded).To(Equal(Diagnostic{Pos: pos, Severity: SeverityWarning, Decl: Decl{Symbol: "Sum", Kind: DeclFunction}, Reason: diag.Reason}))
+	})
+
+	It("leaves out an unknown position", func() {
+		data, err := json.Marshal(Diagnostic{Severity: SeverityError, Decl: Decl{Symbol: "Point", Kind: DeclStruct}, Reason: "no fields"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal(`{"severity":"error","symbol":"Point","kind":"struct","reason":"no fields"}`))
+	})
+})
@@ -0,0 +1,56 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+// ExportKind classifies the symbols of a generated library
+type ExportKind string
+
+const (
+	ExportFunction    ExportKind = "function"
+	ExportMethod      ExportKind = "method"
+	ExportConstructor ExportKind = "constructor"
+	ExportDestructor  ExportKind = "destructor"
+	ExportGetter      ExportKind = "getter"
+	ExportSetter      ExportKind = "setter"
+	ExportConversion  ExportKind = "conversion" // Bulk conversion of a struct to and from JSON
+	ExportIterator    ExportKind = "iterator"   // Pulls the next element of a returned iterator
+	ExportAsync       ExportKind = "async"      // Runs a function or method on a goroutine
+	ExportRuntime     ExportKind = "runtime"    // Helpers such as Free_String
+)
+
+// ExportParam is a parameter or result of an exported symbol
+type ExportParam struct {
+	Name   string `json:"name,omitempty"`
+	Type   string `json:"type"`              // Lowered type, e.g. "C.longlong"
+	GoType string `json:"go_type,omitempty"` // Go type it is converted from or to
+	// Ownership of a returned handle; empty for values that are not handles
+	Ownership Ownership `json:"ownership,omitempty"`
+}
+
+// Export is a symbol of a generated library and the Go declaration it wraps
+type Export struct {
+	Symbol string        `json:"symbol"`
+	Kind   ExportKind    `json:"kind"`
+	Source string        `json:"source,omitempty"` // Func, Struct, Struct.Method or Struct.Field
+	Params []ExportParam `json:"params,omitempty"`
+	Result *ExportParam  `json:"result,omitempty"` // nil for void
+}
+
+// ABIDescriber is implemented by plugins that can list the symbols exported
+// by the code they generate
+type ABIDescriber interface {
+	// Exports returns the symbols generated for pkg, in generation order
+	Exports(pkg *ParsedPackage) ([]Export, error)
+}
@@ -0,0 +1,220 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// SymbolFilter selects which exported symbols get bindings. Patterns are
+// matched against function and struct names, or "Struct.Method" for methods.
+// They use path.Match glob syntax, except that a pattern wrapped in slashes
+// ("/^Get/") is an unanchored regular expression.
+type SymbolFilter struct {
+	// Include keeps only matching symbols (empty keeps everything)
+	Include []string
+	// Exclude drops matching symbols; it takes precedence over Include
+	Exclude []string
+}
+
+// IsEmpty reports whether the filter selects every symbol
+func (f SymbolFilter) IsEmpty() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0
+}
+
+// Validate checks that all patterns are well-formed
+func (f SymbolFilter) Validate() error {
+	for _, patterns := range [][]string{f.Include, f.Exclude} {
+		for _, pattern := range patterns {
+			if _, err := matchPattern(pattern, ""); err != nil {
+				return fmt.Errorf("invalid symbol pattern %q: %w", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Apply removes the symbols not selected by the filter from pkg and returns
+// the removed declarations, which are also recorded in pkg.Filtered.
+// Excluding a struct removes its methods and every function or method whose
+// signature uses it; including a method keeps its struct.
+func (f SymbolFilter) Apply(pkg *ParsedPackage) []Decl {
+	if f.IsEmpty() {
+		return nil
+	}
+
+	// Dropping a method can drop its struct, which in turn drops the
+	// signatures using that struct
+	dropped := make(map[string]bool)
+	for changed := true; changed; {
+		changed = false
+		for _, st := range pkg.Structs {
+			if !dropped[st.Name] && !f.keepsStruct(st, dropped) {
+				dropped[st.Name] = true
+				changed = true
+			}
+		}
+	}
+
+	var removed []Decl
+
+	functions := pkg.Functions[:0]
+	for _, fn := range pkg.Functions {
+		if f.included(fn.Name) && !f.excluded(fn.Name) && !usesStruct(fn.Params, fn.Results, dropped) {
+			functions = append(functions, fn)
+		} else {
+			removed = append(removed, Decl{Symbol: fn.Name, Kind: DeclFunction})
+		}
+	}
+	pkg.Functions = functions
+
+	structs := pkg.Structs[:0]
+	for _, st := range pkg.Structs {
+		if dropped[st.Name] {
+			removed = append(removed, Decl{Symbol: st.Name, Kind: DeclStruct})
+			continue
+		}
+		var methods []ParsedMethod
+		for _, method := range st.Methods {
+			if f.keepsMethod(st, method, dropped) {
+				methods = append(methods, method)
+			} else {
+				removed = append(removed, Decl{Symbol: st.Name + "." + method.Name, Kind: DeclMethod})
+			}
+		}
+		st.Methods = methods
+		structs = append(structs, st)
+	}
+	pkg.Structs = structs
+
+	// Declarations the parser skipped are filtered the same way, so their
+	// diagnostics do not outlive an exclusion
+	diags := pkg.Diagnostics[:0]
+	for _, diag := range pkg.Diagnostics {
+		if f.selects(diag.Decl) {
+			diags = append(diags, diag)
+		} else {
+			removed = append(removed, diag.Decl)
+		}
+	}
+	pkg.Diagnostics = diags
+	pkg.Filtered = append(pkg.Filtered, removed...)
+
+	return removed
+}
+
+// keepsStruct reports whether the filter keeps st, given the structs already
+// dropped: an excluded struct is dropped, and a struct that is not included
+// is kept only for its selected methods
+func (f SymbolFilter) keepsStruct(st ParsedStruct, dropped map[string]bool) bool {
+	if f.excluded(st.Name) {
+		return false
+	}
+	if f.included(st.Name) {
+		return true
+	}
+	for _, method := range st.Methods {
+		if f.keepsMethod(st, method, dropped) {
+			return true
+		}
+	}
+	return false
+}
+
+// keepsMethod reports whether the filter keeps a method of a kept struct
+func (f SymbolFilter) keepsMethod(st ParsedStruct, method ParsedMethod, dropped map[string]bool) bool {
+	symbol := st.Name + "." + method.Name
+	if f.excluded(symbol) || !(f.included(st.Name) || f.included(symbol)) {
+		return false
+	}
+	return !usesStruct(method.Params, method.Results, dropped)
+}
+
+// usesStruct reports whether a signature refers to one of the structs of
+// the package in names
+func usesStruct(params []ParsedParam, results []ParsedResult, names map[string]bool) bool {
+	var uses func(t *ParsedType) bool
+	uses = func(t *ParsedType) bool {
+		if t == nil {
+			return false
+		}
+		if t.Kind == KindStruct && t.PackagePath == "" && names[t.Name] {
+			return true
+		}
+		return uses(t.ElemType) || uses(t.KeyType)
+	}
+	for _, param := range params {
+		if uses(&param.Type) {
+			return true
+		}
+	}
+	for _, result := range results {
+		if uses(&result.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// selects reports whether the filter keeps a single declaration; a struct
+// member is kept unless its struct or itself is excluded
+func (f SymbolFilter) selects(decl Decl) bool {
+	if decl.Kind != DeclMethod && decl.Kind != DeclField {
+		return f.included(decl.Symbol) && !f.excluded(decl.Symbol)
+	}
+	parent, _, _ := strings.Cut(decl.Symbol, ".")
+	if f.excluded(parent) || f.excluded(decl.Symbol) {
+		return false
+	}
+	return f.included(parent) || f.included(decl.Symbol)
+}
+
+// included reports whether symbol matches an include pattern
+func (f SymbolFilter) included(symbol string) bool {
+	if len(f.Include) == 0 {
+		return true
+	}
+	return matchAny(f.Include, symbol)
+}
+
+// excluded reports whether symbol matches an exclude pattern
+func (f SymbolFilter) excluded(symbol string) bool {
+	return matchAny(f.Exclude, symbol)
+}
+
+// matchAny reports whether symbol matches any of the patterns
+func matchAny(patterns []string, symbol string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := matchPattern(pattern, symbol); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPattern matches symbol against a glob or a /regexp/ pattern
+func matchPattern(pattern, symbol string) (bool, error) {
+	if len(pattern) >= 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return false, err
+		}
+		return re.MatchString(symbol), nil
+	}
+	return path.Match(pattern, symbol)
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SymbolFilter", func() {
+	var pkg *ParsedPackage
+
+	BeforeEach(func() {
+		pkg = &ParsedPackage{
+			Name: "geo",
+			Functions: []ParsedFunc{
+				{Name: "Add"}, {Name: "AddAll"}, {Name: "DebugDump"},
+			},
+			Structs: []ParsedStruct{
+				{Name: "Point", Methods: []ParsedMethod{{Name: "Scale"}, {Name: "Debug"}}},
+				{Name: "Shape", Methods: []ParsedMethod{{Name: "Area"}}},
+			},
+		}
+	})
+
+	names := func() []string {
+		var out []string
+		for _, fn := range pkg.Functions {
+			out = append(out, fn.Name)
+		}
+		for _, st := range pkg.Structs {
+			out = append(out, st.Name)
+			for _, m := range st.Methods {
+				out = append(out, st.Name+"."+m.Name)
+			}
+		}
+		return out
+	}
+
+	symbols := func(decls []Decl) []string {
+		var out []string
+		for _, decl := range decls {
+			out = append(out, decl.Symbol)
+		}
+		return out
+	}
+
+	It("keeps everything when empty", func() {
+		Expect(SymbolFilter{}.Apply(pkg)).To(BeEmpty())
+		Expect(names()).To(HaveLen(8))
+	})
+
+	It("keeps only included symbols", func() {
+		removed := SymbolFilter{Include: []string{"Add*", "Shape"}}.Apply(pkg)
+		Expect(names()).To(Equal([]string{"Add", "AddAll", "Shape", "Shape.Area"}))
+		Expect(symbols(removed)).To(ConsistOf("DebugDump", "Point"))
+		Expect(pkg.Filtered).To(ContainElement(Decl{Symbol: "Point", Kind: DeclStruct}))
+	})
+
+	It("keeps the struct of an included method", func() {
+		SymbolFilter{Include: []string{"Point.Scale"}}.Apply(pkg)
+		Expect(names()).To(Equal([]string{"Point", "Point.Scale"}))
+	})
+
+	It("lets exclude take precedence over include", func() {
+		removed := SymbolFilter{Include: []string{"*"}, Exclude: []string{"Debug*", "*.Debug"}}.Apply(pkg)
+		Expect(names()).To(Equal([]string{"Add", "AddAll", "Point", "Point.Scale", "Shape", "Shape.Area"}))
+		Expect(symbols(removed)).To(ConsistOf("DebugDump", "Point.Debug"))
+		Expect(removed).To(ContainElement(Decl{Symbol: "Point.Debug", Kind: DeclMethod}))
+	})
+
+	It("removes the methods of an excluded struct", func() {
+		removed := SymbolFilter{Exclude: []string{"Point"}}.Apply(pkg)
+		Expect(names()).NotTo(ContainElement(HavePrefix("Point")))
+		Expect(symbols(removed)).To(Equal([]string{"Point"}))
+	})
+
+	It("removes the signatures using an excluded struct", func() {
+		point := ParsedType{Kind: KindStruct, Name: "Point"}
+		pkg.Functions[0].Results = []ParsedResult{{Type: ParsedType{Kind: KindPointer, Name: "*Point", ElemType: &point}}}
+		pkg.Structs[1].Methods[0].Params = []ParsedParam{{Name: "ps", Type: ParsedType{Kind: KindSlice, Name: "[]Point", ElemType: &point}}}
+
+		removed := SymbolFilter{Exclude: []string{"Point"}}.Apply(pkg)
+		Expect(names()).To(Equal([]string{"AddAll", "DebugDump", "Shape"}))
+		Expect(symbols(removed)).To(ConsistOf("Add", "Point", "Shape.Area"))
+	})
+
+	It("removes the structs left without selected methods", func() {
+		shape := ParsedType{Kind: KindStruct, Name: "Shape"}
+		pkg.Structs[0].Methods[0].Results = []ParsedResult{{Type: shape}}
+
+		SymbolFilter{Include: []string{"Point.Scale", "Add"}, Exclude: []string{"Shape"}}.Apply(pkg)
+		Expect(names()).To(Equal([]string{"Add"}))
+	})
+
+	It("matches /regexp/ patterns", func() {
+		removed := SymbolFilter{Include: []string{"/^Add/", "/^Point\\.S/"}}.Apply(pkg)
+		Expect(names()).To(Equal([]string{"Add", "AddAll", "Point", "Point.Scale"}))
+		Expect(symbols(removed)).To(ConsistOf("DebugDump", "Point.Debug", "Shape"))
+	})
+
+	It("drops the diagnostics of filtered symbols", func() {
+		pkg.Diagnostics = []Diagnostic{
+			{Decl: Decl{Symbol: "Sum", Kind: DeclFunction}},
+			{Decl: Decl{Symbol: "Watch", Kind: DeclFunction}},
+			{Decl: Decl{Symbol: "Point.Each", Kind: DeclMethod}},
+		}
+		removed := SymbolFilter{Exclude: []string{"Watch", "Point"}}.Apply(pkg)
+		Expect(pkg.Diagnostics).To(Equal([]Diagnostic{{Decl: Decl{Symbol: "Sum", Kind: DeclFunction}}}))
+		Expect(symbols(removed)).To(ConsistOf("Point", "Watch", "Point.Each"))
+	})
+
+	It("rejects malformed patterns", func() {
+		Expect(SymbolFilter{Include: []string{"[a-"}}.Validate()).To(MatchError(ContainSubstring("invalid symbol pattern")))
+		Expect(SymbolFilter{Exclude: []string{"/(/"}}.Validate()).To(MatchError(ContainSubstring("invalid symbol pattern")))
+		Expect(SymbolFilter{Exclude: []string{"Debug*"}}.Validate()).To(Succeed())
+	})
+})
@@ -0,0 +1,213 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// OptionType identifies how a plugin option value is parsed
+type OptionType int
+
+const (
+	OptionString OptionType = iota
+	OptionBool
+	OptionInt
+	OptionList
+)
+
+// String returns the option type name used in help output
+func (t OptionType) String() string {
+	switch t {
+	case OptionBool:
+		return "bool"
+	case OptionInt:
+		return "int"
+	case OptionList:
+		return "list"
+	default:
+		return "string"
+	}
+}
+
+// OptionSpec declares a single plugin-specific option
+type OptionSpec struct {
+	Name        string
+	Type        OptionType
+	Default     string
+	Choices     []string // Allowed values for string options (empty allows any value)
+	Description string
+}
+
+// Options holds validated plugin option values keyed by option name.
+// Values are stored as string, bool, int or []string according to the
+// declared OptionType.
+type Options map[string]any
+
+// String returns a string option value, or "" if unset
+func (o Options) String(name string) string {
+	v, _ := o[name].(string)
+	return v
+}
+
+// Bool returns a bool option value, or false if unset
+func (o Options) Bool(name string) bool {
+	v, _ := o[name].(bool)
+	return v
+}
+
+// Int returns an int option value, or 0 if unset
+func (o Options) Int(name string) int {
+	v, _ := o[name].(int)
+	return v
+}
+
+// List returns a list option value, or nil if unset
+func (o Options) List(name string) []string {
+	v, _ := o[name].([]string)
+	return v
+}
+
+// ParseOptions validates raw key=value settings against the plugin's option
+// specs, converts them to their declared types and fills in defaults
+func ParseOptions(plugin string, specs []OptionSpec, raw map[string]string) (Options, error) {
+	byName := make(map[string]OptionSpec, len(specs))
+	for _, spec := range specs {
+		byName[spec.Name] = spec
+	}
+
+	// Report unknown keys in a stable order
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, ok := byName[key]; !ok {
+			if len(specs) == 0 {
+				return nil, fmt.Errorf("plugin %s does not accept options (got %q)", plugin, key)
+			}
+			return nil, fmt.Errorf("unknown option %q for plugin %s (available: %s)", key, plugin, strings.Join(optionNames(specs), ", "))
+		}
+	}
+
+	opts := make(Options, len(specs))
+	for _, spec := range specs {
+		value, ok := raw[spec.Name]
+		if !ok {
+			value = spec.Default
+		}
+		parsed, err := parseOptionValue(spec, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for option %s of plugin %s: %w", spec.Name, plugin, err)
+		}
+		opts[spec.Name] = parsed
+	}
+
+	return opts, nil
+}
+
+// ApplyOptions parses raw settings for plugins implementing Configurable and
+// hands the result to Configure. Plugins without options reject any setting.
+func ApplyOptions(plugin Plugin, raw map[string]string) error {
+	configurable, ok := plugin.(Configurable)
+	if !ok {
+		if len(raw) > 0 {
+			_, err := ParseOptions(plugin.Name(), nil, raw)
+			return err
+		}
+		return nil
+	}
+
+	opts, err := ParseOptions(plugin.Name(), configurable.Options(), raw)
+	if err != nil {
+		return err
+	}
+	return configurable.Configure(opts)
+}
+
+// ParseOptionAssignments splits repeated key=value flag values into a map.
+// Repeating a list option in specs appends to its items; for other options
+// the last assignment wins.
+func ParseOptionAssignments(specs []OptionSpec, assignments []string) (map[string]string, error) {
+	lists := make(map[string]bool)
+	for _, spec := range specs {
+		if spec.Type == OptionList {
+			lists[spec.Name] = true
+		}
+	}
+
+	raw := make(map[string]string, len(assignments))
+	for _, assignment := range assignments {
+		key, value, ok := strings.Cut(assignment, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid option %q: expected key=value", assignment)
+		}
+		if previous, seen := raw[key]; seen && lists[key] {
+			value = previous + "\n" + value
+		}
+		raw[key] = value
+	}
+	return raw, nil
+}
+
+// parseOptionValue converts a raw value to the type declared by spec
+func parseOptionValue(spec OptionSpec, value string) (any, error) {
+	switch spec.Type {
+	case OptionBool:
+		if value == "" {
+			return false, nil
+		}
+		return strconv.ParseBool(value)
+	case OptionInt:
+		if value == "" {
+			return 0, nil
+		}
+		return strconv.Atoi(value)
+	case OptionList:
+		return splitList(value), nil
+	default:
+		if len(spec.Choices) > 0 && value != "" && !slices.Contains(spec.Choices, value) {
+			return nil, fmt.Errorf("%q is not one of %s", value, strings.Join(spec.Choices, ", "))
+		}
+		return value, nil
+	}
+}
+
+// splitList splits a list option value into its lines, which may hold
+// commas; blank lines are dropped
+func splitList(value string) []string {
+	var items []string
+	for _, line := range strings.Split(value, "\n") {
+		if item := strings.TrimSpace(line); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// optionNames returns the declared option names in declaration order
+func optionNames(specs []OptionSpec) []string {
+	names := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		names = append(names, spec.Name)
+	}
+	return names
+}
@@ -0,0 +1,177 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type optionsPlugin struct {
+	applied Options
+}
+
+func (p *optionsPlugin) Name() string                                { return "opts" }
+func (p *optionsPlugin) Generate(pkg *ParsedPackage) ([]byte, error) { return nil, nil }
+func (p *optionsPlugin) Build(pkg *ParsedPackage, inputPath string, opts *BuildOptions) error {
+	return nil
+}
+func (p *optionsPlugin) Options() []OptionSpec {
+	return []OptionSpec{{Name: "mode", Type: OptionString, Default: "fast"}}
+}
+func (p *optionsPlugin) Configure(opts Options) error {
+	p.applied = opts
+	return nil
+}
+
+type plainPlugin struct{}
+
+func (p *plainPlugin) Name() string                                { return "plain" }
+func (p *plainPlugin) Generate(pkg *ParsedPackage) ([]byte, error) { return nil, nil }
+func (p *plainPlugin) Build(pkg *ParsedPackage, inputPath string, opts *BuildOptions) error {
+	return nil
+}
+
+var _ = Describe("Options", func() {
+	specs := []OptionSpec{
+		{Name: "system", Type: OptionString, Default: "a", Choices: []string{"a", "b"}},
+		{Name: "strict", Type: OptionBool},
+		{Name: "jobs", Type: OptionInt, Default: "4"},
+	}
+
+	Describe("ParseOptions", func() {
+		It("applies defaults for unset options", func() {
+			opts, err := ParseOptions("test", specs, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opts.String("system")).To(Equal("a"))
+			Expect(opts.Bool("strict")).To(BeFalse())
+			Expect(opts.Int("jobs")).To(Equal(4))
+		})
+
+		It("converts values to their declared types", func() {
+			opts, err := ParseOptions("test", specs, map[string]string{
+				"system": "b",
+				"strict": "true",
+				"jobs":   "8",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opts.String("system")).To(Equal("b"))
+			Expect(opts.Bool("strict")).To(BeTrue())
+			Expect(opts.Int("jobs")).To(Equal(8))
+		})
+
+		It("rejects unknown options", func() {
+			_, err := ParseOptions("test", specs, map[string]string{"bogus": "1"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("unknown option \"bogus\""))
+			Expect(err.Error()).To(ContainSubstring("system, strict, jobs"))
+		})
+
+		It("rejects options for plugins without options", func() {
+			_, err := ParseOptions("test", nil, map[string]string{"bogus": "1"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("does not accept options"))
+		})
+
+		It("rejects values outside the declared choices", func() {
+			_, err := ParseOptions("test", specs, map[string]string{"system": "c"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("not one of a, b"))
+		})
+
+		It("rejects malformed bool and int values", func() {
+			_, err := ParseOptions("test", specs, map[string]string{"strict": "maybe"})
+			Expect(err).To(HaveOccurred())
+			_, err = ParseOptions("test", specs, map[string]string{"jobs": "many"})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("splits list values into lines", func() {
+			listSpecs := []OptionSpec{{Name: "deps", Type: OptionList}, {Name: "tags", Type: OptionList}}
+			opts, err := ParseOptions("test", listSpecs, map[string]string{"deps": "numpy>=1.20,<2\n\n  requests \n"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opts.List("deps")).To(Equal([]string{"numpy>=1.20,<2", "requests"}))
+			Expect(opts.List("tags")).To(BeEmpty())
+		})
+	})
+
+	Describe("Options accessors", func() {
+		It("returns zero values for missing or mistyped options", func() {
+			opts := Options{"name": 3}
+			Expect(opts.String("name")).To(BeEmpty())
+			Expect(opts.Bool("missing")).To(BeFalse())
+			Expect(opts.Int("missing")).To(BeZero())
+		})
+	})
+
+	Describe("ParseOptionAssignments", func() {
+		It("splits key=value pairs", func() {
+			raw, err := ParseOptionAssignments(nil, []string{"a=1", "b=x=y", "c="})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(raw).To(Equal(map[string]string{"a": "1", "b": "x=y", "c": ""}))
+		})
+
+		It("lets later assignments win", func() {
+			raw, err := ParseOptionAssignments(nil, []string{"a=1", "a=2"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(raw["a"]).To(Equal("2"))
+		})
+
+		It("appends repeated list assignments", func() {
+			specs := []OptionSpec{{Name: "authors", Type: OptionList}, {Name: "name"}}
+			raw, err := ParseOptionAssignments(specs, []string{"authors=A <a@x>", "name=a", "authors=B <b@x>", "name=b"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(raw).To(Equal(map[string]string{"authors": "A <a@x>\nB <b@x>", "name": "b"}))
+
+			opts, err := ParseOptions("test", specs, raw)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opts.List("authors")).To(Equal([]string{"A <a@x>", "B <b@x>"}))
+		})
+
+		It("rejects assignments without a key", func() {
+			_, err := ParseOptionAssignments(nil, []string{"novalue"})
+			Expect(err).To(HaveOccurred())
+			_, err = ParseOptionAssignments(nil, []string{"=value"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("ApplyOptions", func() {
+		It("configures plugins implementing Configurable", func() {
+			plugin := &optionsPlugin{}
+			Expect(ApplyOptions(plugin, map[string]string{"mode": "slow"})).To(Succeed())
+			Expect(plugin.applied.String("mode")).To(Equal("slow"))
+		})
+
+		It("accepts empty options for plain plugins", func() {
+			Expect(ApplyOptions(&plainPlugin{}, nil)).To(Succeed())
+		})
+
+		It("rejects options for plain plugins", func() {
+			err := ApplyOptions(&plainPlugin{}, map[string]string{"mode": "slow"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("plugin plain does not accept options"))
+		})
+	})
+
+	Describe("OptionType", func() {
+		It("has readable names", func() {
+			Expect(OptionString.String()).To(Equal("string"))
+			Expect(OptionBool.String()).To(Equal("bool"))
+			Expect(OptionInt.String()).To(Equal("int"))
+			Expect(OptionList.String()).To(Equal("list"))
+		})
+	})
+})
@@ -0,0 +1,125 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ApplyTypeOverrides rewrites references to package-level named types as their
+// underlying builtin type, e.g. {"Celsius": "float64"} binds Celsius values as
+// float64. The original type name is kept in ParsedType.Named for conversions.
+func ApplyTypeOverrides(pkg *ParsedPackage, overrides map[string]string) error {
+	if len(overrides) == 0 {
+		return nil
+	}
+
+	// Validate overrides in a stable order so errors are deterministic
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var p Parser
+	resolved := make(map[string]ParsedType, len(overrides))
+	for _, name := range names {
+		underlying := p.identToType(overrides[name])
+		if underlying.Kind != KindPrimitive && underlying.Kind != KindString {
+			return fmt.Errorf("type override %s: %q is not a builtin numeric, bool or string type", name, overrides[name])
+		}
+		underlying.Named = name
+		resolved[name] = underlying
+	}
+
+	overrideAll := func(types ...*ParsedType) {
+		for _, pt := range types {
+			*pt = overrideType(*pt, resolved)
+		}
+	}
+
+	for i := range pkg.Functions {
+		fn := &pkg.Functions[i]
+		for j := range fn.Params {
+			overrideAll(&fn.Params[j].Type)
+		}
+		for j := range fn.Results {
+			overrideResult(&fn.Results[j], resolved)
+		}
+	}
+
+	for i := range pkg.Structs {
+		st := &pkg.Structs[i]
+		for j := range st.Fields {
+			field := &st.Fields[j]
+			field.Type = overrideType(field.Type, resolved)
+			field.Ownership = FieldOwnership(field.Type)
+		}
+		for j := range st.Methods {
+			method := &st.Methods[j]
+			for k := range method.Params {
+				overrideAll(&method.Params[k].Type)
+			}
+			for k := range method.Results {
+				overrideResult(&method.Results[k], resolved)
+			}
+		}
+	}
+
+	return nil
+}
+
+// overrideResult overrides the type of a result, which is no longer a
+// handle when bound as a builtin type
+func overrideResult(result *ParsedResult, resolved map[string]ParsedType) {
+	result.Type = overrideType(result.Type, resolved)
+	if !result.Type.IsHandle() {
+		result.Ownership = OwnershipNone
+	}
+}
+
+// overrideType replaces overridden named types within pt, rebuilding the
+// names of composite types that contain them
+func overrideType(pt ParsedType, resolved map[string]ParsedType) ParsedType {
+	if pt.Kind == KindStruct && pt.PackagePath == "" {
+		if underlying, ok := resolved[pt.Name]; ok {
+			return underlying
+		}
+		return pt
+	}
+
+	if pt.KeyType != nil {
+		key := overrideType(*pt.KeyType, resolved)
+		pt.KeyType = &key
+	}
+	if pt.ElemType == nil {
+		return pt
+	}
+	elem := overrideType(*pt.ElemType, resolved)
+	pt.ElemType = &elem
+
+	switch pt.Kind {
+	case KindPointer:
+		pt.Name = "*" + elem.Name
+	case KindSlice:
+		pt.Name = "[]" + elem.Name
+	case KindArray:
+		pt.Name = fmt.Sprintf("[%d]%s", pt.Size, elem.Name)
+	case KindMap:
+		pt.Name = fmt.Sprintf("map[%s]%s", pt.KeyType.Name, elem.Name)
+	}
+	return pt
+}
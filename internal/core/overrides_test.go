@@ -0,0 +1,73 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ApplyTypeOverrides", func() {
+	named := func(name string) ParsedType {
+		return ParsedType{Kind: KindStruct, Name: name}
+	}
+
+	It("rewrites named types in functions, fields and methods", func() {
+		elem := named("Celsius")
+		pkg := &ParsedPackage{
+			Functions: []ParsedFunc{{
+				Name:    "Convert",
+				Params:  []ParsedParam{{Name: "c", Type: named("Celsius")}},
+				Results: []ParsedResult{{Type: ParsedType{Kind: KindSlice, Name: "[]Celsius", ElemType: &elem}}},
+			}},
+			Structs: []ParsedStruct{{
+				Name:    "Sensor",
+				Fields:  []ParsedField{{Name: "Label", Type: named("Label"), Exported: true, Ownership: OwnershipChild}},
+				Methods: []ParsedMethod{{Name: "Read", Results: []ParsedResult{{Type: named("Celsius"), Ownership: OwnershipOwned}}}},
+			}},
+		}
+
+		Expect(ApplyTypeOverrides(pkg, map[string]string{"Celsius": "float64", "Label": "string"})).To(Succeed())
+
+		param := pkg.Functions[0].Params[0].Type
+		Expect(param.Kind).To(Equal(KindPrimitive))
+		Expect(param.Name).To(Equal("float64"))
+		Expect(param.Named).To(Equal("Celsius"))
+
+		result := pkg.Functions[0].Results[0].Type
+		Expect(result.Name).To(Equal("[]float64"))
+		Expect(result.ElemType.Named).To(Equal("Celsius"))
+		Expect(elem.Kind).To(Equal(KindStruct), "the original element type is not modified")
+
+		field := pkg.Structs[0].Fields[0].Type
+		Expect(field.Kind).To(Equal(KindString))
+		Expect(field.Named).To(Equal("Label"))
+		Expect(pkg.Structs[0].Fields[0].Ownership).To(Equal(OwnershipNone), "builtin values are not handles")
+
+		Expect(pkg.Structs[0].Methods[0].Results[0].Type.Name).To(Equal("float64"))
+		Expect(pkg.Structs[0].Methods[0].Results[0].Ownership).To(Equal(OwnershipNone))
+	})
+
+	It("leaves other struct types alone", func() {
+		pkg := &ParsedPackage{Functions: []ParsedFunc{{Params: []ParsedParam{{Type: named("Point")}}}}}
+		Expect(ApplyTypeOverrides(pkg, map[string]string{"Celsius": "float64"})).To(Succeed())
+		Expect(pkg.Functions[0].Params[0].Type).To(Equal(named("Point")))
+	})
+
+	It("rejects non-builtin targets", func() {
+		err := ApplyTypeOverrides(&ParsedPackage{}, map[string]string{"Celsius": "Temperature"})
+		Expect(err).To(MatchError(ContainSubstring("type override Celsius")))
+	})
+})
@@ -0,0 +1,178 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// Ownership says who releases the handle of a struct value returned across
+// the C ABI
+type Ownership string
+
+const (
+	// OwnershipNone marks values that are not handles
+	OwnershipNone Ownership = ""
+	// OwnershipOwned is a new handle the caller releases
+	OwnershipOwned Ownership = "owned"
+	// OwnershipBorrowed is the receiver's own handle, returned by methods
+	// that return their receiver; the caller does not release it
+	OwnershipBorrowed Ownership = "borrowed"
+	// OwnershipChild is a new handle to a value stored inside its parent.
+	// The caller releases it and keeps the parent alive while it lives.
+	OwnershipChild Ownership = "child"
+)
+
+// IsHandle reports whether values of the type cross the C ABI as handles:
+// structs and pointers to structs
+func (t ParsedType) IsHandle() bool {
+	if t.Kind == KindPointer {
+		return t.ElemType != nil && t.ElemType.Kind == KindStruct
+	}
+	return t.Kind == KindStruct
+}
+
+// ResultOwnership returns the ownership of a result of type t
+func ResultOwnership(t ParsedType) Ownership {
+	if t.IsHandle() {
+		return OwnershipOwned
+	}
+	return OwnershipNone
+}
+
+// FieldOwnership returns the ownership of a field value read through its
+// getter: struct fields live inside their parent, pointed-to structs do not
+func FieldOwnership(t ParsedType) Ownership {
+	if !t.IsHandle() {
+		return OwnershipNone
+	}
+	if t.Kind == KindStruct {
+		return OwnershipChild
+	}
+	return OwnershipOwned
+}
+
+// returnsReceiver reports whether every return statement of a method body
+// returns the receiver named recv, which the body never reassigns
+func returnsReceiver(body *ast.BlockStmt, recv string) bool {
+	if body == nil || recv == "" || recv == "_" {
+		return false
+	}
+	returns, ok := 0, true
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			// Returns of closures are not returns of the method
+			return false
+		case *ast.ReturnStmt:
+			returns++
+			if len(n.Results) != 1 || !isIdent(n.Results[0], recv) {
+				ok = false
+			}
+		case *ast.AssignStmt:
+			for _, lhs := range n.Lhs {
+				if isIdent(lhs, recv) {
+					ok = false
+				}
+			}
+		}
+		return ok
+	})
+	return ok && returns > 0
+}
+
+// isIdent reports whether expr is the identifier name
+func isIdent(expr ast.Expr, name string) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == name
+}
+
+// markBorrowed marks the slice parameters that body only reads during the
+// call, so hosts may lend their own memory for them
+func markBorrowed(params []ParsedParam, body *ast.BlockStmt) {
+	for i := range params {
+		if params[i].Type.Kind == KindSlice {
+			params[i].Borrowed = readsOnly(body, params[i].Name)
+		}
+	}
+}
+
+// readsOnly reports whether every use of the slice named param in body reads
+// its length or elements: len, cap, range and indexing that is not assigned
+// to or addressed. Any other use may retain the slice beyond the call.
+func readsOnly(body *ast.BlockStmt, param string) bool {
+	if body == nil {
+		return false
+	}
+	if param == "" || param == "_" {
+		return true
+	}
+	ok := true
+	var stack []ast.Node
+	ast.Inspect(body, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		if !ok {
+			return false
+		}
+		if ident, isID := n.(*ast.Ident); isID && ident.Name == param && !isRead(ident, stack) {
+			ok = false
+			return false
+		}
+		stack = append(stack, n)
+		return true
+	})
+	return ok
+}
+
+// isRead reports whether ident, nested in the nodes of stack, only reads
+// the length or an element of the slice it names
+func isRead(ident *ast.Ident, stack []ast.Node) bool {
+	for _, n := range stack {
+		switch n.(type) {
+		case *ast.FuncLit, *ast.GoStmt:
+			// Closures and goroutines may outlive the call
+			return false
+		}
+	}
+	switch parent := stack[len(stack)-1].(type) {
+	case *ast.CallExpr:
+		fun, ok := parent.Fun.(*ast.Ident)
+		return ok && (fun.Name == "len" || fun.Name == "cap") && len(parent.Args) == 1
+	case *ast.RangeStmt:
+		return parent.X == ident
+	case *ast.IndexExpr:
+		if parent.X != ident {
+			return false
+		}
+		switch grand := stack[len(stack)-2].(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range grand.Lhs {
+				if lhs == parent {
+					return false
+				}
+			}
+		case *ast.IncDecStmt:
+			return grand.X != parent
+		case *ast.UnaryExpr:
+			return grand.Op != token.AND
+		}
+		return true
+	}
+	return false
+}
@@ -20,6 +20,7 @@ import (
 	"go/parser"
 	"go/token"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"unicode"
@@ -29,6 +30,8 @@ import (
 type Parser struct {
 	fset    *token.FileSet
 	verbose bool
+	imports map[string]string // Local package name to import path for the file being parsed
+	diags   []Diagnostic      // Exported declarations dropped from the package being parsed
 }
 
 // NewParser creates a new Parser instance
@@ -74,12 +77,21 @@ func (p *Parser) ParsePackage(dirPath string) (*ParsedPackage, error) {
 		Name: pkgName,
 		Dir:  absPath,
 	}
+	p.diags = nil
 
 	// Collect all methods first to associate with structs later
 	methodsByReceiver := make(map[string][]ParsedMethod)
 
-	// Parse all files in the package
-	for _, file := range pkg.Files {
+	// Parse all files in the package, in file name order so the output does
+	// not depend on map iteration
+	fileNames := make([]string, 0, len(pkg.Files))
+	for name := range pkg.Files {
+		fileNames = append(fileNames, name)
+	}
+	sort.Strings(fileNames)
+	for _, fileName := range fileNames {
+		file := pkg.Files[fileName]
+		p.imports = fileImports(file)
 		for _, decl := range file.Decls {
 			switch d := decl.(type) {
 			case *ast.FuncDecl:
@@ -87,9 +99,7 @@ func (p *Parser) ParsePackage(dirPath string) (*ParsedPackage, error) {
 					// This is a method
 					method, receiverType, err := p.parseMethod(d)
 					if err != nil {
-						if p.verbose {
-							fmt.Printf("Skipping method %s: %v\n", d.Name.Name, err)
-						}
+						p.skip(d.Name.Pos(), receiverType+"."+d.Name.Name, DeclMethod, err)
 						continue
 					}
 					if method != nil {
@@ -99,9 +109,7 @@ func (p *Parser) ParsePackage(dirPath string) (*ParsedPackage, error) {
 					// This is a function
 					fn, err := p.parseFunc(d)
 					if err != nil {
-						if p.verbose {
-							fmt.Printf("Skipping function %s: %v\n", d.Name.Name, err)
-						}
+						p.skip(d.Name.Pos(), d.Name.Name, DeclFunction, err)
 						continue
 					}
 					if fn != nil {
@@ -121,9 +129,7 @@ func (p *Parser) ParsePackage(dirPath string) (*ParsedPackage, error) {
 						}
 						parsedStruct, err := p.parseStruct(ts, st, d.Doc)
 						if err != nil {
-							if p.verbose {
-								fmt.Printf("Skipping struct %s: %v\n", ts.Name.Name, err)
-							}
+							p.skip(ts.Name.Pos(), ts.Name.Name, DeclStruct, err)
 							continue
 						}
 						if parsedStruct != nil {
@@ -140,12 +146,32 @@ func (p *Parser) ParsePackage(dirPath string) (*ParsedPackage, error) {
 		structName := parsed.Structs[i].Name
 		if methods, ok := methodsByReceiver[structName]; ok {
 			parsed.Structs[i].Methods = methods
+			delete(methodsByReceiver, structName)
+		}
+	}
+
+	// Methods left over belong to types that are not bound
+	receivers := make([]string, 0, len(methodsByReceiver))
+	for receiverType := range methodsByReceiver {
+		receivers = append(receivers, receiverType)
+	}
+	sort.Strings(receivers)
+	for _, receiverType := range receivers {
+		for _, method := range methodsByReceiver[receiverType] {
+			p.diags = append(p.diags, Skipped(method.Pos, receiverType+"."+method.Name, DeclMethod,
+				fmt.Errorf("receiver type %s is not a struct", receiverType)))
 		}
 	}
 
+	parsed.Diagnostics = p.diags
 	return parsed, nil
 }
 
+// skip records a diagnostic for an exported declaration the package drops
+func (p *Parser) skip(pos token.Pos, symbol string, kind DeclKind, err error) {
+	p.diags = append(p.diags, Skipped(p.fset.Position(pos), symbol, kind, err))
+}
+
 // parseFunc extracts function information from ast.FuncDecl
 func (p *Parser) parseFunc(fn *ast.FuncDecl) (*ParsedFunc, error) {
 	// Skip unexported functions
@@ -155,6 +181,7 @@ func (p *Parser) parseFunc(fn *ast.FuncDecl) (*ParsedFunc, error) {
 
 	parsed := &ParsedFunc{
 		Name: fn.Name.Name,
+		Pos:  p.fset.Position(fn.Name.Pos()),
 	}
 
 	if fn.Doc != nil {
@@ -188,21 +215,24 @@ func (p *Parser) parseFunc(fn *ast.FuncDecl) (*ParsedFunc, error) {
 		}
 	}
 
+	markBorrowed(parsed.Params, fn.Body)
+
 	// Parse results
 	if fn.Type.Results != nil {
 		for _, field := range fn.Type.Results.List {
-			pt, err := p.parseType(field.Type)
+			pt, err := p.parseResultType(field.Type)
 			if err != nil {
 				return nil, err
 			}
 
 			if len(field.Names) == 0 {
-				parsed.Results = append(parsed.Results, ParsedResult{Type: pt})
+				parsed.Results = append(parsed.Results, ParsedResult{Type: pt, Ownership: ResultOwnership(pt)})
 			} else {
 				for _, name := range field.Names {
 					parsed.Results = append(parsed.Results, ParsedResult{
-						Name: name.Name,
-						Type: pt,
+						Name:      name.Name,
+						Type:      pt,
+						Ownership: ResultOwnership(pt),
 					})
 				}
 			}
@@ -250,6 +280,7 @@ func (p *Parser) parseMethod(fn *ast.FuncDecl) (*ParsedMethod, string, error) {
 
 	parsed := &ParsedMethod{
 		Name:          fn.Name.Name,
+		Pos:           p.fset.Position(fn.Name.Pos()),
 		ReceiverName:  receiverName,
 		ReceiverType:  receiverType,
 		ReceiverIsPtr: receiverIsPtr,
@@ -264,7 +295,7 @@ func (p *Parser) parseMethod(fn *ast.FuncDecl) (*ParsedMethod, string, error) {
 		for _, field := range fn.Type.Params.List {
 			pt, err := p.parseType(field.Type)
 			if err != nil {
-				return nil, "", err
+				return nil, receiverType, err
 			}
 
 			if _, isEllipsis := field.Type.(*ast.Ellipsis); isEllipsis {
@@ -284,27 +315,39 @@ func (p *Parser) parseMethod(fn *ast.FuncDecl) (*ParsedMethod, string, error) {
 		}
 	}
 
+	markBorrowed(parsed.Params, fn.Body)
+
 	// Parse results
 	if fn.Type.Results != nil {
 		for _, field := range fn.Type.Results.List {
-			pt, err := p.parseType(field.Type)
+			pt, err := p.parseResultType(field.Type)
 			if err != nil {
-				return nil, "", err
+				return nil, receiverType, err
 			}
 
 			if len(field.Names) == 0 {
-				parsed.Results = append(parsed.Results, ParsedResult{Type: pt})
+				parsed.Results = append(parsed.Results, ParsedResult{Type: pt, Ownership: ResultOwnership(pt)})
 			} else {
 				for _, name := range field.Names {
 					parsed.Results = append(parsed.Results, ParsedResult{
-						Name: name.Name,
-						Type: pt,
+						Name:      name.Name,
+						Type:      pt,
+						Ownership: ResultOwnership(pt),
 					})
 				}
 			}
 		}
 	}
 
+	// A method returning its receiver hands back the caller's own handle
+	if receiverIsPtr && len(parsed.Results) == 1 {
+		result := &parsed.Results[0]
+		if result.Type.Kind == KindPointer && result.Type.ElemType.Name == receiverType && result.Type.ElemType.PackagePath == "" &&
+			returnsReceiver(fn.Body, receiverName) {
+			result.Ownership = OwnershipBorrowed
+		}
+	}
+
 	return parsed, receiverType, nil
 }
 
@@ -317,6 +360,7 @@ func (p *Parser) parseStruct(ts *ast.TypeSpec, st *ast.StructType, doc *ast.Comm
 
 	parsed := &ParsedStruct{
 		Name: ts.Name.Name,
+		Pos:  p.fset.Position(ts.Name.Pos()),
 	}
 
 	if doc != nil {
@@ -331,8 +375,10 @@ func (p *Parser) parseStruct(ts *ast.TypeSpec, st *ast.StructType, doc *ast.Comm
 			pt, err := p.parseType(field.Type)
 			if err != nil {
 				// Skip fields with unsupported types
-				if p.verbose {
-					fmt.Printf("Skipping field in %s: %v\n", ts.Name.Name, err)
+				for _, name := range field.Names {
+					if isExported(name.Name) {
+						p.skip(name.Pos(), ts.Name.Name+"."+name.Name, DeclField, err)
+					}
 				}
 				continue
 			}
@@ -345,18 +391,22 @@ func (p *Parser) parseStruct(ts *ast.TypeSpec, st *ast.StructType, doc *ast.Comm
 			if len(field.Names) == 0 {
 				// Embedded field
 				parsed.Fields = append(parsed.Fields, ParsedField{
-					Name:     pt.Name,
-					Type:     pt,
-					Tag:      tag,
-					Exported: isExported(pt.Name),
+					Name:      pt.Name,
+					Pos:       p.fset.Position(field.Type.Pos()),
+					Type:      pt,
+					Tag:       tag,
+					Exported:  isExported(pt.Name),
+					Ownership: FieldOwnership(pt),
 				})
 			} else {
 				for _, name := range field.Names {
 					parsed.Fields = append(parsed.Fields, ParsedField{
-						Name:     name.Name,
-						Type:     pt,
-						Tag:      tag,
-						Exported: isExported(name.Name),
+						Name:      name.Name,
+						Pos:       p.fset.Position(name.Pos()),
+						Type:      pt,
+						Tag:       tag,
+						Exported:  isExported(name.Name),
+						Ownership: FieldOwnership(pt),
 					})
 				}
 			}
@@ -444,10 +494,14 @@ func (p *Parser) parseType(expr ast.Expr) (ParsedType, error) {
 	case *ast.SelectorExpr:
 		// Imported type like pkg.Type
 		if ident, ok := t.X.(*ast.Ident); ok {
+			pkgPath := ident.Name
+			if importPath, ok := p.imports[ident.Name]; ok {
+				pkgPath = importPath
+			}
 			return ParsedType{
 				Kind:        KindStruct, // Assume struct for imported types
 				Name:        t.Sel.Name,
-				PackagePath: ident.Name,
+				PackagePath: pkgPath,
 			}, nil
 		}
 		return ParsedType{}, &UnsupportedTypeError{
@@ -461,6 +515,18 @@ func (p *Parser) parseType(expr ast.Expr) (ParsedType, error) {
 			Reason: "function types cannot be exposed via CGO",
 		}
 
+	case *ast.IndexExpr, *ast.IndexListExpr:
+		if _, ok := p.iterKind(expr); ok {
+			return ParsedType{}, &UnsupportedTypeError{
+				Type:   "iterator",
+				Reason: "iterators are only supported as results",
+			}
+		}
+		return ParsedType{}, &UnsupportedTypeError{
+			Type:   "generic",
+			Reason: "generic type instantiations cannot be exposed via CGO",
+		}
+
 	case *ast.Ellipsis:
 		// Variadic parameter - parse the element type
 		elem, err := p.parseType(t.Elt)
@@ -481,6 +547,68 @@ func (p *Parser) parseType(expr ast.Expr) (ParsedType, error) {
 	}
 }
 
+// parseResultType converts the type of a result, which may also be an
+// iterator: iter.Seq[V] or iter.Seq2[K, V]
+func (p *Parser) parseResultType(expr ast.Expr) (ParsedType, error) {
+	name, ok := p.iterKind(expr)
+	if !ok {
+		return p.parseType(expr)
+	}
+
+	var args []ast.Expr
+	switch t := expr.(type) {
+	case *ast.IndexExpr:
+		args = []ast.Expr{t.Index}
+	case *ast.IndexListExpr:
+		args = t.Indices
+	}
+	if want := map[string]int{"Seq": 1, "Seq2": 2}[name]; len(args) != want {
+		return ParsedType{}, &UnsupportedTypeError{Type: "iter." + name, Reason: "wrong number of type arguments"}
+	}
+
+	elems := make([]ParsedType, len(args))
+	names := make([]string, len(args))
+	for i, arg := range args {
+		elem, err := p.parseType(arg)
+		if err != nil {
+			return ParsedType{}, err
+		}
+		elems[i], names[i] = elem, elem.Name
+	}
+	it := ParsedType{
+		Kind:     KindIter,
+		Name:     fmt.Sprintf("iter.%s[%s]", name, strings.Join(names, ", ")),
+		ElemType: &elems[len(elems)-1],
+	}
+	if len(elems) == 2 {
+		it.KeyType = &elems[0]
+	}
+	return it, nil
+}
+
+// iterKind returns "Seq" or "Seq2" when expr instantiates that iterator
+// type of the iter package
+func (p *Parser) iterKind(expr ast.Expr) (string, bool) {
+	var generic ast.Expr
+	switch t := expr.(type) {
+	case *ast.IndexExpr:
+		generic = t.X
+	case *ast.IndexListExpr:
+		generic = t.X
+	default:
+		return "", false
+	}
+	sel, ok := generic.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	if !ok || p.imports[ident.Name] != "iter" || (sel.Sel.Name != "Seq" && sel.Sel.Name != "Seq2") {
+		return "", false
+	}
+	return sel.Sel.Name, true
+}
+
 // identToType converts a type name to ParsedType
 func (p *Parser) identToType(name string) ParsedType {
 	switch name {
@@ -515,3 +643,33 @@ func isExported(name string) bool {
 	}
 	return unicode.IsUpper(rune(name[0]))
 }
+
+// fileImports maps the local names of a file's imports to their import paths
+func fileImports(file *ast.File) map[string]string {
+	imports := make(map[string]string, len(file.Imports))
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := importName(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = importPath
+	}
+	return imports
+}
+
+// importName guesses the package name of an import path from its last
+// element, skipping major version suffixes (e.g., "example.com/mod/v2",
+// "gopkg.in/yaml.v3")
+func importName(importPath string) string {
+	elems := strings.Split(importPath, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = elems[len(elems)-2]
+	}
+	name, _, _ = strings.Cut(name, ".")
+	return strings.TrimPrefix(name, "go-")
+}
@@ -17,6 +17,7 @@ package core
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -51,6 +52,7 @@ var _ = Describe("TypeKind constants", func() {
 		Expect(KindFunc).To(Equal(TypeKind(8)))
 		Expect(KindChan).To(Equal(TypeKind(9)))
 		Expect(KindError).To(Equal(TypeKind(10)))
+		Expect(KindIter).To(Equal(TypeKind(11)))
 	})
 })
 
@@ -139,6 +141,22 @@ var _ = Describe("Parser", func() {
 			}
 		})
 
+		It("parses files in file name order", func() {
+			tmpDir := GinkgoT().TempDir()
+			for _, name := range []string{"d", "b", "e", "a", "c"} {
+				src := "package multi\n\nfunc " + strings.ToUpper(name) + "() {}\n"
+				Expect(os.WriteFile(filepath.Join(tmpDir, name+".go"), []byte(src), 0644)).To(Succeed())
+			}
+
+			pkg, err := parser.ParsePackage(tmpDir)
+			Expect(err).NotTo(HaveOccurred())
+			var names []string
+			for _, fn := range pkg.Functions {
+				names = append(names, fn.Name)
+			}
+			Expect(names).To(Equal([]string{"A", "B", "C", "D", "E"}))
+		})
+
 		It("returns error for non-existent directory", func() {
 			_, err := parser.ParsePackage("/nonexistent/path")
 			Expect(err).To(HaveOccurred())
@@ -153,6 +171,41 @@ var _ = Describe("Parser", func() {
 		})
 	})
 
+	Describe("imported types", func() {
+		It("resolves qualified types to import paths", func() {
+			tmpDir, err := os.MkdirTemp("", "imports")
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { _ = os.RemoveAll(tmpDir) }()
+
+			src := `package shapes
+
+import (
+	"example.com/geo"
+	g2 "example.com/geo/v2"
+	"gopkg.in/yaml.v3"
+)
+
+func Move(p *geo.Point, q g2.Point, n yaml.Node) {}
+`
+			Expect(os.WriteFile(filepath.Join(tmpDir, "shapes.go"), []byte(src), 0644)).To(Succeed())
+
+			pkg, err := parser.ParsePackage(tmpDir)
+			Expect(err).NotTo(HaveOccurred())
+			params := pkg.Functions[0].Params
+			Expect(params[0].Type.ElemType.PackagePath).To(Equal("example.com/geo"))
+			Expect(params[1].Type.PackagePath).To(Equal("example.com/geo/v2"))
+			Expect(params[2].Type.PackagePath).To(Equal("gopkg.in/yaml.v3"))
+		})
+
+		It("guesses package names from import paths", func() {
+			Expect(importName("example.com/geo")).To(Equal("geo"))
+			Expect(importName("example.com/geo/v2")).To(Equal("geo"))
+			Expect(importName("github.com/mattn/go-sqlite3")).To(Equal("sqlite3"))
+			Expect(importName("gopkg.in/yaml.v3")).To(Equal("yaml"))
+			Expect(importName("fmt")).To(Equal("fmt"))
+		})
+	})
+
 	Describe("identToType", func() {
 		It("maps primitive types", func() {
 			primitives := []string{"int", "int8", "int16", "int32", "int64",
@@ -313,6 +366,240 @@ var _ = Describe("Parser", func() {
 		})
 	})
 
+	Describe("diagnostics", func() {
+		It("reports exported declarations it cannot represent with their position", func() {
+			tmpDir := GinkgoT().TempDir()
+			src := `package shapes
+
+type Level int
+
+func (l Level) String() string { return "" }
+
+func Watch(c chan int) {}
+
+func hidden(c chan int) {}
+
+type Box struct {
+	Size   int
+	Notify chan int
+	done   chan int
+}
+
+func (b *Box) Run(f func()) {}
+`
+			Expect(os.WriteFile(filepath.Join(tmpDir, "shapes.go"), []byte(src), 0644)).To(Succeed())
+
+			pkg, err := parser.ParsePackage(tmpDir)
+			Expect(err).NotTo(HaveOccurred())
+			var messages []string
+			for _, diag := range pkg.Diagnostics {
+				messages = append(messages, strings.TrimPrefix(diag.String(), tmpDir+string(filepath.Separator)))
+			}
+			Expect(messages).To(ConsistOf(
+				"shapes.go:7:6: warning: function Watch skipped: unsupported type chan: channels cannot be exposed via CGO",
+				"shapes.go:13:2: warning: field Box.Notify skipped: unsupported type chan: channels cannot be exposed via CGO",
+				"shapes.go:17:15: warning: method Box.Run skipped: unsupported type func: function types cannot be exposed via CGO",
+				"shapes.go:5:16: warning: method Level.String skipped: receiver type Level is not a struct",
+			))
+			Expect(pkg.Decls()).To(Equal([]Decl{
+				{Symbol: "Box", Kind: DeclStruct},
+				{Symbol: "Box.Notify", Kind: DeclField},
+				{Symbol: "Box.Run", Kind: DeclMethod},
+				{Symbol: "Box.Size", Kind: DeclField},
+				{Symbol: "Level.String", Kind: DeclMethod},
+				{Symbol: "Watch", Kind: DeclFunction},
+			}))
+		})
+	})
+
+	Describe("iterators", func() {
+		It("parses iter.Seq and iter.Seq2 results and rejects them elsewhere", func() {
+			tmpDir := GinkgoT().TempDir()
+			src := `package shapes
+
+import "iter"
+
+type Box struct{}
+
+func Sizes() iter.Seq[int] { return nil }
+
+func (b *Box) Labels() (iter.Seq2[int, string], error) { return nil, nil }
+
+func Sum(s iter.Seq[int]) int { return 0 }
+`
+			Expect(os.WriteFile(filepath.Join(tmpDir, "shapes.go"), []byte(src), 0644)).To(Succeed())
+
+			pkg, err := parser.ParsePackage(tmpDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pkg.Functions).To(HaveLen(1))
+			sizes := pkg.Functions[0].Results[0].Type
+			Expect(sizes.Kind).To(Equal(KindIter))
+			Expect(sizes.Name).To(Equal("iter.Seq[int]"))
+			Expect(sizes.ElemType.Name).To(Equal("int"))
+			Expect(sizes.KeyType).To(BeNil())
+
+			labels := pkg.Structs[0].Methods[0].Results[0].Type
+			Expect(labels.Name).To(Equal("iter.Seq2[int, string]"))
+			Expect(labels.KeyType.Name).To(Equal("int"))
+			Expect(labels.ElemType.Kind).To(Equal(KindString))
+			Expect(pkg.ReturnsIterators()).To(BeTrue())
+
+			Expect(pkg.Diagnostics).To(HaveLen(1))
+			Expect(pkg.Diagnostics[0].Symbol).To(Equal("Sum"))
+			Expect(pkg.Diagnostics[0].Reason).To(ContainSubstring("iterators are only supported as results"))
+		})
+	})
+
+	Describe("contexts", func() {
+		It("recognizes context.Context parameters", func() {
+			tmpDir := GinkgoT().TempDir()
+			src := `package net
+
+import "context"
+
+type Client struct{}
+
+func (c *Client) Fetch(ctx context.Context, url string) (string, error) { return "", nil }
+
+func Ping() {}
+`
+			Expect(os.WriteFile(filepath.Join(tmpDir, "net.go"), []byte(src), 0644)).To(Succeed())
+
+			pkg, err := parser.ParsePackage(tmpDir)
+			Expect(err).NotTo(HaveOccurred())
+			params := pkg.Structs[0].Methods[0].Params
+			Expect(params[0].Type.IsContext()).To(BeTrue())
+			Expect(params[1].Type.IsContext()).To(BeFalse())
+			Expect(TakesContext(params)).To(BeTrue())
+			Expect(TakesContext(pkg.Functions[0].Params)).To(BeFalse())
+			Expect(pkg.TakesContexts()).To(BeTrue())
+		})
+	})
+
+	Describe("numeric buffers", func() {
+		It("marks slice parameters the body only reads as borrowed", func() {
+			tmpDir := GinkgoT().TempDir()
+			src := `package stats
+
+var kept []float64
+
+type Grid struct{ Cells [][]float32 }
+
+func Sum(xs []float64) float64 {
+	total := 0.0
+	for _, x := range xs {
+		total += x
+	}
+	return total + xs[len(xs)-1]
+}
+
+func Keep(xs []float64) { kept = xs }
+
+func Scale(xs []float64, k float64) {
+	for i := range xs {
+		xs[i] *= k
+	}
+}
+
+func Spawn(xs []float64) { go func() { _ = xs[0] }() }
+
+func Rows(grid [][]float32, size [2]int32, raw []byte) []int { return nil }
+`
+			Expect(os.WriteFile(filepath.Join(tmpDir, "stats.go"), []byte(src), 0644)).To(Succeed())
+
+			pkg, err := parser.ParsePackage(tmpDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			borrowed := make(map[string]bool)
+			for _, fn := range pkg.Functions {
+				borrowed[fn.Name] = fn.Params[0].Borrowed
+			}
+			Expect(borrowed).To(Equal(map[string]bool{
+				"Sum":   true,
+				"Keep":  false,
+				"Scale": false,
+				"Spawn": false,
+				"Rows":  true,
+			}))
+
+			rows := pkg.Functions[4]
+			Expect(rows.Params[0].Type.IsBuffer()).To(BeTrue())
+			Expect(rows.Params[0].Type.IsRows()).To(BeTrue())
+			Expect(rows.Params[0].Type.BufferElem().Name).To(Equal("float32"))
+			Expect(rows.Params[1].Type.IsBuffer()).To(BeTrue())
+			Expect(rows.Params[1].Type.IsRows()).To(BeFalse())
+			Expect(rows.Params[2].Type.IsBuffer()).To(BeTrue())
+			Expect(rows.Params[2].Type.BufferElem().Name).To(Equal("byte"))
+			Expect(rows.Results[0].Type.IsBuffer()).To(BeTrue())
+			Expect(pkg.UsesBuffers()).To(BeTrue())
+		})
+	})
+
+	Describe("ownership", func() {
+		It("records who releases returned handles", func() {
+			tmpDir := GinkgoT().TempDir()
+			src := `package shapes
+
+type Point struct{ X int }
+
+type Line struct {
+	Start Point
+	End   *Point
+	Len   int
+}
+
+func NewPoint() *Point { return &Point{} }
+
+func (p *Point) Scale(k int) *Point {
+	p.X *= k
+	return p
+}
+
+func (p *Point) Clone() *Point {
+	if p == nil {
+		return nil
+	}
+	return &Point{X: p.X}
+}
+
+func (p *Point) Next() *Point {
+	p = &Point{X: p.X + 1}
+	return p
+}
+
+func (p Point) Moved() Point { return p }
+`
+			Expect(os.WriteFile(filepath.Join(tmpDir, "shapes.go"), []byte(src), 0644)).To(Succeed())
+
+			pkg, err := parser.ParsePackage(tmpDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pkg.Functions[0].Results[0].Ownership).To(Equal(OwnershipOwned))
+
+			results := make(map[string]Ownership)
+			fields := make(map[string]Ownership)
+			for _, st := range pkg.Structs {
+				for _, method := range st.Methods {
+					results[method.Name] = method.Results[0].Ownership
+				}
+				for _, field := range st.Fields {
+					fields[st.Name+"."+field.Name] = field.Ownership
+				}
+			}
+			Expect(results).To(Equal(map[string]Ownership{
+				"Scale": OwnershipBorrowed,
+				"Clone": OwnershipOwned,
+				"Next":  OwnershipOwned,
+				"Moved": OwnershipOwned,
+			}))
+			Expect(fields).To(Equal(map[string]Ownership{
+				"Point.X":    OwnershipNone,
+				"Line.Start": OwnershipChild,
+				"Line.End":   OwnershipOwned,
+				"Line.Len":   OwnershipNone,
+			}))
+		})
+	})
+
 	Describe("Verbose parser", func() {
 		It("runs without errors in verbose mode", func() {
 			wd, _ := os.Getwd()
@@ -14,16 +14,63 @@
 
 package core
 
+// Link modes for BuildOptions.Link
+const (
+	// LinkShared builds a shared library (-buildmode=c-shared)
+	LinkShared = "shared"
+	// LinkStatic builds a static archive (-buildmode=c-archive)
+	LinkStatic = "static"
+)
+
 // BuildOptions contains configuration for the Build method
 type BuildOptions struct {
 	// OutputDir is the directory where build artifacts should be placed
 	OutputDir string
 	// LibraryName overrides the default library name (default: lib<package>)
 	LibraryName string
-	// BuildSystem specifies the build system for language-specific packaging (e.g., setuptools, hatch)
-	BuildSystem string
 	// Verbose enables verbose output during build
 	Verbose bool
+	// Link selects a shared library or a static archive (default: LinkShared)
+	Link string
+	// Install also lays out the library under <OutputDir>/install as
+	// include/, lib/, lib/pkgconfig/ and lib/cmake/
+	Install bool
+
+	// Tags are passed to go build as -tags
+	Tags []string
+	// LDFlags are passed to go build as -ldflags
+	LDFlags string
+	// TrimPath removes file system paths from the compiled library
+	TrimPath bool
+	// Race enables the race detector
+	Race bool
+	// BuildVCS sets -buildvcs (true, false or auto); empty leaves the go default
+	BuildVCS string
+	// CC overrides the C compiler used by cgo
+	CC string
+	// CGOCFlags and CGOLDFlags are set as CGO_CFLAGS and CGO_LDFLAGS
+	CGOCFlags  string
+	CGOLDFlags string
+	// Reproducible builds byte-identical libraries: it implies TrimPath,
+	// -buildvcs=false and an empty build ID
+	Reproducible bool
+
+	// Diagnostics receives the diagnostics of the generated code before it
+	// is compiled (optional)
+	Diagnostics DiagnosticHandler `json:"-"`
+
+	// SharedLibrary is the path of a shared library a LibraryBuilder already
+	// built for the same packages. Plugins that wrap the library reuse it
+	// instead of compiling it again (optional).
+	SharedLibrary string
+}
+
+// HandleDiagnostics passes diags to the Diagnostics handler, if any
+func (o *BuildOptions) HandleDiagnostics(diags []Diagnostic) error {
+	if o.Diagnostics == nil {
+		return nil
+	}
+	return o.Diagnostics(diags)
 }
 
 // Plugin is the interface that all language plugins must implement
@@ -38,3 +85,30 @@ type Plugin interface {
 	// The inputPath is the path to the original Go package source
 	Build(pkg *ParsedPackage, inputPath string, opts *BuildOptions) error
 }
+
+// Configurable is implemented by plugins that accept plugin-specific options
+// (set with --opt key=value or the plugin's section of the config file)
+type Configurable interface {
+	// Options declares the options the plugin accepts
+	Options() []OptionSpec
+
+	// Configure validates and applies parsed option values.
+	// It is called before Generate or Build.
+	Configure(opts Options) error
+}
+
+// LibraryBuilder is implemented by plugins whose Build and BuildBundle
+// compile a library that other plugins can reuse through
+// BuildOptions.SharedLibrary
+type LibraryBuilder interface {
+	// LibraryFile returns the path of the library built for pkgs with opts
+	LibraryFile(pkgs []*ParsedPackage, opts *BuildOptions) (string, error)
+}
+
+// Bundler is implemented by plugins that can bind several packages into one
+// shared library, so they share a Go runtime and handle registry
+type Bundler interface {
+	// BuildBundle generates code for all packages and builds a single library.
+	// inputPaths holds the source directory of each package.
+	BuildBundle(pkgs []*ParsedPackage, inputPaths []string, opts *BuildOptions) error
+}
@@ -0,0 +1,78 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import "sort"
+
+// DeclKind classifies the exported declarations of a Go package
+type DeclKind string
+
+const (
+	DeclFunction DeclKind = "function"
+	DeclStruct   DeclKind = "struct"
+	DeclMethod   DeclKind = "method"
+	DeclField    DeclKind = "field"
+)
+
+// Decl identifies an exported declaration
+type Decl struct {
+	Symbol string   `json:"symbol"` // Func, Struct, Struct.Method or Struct.Field
+	Kind   DeclKind `json:"kind"`
+}
+
+// Binding is something a plugin generates for an exported declaration
+type Binding struct {
+	Decl
+	Name      string `json:"name"`      // Generated name, e.g. "simple_Add" or "add"
+	Signature string `json:"signature"` // Signature in the target language
+}
+
+// BindingReport describes how a plugin binds the declarations of a package
+type BindingReport struct {
+	Bindings    []Binding
+	Diagnostics []Diagnostic // Dropped by the plugin, in addition to ParsedPackage.Diagnostics
+}
+
+// BindingReporter is implemented by plugins that can report what they
+// generate for each declaration and why declarations are dropped
+type BindingReporter interface {
+	// ReportBindings generates code for pkg and describes the result
+	ReportBindings(pkg *ParsedPackage) (*BindingReport, error)
+}
+
+// Decls lists the exported declarations of the package, including the ones
+// the parser skipped, sorted by symbol so struct members follow their struct
+func (p *ParsedPackage) Decls() []Decl {
+	var decls []Decl
+	for _, fn := range p.Functions {
+		decls = append(decls, Decl{Symbol: fn.Name, Kind: DeclFunction})
+	}
+	for _, st := range p.Structs {
+		decls = append(decls, Decl{Symbol: st.Name, Kind: DeclStruct})
+		for _, field := range st.Fields {
+			if field.Exported {
+				decls = append(decls, Decl{Symbol: st.Name + "." + field.Name, Kind: DeclField})
+			}
+		}
+		for _, method := range st.Methods {
+			decls = append(decls, Decl{Symbol: st.Name + "." + method.Name, Kind: DeclMethod})
+		}
+	}
+	for _, diag := range p.Diagnostics {
+		decls = append(decls, diag.Decl)
+	}
+	sort.SliceStable(decls, func(i, j int) bool { return decls[i].Symbol < decls[j].Symbol })
+	return decls
+}
@@ -14,6 +14,8 @@
 
 package core
 
+import "go/token"
+
 // TypeKind represents the kind of Go type
 type TypeKind int
 
@@ -29,34 +31,48 @@ const (
 	KindFunc
 	KindChan
 	KindError
+	KindIter // iter.Seq or iter.Seq2 result; KeyType is set for Seq2
 )
 
 // ParsedType represents a Go type with full information
 type ParsedType struct {
 	Kind        TypeKind
 	Name        string      // e.g., "int", "MyStruct"
-	PackagePath string      // For imported types
+	PackagePath string      // Import path of imported types
 	ElemType    *ParsedType // For slices, arrays, pointers, maps (value type)
 	KeyType     *ParsedType // For maps (key type)
 	Size        int         // For arrays
 	IsPointer   bool
+	Named       string // Package-level named type this type was overridden from (e.g., "Celsius")
+}
+
+// DeclaredName returns the type name as written in the Go source, before
+// type overrides
+func (t ParsedType) DeclaredName() string {
+	if t.Named != "" {
+		return t.Named
+	}
+	return t.Name
 }
 
 // ParsedParam represents a function parameter
 type ParsedParam struct {
-	Name string
-	Type ParsedType
+	Name     string
+	Type     ParsedType
+	Borrowed bool `json:",omitempty"` // The body only reads a slice parameter during the call
 }
 
 // ParsedResult represents a function return value
 type ParsedResult struct {
-	Name string // May be empty for unnamed returns
-	Type ParsedType
+	Name      string // May be empty for unnamed returns
+	Type      ParsedType
+	Ownership Ownership `json:",omitempty"` // Who releases a returned handle
 }
 
 // ParsedFunc represents an exported Go function
 type ParsedFunc struct {
 	Name       string
+	Pos        token.Position `json:"-"`
 	Doc        string
 	Params     []ParsedParam
 	Results    []ParsedResult
@@ -65,15 +81,18 @@ type ParsedFunc struct {
 
 // ParsedField represents a struct field
 type ParsedField struct {
-	Name     string
-	Type     ParsedType
-	Tag      string
-	Exported bool
+	Name      string
+	Pos       token.Position `json:"-"`
+	Type      ParsedType
+	Tag       string
+	Exported  bool
+	Ownership Ownership `json:",omitempty"` // Who releases the handle its getter returns
 }
 
 // ParsedMethod represents a method on a struct
 type ParsedMethod struct {
 	Name          string
+	Pos           token.Position `json:"-"`
 	Doc           string
 	ReceiverName  string
 	ReceiverType  string
@@ -86,6 +105,7 @@ type ParsedMethod struct {
 // ParsedStruct represents a Go struct with its methods
 type ParsedStruct struct {
 	Name    string
+	Pos     token.Position `json:"-"`
 	Doc     string
 	Fields  []ParsedField
 	Methods []ParsedMethod
@@ -93,11 +113,66 @@ type ParsedStruct struct {
 
 // ParsedPackage represents a parsed Go package
 type ParsedPackage struct {
-	Name       string
-	ImportPath string
-	Dir        string
-	Functions  []ParsedFunc
-	Structs    []ParsedStruct
+	Name         string
+	ImportPath   string
+	Dir          string
+	ExportPrefix string // Prefix for all C export names (default: package name for functions, none for structs)
+	Functions    []ParsedFunc
+	Structs      []ParsedStruct
+	Diagnostics  []Diagnostic // Exported declarations the parser could not represent
+	Filtered     []Decl       // Exported declarations removed by a SymbolFilter
+}
+
+// Source identifies the package in generated code: its import path, or its
+// directory when the import path is unknown. Generated files stay identical
+// across checkouts when the import path is set.
+func (p *ParsedPackage) Source() string {
+	if p.ImportPath != "" {
+		return p.ImportPath
+	}
+	return p.Dir
+}
+
+// FuncSymbol returns the C export name of a package-level function
+func (p *ParsedPackage) FuncSymbol(fn string) string {
+	if p.ExportPrefix != "" {
+		return p.ExportPrefix + "_" + fn
+	}
+	return p.Name + "_" + fn
+}
+
+// StructPrefix returns the prefix of the C export names generated for a struct
+func (p *ParsedPackage) StructPrefix(st string) string {
+	if p.ExportPrefix != "" {
+		return p.ExportPrefix + "_" + st
+	}
+	return st
+}
+
+// ReturnsIterators reports whether any function or method of the package
+// returns an iterator
+func (p *ParsedPackage) ReturnsIterators() bool {
+	returnsIter := func(results []ParsedResult) bool {
+		for _, result := range results {
+			if result.Type.Kind == KindIter {
+				return true
+			}
+		}
+		return false
+	}
+	for _, fn := range p.Functions {
+		if returnsIter(fn.Results) {
+			return true
+		}
+	}
+	for _, st := range p.Structs {
+		for _, method := range st.Methods {
+			if returnsIter(method.Results) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // UnsupportedTypeError indicates a type that cannot be exported
@@ -0,0 +1,158 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ModuleVersion returns the PEP 440 version of the newest release tag of the Go
+// module containing dir, or "" if there is none. Tags of a module in a
+// subdirectory carry the subdirectory as prefix, as for the go command. A
+// commit after the tag gets a development version of the next patch release.
+func ModuleVersion(dir string) string {
+	moduleDir := dir
+	for {
+		if _, err := os.Stat(filepath.Join(moduleDir, "go.mod")); err == nil {
+			break
+		}
+		parent := filepath.Dir(moduleDir)
+		if parent == moduleDir {
+			return ""
+		}
+		moduleDir = parent
+	}
+
+	out, err := exec.Command("git", "-C", moduleDir, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return ""
+	}
+	prefix, err := filepath.Rel(strings.TrimSpace(string(out)), moduleDir)
+	if err != nil {
+		return ""
+	}
+	if prefix == "." {
+		prefix = ""
+	} else {
+		prefix = filepath.ToSlash(prefix) + "/"
+	}
+
+	out, err = exec.Command("git", "-C", moduleDir, "describe", "--tags", "--long", "--match", prefix+"v[0-9]*").Output()
+	if err != nil {
+		return ""
+	}
+	return describeVersion(strings.TrimPrefix(strings.TrimSpace(string(out)), prefix))
+}
+
+// describeVersion converts the output of git describe --long for a semver
+// tag to a PEP 440 version
+func describeVersion(describe string) string {
+	rest, hash, ok := cutLast(describe, "-")
+	if !ok {
+		return ""
+	}
+	tag, count, ok := cutLast(rest, "-")
+	if !ok {
+		return ""
+	}
+	commits, err := strconv.Atoi(count)
+	if err != nil {
+		return ""
+	}
+
+	version, ok := pep440Version(tag)
+	if !ok || commits == 0 {
+		return version
+	}
+	if strings.ContainsAny(tag, "-+") {
+		return fmt.Sprintf("%s.post%d+%s", version, commits, hash)
+	}
+	major, minor, patch, _ := semverCore(tag)
+	return fmt.Sprintf("%d.%d.%d.dev%d+%s", major, minor, patch+1, commits, hash)
+}
+
+// cutLast slices s around the last instance of sep
+func cutLast(s, sep string) (string, string, bool) {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return s, "", false
+	}
+	return s[:i], s[i+len(sep):], true
+}
+
+// semverCore parses the major, minor and patch numbers of a vX.Y.Z tag
+func semverCore(tag string) (int, int, int, bool) {
+	core := strings.TrimPrefix(tag, "v")
+	if i := strings.IndexAny(core, "-+"); i >= 0 {
+		core = core[:i]
+	}
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return 0, 0, 0, false
+	}
+	var nums [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, 0, 0, false
+		}
+		nums[i] = n
+	}
+	return nums[0], nums[1], nums[2], true
+}
+
+var (
+	localSeparators   = regexp.MustCompile(`[^A-Za-z0-9]+`)
+	preReleasePattern = regexp.MustCompile(`^([A-Za-z]*)[.-]?([0-9]*)`)
+)
+
+// preReleases maps semver pre-release identifiers to PEP 440 segments
+var preReleases = map[string]string{"alpha": "a", "a": "a", "beta": "b", "b": "b", "rc": "rc", "c": "rc"}
+
+// pep440Version converts a semver tag such as v1.2.3-rc.1 to a PEP 440
+// version such as 1.2.3rc1
+func pep440Version(tag string) (string, bool) {
+	major, minor, patch, ok := semverCore(tag)
+	if !ok {
+		return "", false
+	}
+	version := fmt.Sprintf("%d.%d.%d", major, minor, patch)
+
+	rest := strings.TrimPrefix(tag, "v")
+	rest, build, _ := strings.Cut(rest, "+")
+	if _, pre, ok := strings.Cut(rest, "-"); ok {
+		m := preReleasePattern.FindStringSubmatch(pre)
+		number := "0"
+		if m[2] != "" {
+			n, _ := strconv.Atoi(m[2])
+			number = strconv.Itoa(n)
+		}
+		if segment, ok := preReleases[strings.ToLower(m[1])]; ok {
+			version += segment + number
+		} else {
+			version += ".dev" + number
+		}
+	}
+	if build != "" && build != "incompatible" {
+		version += "+" + localSeparators.ReplaceAllString(build, ".")
+	}
+	return version, true
+}
@@ -0,0 +1,86 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Version", func() {
+	Describe("pep440Version", func() {
+		It("converts semver tags", func() {
+			for tag, version := range map[string]string{
+				"v1.2.3":              "1.2.3",
+				"v1.2.3-rc.1":         "1.2.3rc1",
+				"v1.2.3-beta2":        "1.2.3b2",
+				"v1.2.3-alpha":        "1.2.3a0",
+				"v1.2.3-pre.4":        "1.2.3.dev4",
+				"v2.0.0+incompatible": "2.0.0",
+				"v1.0.0+build.5":      "1.0.0+build.5",
+			} {
+				converted, ok := pep440Version(tag)
+				Expect(ok).To(BeTrue(), tag)
+				Expect(converted).To(Equal(version), tag)
+			}
+		})
+
+		It("rejects other tags", func() {
+			_, ok := pep440Version("release-1")
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("describeVersion", func() {
+		It("uses the tag of a tagged commit", func() {
+			Expect(describeVersion("v1.2.3-0-gabc1234")).To(Equal("1.2.3"))
+		})
+
+		It("gives later commits a development version of the next patch", func() {
+			Expect(describeVersion("v1.2.3-4-gabc1234")).To(Equal("1.2.4.dev4+gabc1234"))
+			Expect(describeVersion("v1.2.3-rc.1-4-gabc1234")).To(Equal("1.2.3rc1.post4+gabc1234"))
+		})
+	})
+
+	Describe("ModuleVersion", func() {
+		It("reads the tag of a module in a subdirectory", func() {
+			if _, err := exec.LookPath("git"); err != nil {
+				Skip("git is not installed")
+			}
+			repo := GinkgoT().TempDir()
+			moduleDir := filepath.Join(repo, "geo")
+			Expect(os.MkdirAll(moduleDir, 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(moduleDir, "go.mod"), []byte("module example.com/geo\n"), 0644)).To(Succeed())
+
+			git := func(args ...string) {
+				cmd := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+				out, err := cmd.CombinedOutput()
+				Expect(err).NotTo(HaveOccurred(), string(out))
+			}
+			git("init", "-q")
+			git("add", ".")
+			git("commit", "-q", "-m", "init")
+			Expect(ModuleVersion(moduleDir)).To(BeEmpty())
+
+			git("tag", "v9.0.0")
+			git("tag", "geo/v1.4.0")
+			Expect(ModuleVersion(moduleDir)).To(Equal("1.4.0"))
+		})
+	})
+})
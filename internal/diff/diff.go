@@ -0,0 +1,180 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// context is the number of unchanged lines shown around each change
+const context = 3
+
+// edit is one line of an edit script: kept (' '), deleted ('-') or
+// inserted ('+'). a and b are the line positions in the old and new text.
+type edit struct {
+	op   byte
+	a, b int
+}
+
+// Unified returns the unified diff turning old into new, or "" when they are
+// equal. oldName and newName label the two sides.
+func Unified(oldName, newName string, old, new []byte) string {
+	if string(old) == string(new) {
+		return ""
+	}
+
+	a, b := splitLines(string(old)), splitLines(string(new))
+	edits := editScript(a, b)
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+	for _, hunk := range hunks(edits) {
+		writeHunk(&out, edits[hunk[0]:hunk[1]], a, b)
+	}
+	return out.String()
+}
+
+// splitLines splits text into lines, keeping their line endings
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// editScript computes a shortest edit script with Myers' algorithm
+func editScript(a, b []string) []edit {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Walk the trace back from the end to recover the edits
+	var edits []edit
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x, y = x-1, y-1
+			edits = append(edits, edit{' ', x, y})
+		}
+		if d > 0 {
+			if x == prevX {
+				y--
+				edits = append(edits, edit{'+', x, y})
+			} else {
+				x--
+				edits = append(edits, edit{'-', x, y})
+			}
+		}
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
+
+// hunks returns the [start, end) ranges of edits to print: each change with
+// its surrounding context, merging changes whose context overlaps
+func hunks(edits []edit) [][2]int {
+	var ranges [][2]int
+	for i, e := range edits {
+		if e.op == ' ' {
+			continue
+		}
+		start := max(i-context, 0)
+		end := min(i+context+1, len(edits))
+		if n := len(ranges); n > 0 && start <= ranges[n-1][1] {
+			ranges[n-1][1] = end
+			continue
+		}
+		ranges = append(ranges, [2]int{start, end})
+	}
+	return ranges
+}
+
+// writeHunk writes a hunk header and its lines
+func writeHunk(out *strings.Builder, edits []edit, a, b []string) {
+	var oldCount, newCount int
+	for _, e := range edits {
+		if e.op != '+' {
+			oldCount++
+		}
+		if e.op != '-' {
+			newCount++
+		}
+	}
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(edits[0].a, oldCount), hunkRange(edits[0].b, newCount))
+
+	for _, e := range edits {
+		var line string
+		if e.op == '+' {
+			line = b[e.b]
+		} else {
+			line = a[e.a]
+		}
+		out.WriteByte(e.op)
+		out.WriteString(line)
+		if !strings.HasSuffix(line, "\n") {
+			out.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats the start and length of a hunk side; an empty side
+// starts at the line before it
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
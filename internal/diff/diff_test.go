@@ -0,0 +1,57 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestDiff(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Diff Suite")
+}
+
+var _ = Describe("Unified", func() {
+	It("returns an empty diff for equal inputs", func() {
+		Expect(Unified("a", "b", []byte("x\ny\n"), []byte("x\ny\n"))).To(BeEmpty())
+	})
+
+	It("diffs a new file against /dev/null", func() {
+		Expect(Unified("/dev/null", "b/f", nil, []byte("x\ny\n"))).To(Equal(
+			"--- /dev/null\n+++ b/f\n@@ -0,0 +1,2 @@\n+x\n+y\n"))
+	})
+
+	It("keeps three lines of context around a change", func() {
+		old := []byte("1\n2\n3\n4\n5\n6\n7\n8\n9\n")
+		new := []byte("1\n2\n3\n4\nfive\n6\n7\n8\n9\n")
+		Expect(Unified("a/f", "b/f", old, new)).To(Equal(
+			"--- a/f\n+++ b/f\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n"))
+	})
+
+	It("splits distant changes into separate hunks", func() {
+		old := []byte("a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\n")
+		new := []byte("A\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nL\n")
+		Expect(Unified("a/f", "b/f", old, new)).To(Equal(
+			"--- a/f\n+++ b/f\n@@ -1,4 +1,4 @@\n-a\n+A\n b\n c\n d\n@@ -9,4 +9,4 @@\n i\n j\n k\n-l\n+L\n"))
+	})
+
+	It("marks a missing trailing newline", func() {
+		Expect(Unified("a/f", "b/f", []byte("x\n"), []byte("x"))).To(Equal(
+			"--- a/f\n+++ b/f\n@@ -1 +1 @@\n-x\n+x\n\\ No newline at end of file\n"))
+	})
+})
@@ -0,0 +1,135 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cgo
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/riceriley59/goanywhere/internal/core"
+)
+
+// takesContexts reports whether any of pkgs takes a context.Context, which
+// hosts then pass as handles from Context_New
+func takesContexts(pkgs []*core.ParsedPackage) bool {
+	for _, pkg := range pkgs {
+		if pkg.TakesContexts() {
+			return true
+		}
+	}
+	return false
+}
+
+// MapParam converts the type of a parameter, which may also be a
+// context.Context passed as a handle
+func (m *TypeMapper) MapParam(pt core.ParsedType) (CType, error) {
+	if pt.IsContext() {
+		return CType{CTypeName: "C.uintptr_t", GoTypeName: "context.Context"}, nil
+	}
+	return m.MapType(pt)
+}
+
+// writeContextRuntime writes the exports creating, cancelling and releasing
+// the contexts hosts pass to context.Context parameters
+func (a *Plugin) writeContextRuntime(buf *bytes.Buffer) {
+	handle := []core.ExportParam{{Name: "h", Type: "C.uintptr_t"}}
+	a.exports = append(a.exports,
+		core.Export{Symbol: "Context_New", Kind: core.ExportRuntime, Result: &core.ExportParam{Type: "C.uintptr_t"}},
+		core.Export{Symbol: "Context_Cancel", Kind: core.ExportRuntime, Params: handle},
+		core.Export{Symbol: "Context_Free", Kind: core.ExportRuntime, Params: handle},
+	)
+	buf.WriteString(`// ============ Contexts ============
+
+// hostContext is a context the host cancels through its handle
+type hostContext struct {
+	context.Context
+	cancel context.CancelFunc
+}
+
+// contextOf returns the context of a handle from Context_New, or
+// context.Background for 0
+func contextOf(h C.uintptr_t) context.Context {
+	if raw, ok := getHandle(h); ok {
+		if ctx, ok := raw.(*hostContext); ok {
+			return ctx
+		}
+	}
+	return context.Background()
+}
+
+//export Context_New
+func Context_New() C.uintptr_t {
+	ctx, cancel := context.WithCancel(context.Background())
+	return registerHandle(&hostContext{Context: ctx, cancel: cancel})
+}
+
+//export Context_Cancel
+func Context_Cancel(h C.uintptr_t) {
+	raw, _ := getHandle(h)
+	if ctx, ok := raw.(*hostContext); ok {
+		ctx.cancel()
+	}
+}
+
+//export Context_Free
+func Context_Free(h C.uintptr_t) {
+	raw, _ := getHandle(h)
+	freeHandle(h)
+	// Release the context once its last handle is released
+	if ctx, ok := raw.(*hostContext); ok {
+		if _, live := getHandle(h); !live {
+			ctx.cancel()
+		}
+	}
+}
+`)
+}
+
+// writeAsync writes the export starting the export sync on a goroutine. It
+// stores the result in outResult and calls onDone with callID once the call
+// returns; the host keeps the arguments alive until then.
+func (a *Plugin) writeAsync(buf *bytes.Buffer, sync core.Export) {
+	export := core.Export{
+		Symbol: sync.Symbol + "_Async",
+		Kind:   core.ExportAsync,
+		Source: sync.Source,
+		Params: append([]core.ExportParam(nil), sync.Params...),
+	}
+	args := make([]string, len(sync.Params))
+	for i, param := range sync.Params {
+		args[i] = param.Name
+	}
+	call := fmt.Sprintf("%s(%s)", sync.Symbol, strings.Join(args, ", "))
+	if sync.Result != nil {
+		export.Params = append(export.Params, core.ExportParam{Name: "outResult", Type: "*" + sync.Result.Type, GoType: sync.Result.GoType, Ownership: sync.Result.Ownership})
+		call = "*outResult = " + call
+	}
+	export.Params = append(export.Params,
+		core.ExportParam{Name: "onDone", Type: "C.goanywhere_done"},
+		core.ExportParam{Name: "callID", Type: "C.uintptr_t"},
+	)
+	a.exports = append(a.exports, export)
+
+	params := make([]string, len(export.Params))
+	for i, param := range export.Params {
+		params[i] = param.Name + " " + param.Type
+	}
+	fmt.Fprintf(buf, "\n//export %s\nfunc %s(%s) {\n", export.Symbol, export.Symbol, strings.Join(params, ", "))
+	buf.WriteString("\tgo func() {\n")
+	buf.WriteString("\t\tdefer C.goanywhere_complete(onDone, callID)\n")
+	fmt.Fprintf(buf, "\t\t%s\n", call)
+	buf.WriteString("\t}()\n}\n")
+}
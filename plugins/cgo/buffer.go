@@ -0,0 +1,182 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cgo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/riceriley59/goanywhere/internal/core"
+)
+
+// errBufferResult is the reason functions returning numbers in a buffer
+// alongside other results are skipped
+var errBufferResult = errors.New("slices and arrays of numbers are only supported as the only non-error result")
+
+// usesBuffers reports whether any of pkgs passes numbers in buffers
+func usesBuffers(pkgs []*core.ParsedPackage) bool {
+	for _, pkg := range pkgs {
+		if pkg.UsesBuffers() {
+			return true
+		}
+	}
+	return false
+}
+
+// mapBuffer maps a buffer of numbers to a pointer to its first element
+func (m *TypeMapper) mapBuffer(pt core.ParsedType) (CType, error) {
+	elemType, err := m.MapType(pt.BufferElem())
+	if err != nil {
+		return CType{}, err
+	}
+	return CType{
+		CTypeName:  "*" + elemType.CTypeName,
+		GoTypeName: pt.Name,
+		NeedsAlloc: true,
+		NeedsFree:  true,
+	}, nil
+}
+
+// bufferParams returns the C parameters following the pointer of a buffer
+// parameter: the length of a slice, or the row lengths and count of rows
+func bufferParams(name string, pt core.ParsedType) []core.ExportParam {
+	switch {
+	case pt.IsRows():
+		return []core.ExportParam{
+			{Name: name + "Lens", Type: "*C.longlong", GoType: "[]int"},
+			{Name: name + "Rows", Type: "C.longlong", GoType: "int"},
+		}
+	case pt.Kind == core.KindSlice:
+		return []core.ExportParam{{Name: name + "Len", Type: "C.longlong", GoType: "int"}}
+	}
+	return nil
+}
+
+// bufferOutParams returns the out parameters receiving the length of a
+// buffer result; arrays have a fixed length
+func bufferOutParams(pt core.ParsedType) []core.ExportParam {
+	switch {
+	case pt.IsRows():
+		return []core.ExportParam{
+			{Name: "outLens", Type: "**C.longlong", GoType: "[]int"},
+			{Name: "outRows", Type: "*C.longlong", GoType: "int"},
+		}
+	case pt.Kind == core.KindSlice:
+		return []core.ExportParam{{Name: "outLen", Type: "*C.longlong", GoType: "int"}}
+	}
+	return nil
+}
+
+// paramList formats export parameters as a C parameter list
+func paramList(params []core.ExportParam) []string {
+	out := make([]string, len(params))
+	for i, param := range params {
+		out[i] = param.Name + " " + param.Type
+	}
+	return out
+}
+
+// bufferInput converts a buffer parameter to Go. Slices the function only
+// reads during the call view the caller's memory; others are copied, since
+// Go may keep them after the caller frees it.
+func (a *Plugin) bufferInput(name string, pt core.ParsedType, borrowed bool) (string, string) {
+	elem := a.goTypeExpr(pt.BufferElem())
+	goVar := "go" + capitalize(name)
+	switch {
+	case pt.IsRows():
+		return goVar, fmt.Sprintf("%s := rowsBuffer[%s](unsafe.Pointer(%s), %sLens, %sRows)", goVar, elem, name, name, name)
+	case pt.Kind == core.KindArray:
+		return fmt.Sprintf("*(*[%d]%s)(unsafe.Pointer(%s))", pt.Size, elem, name), ""
+	case borrowed:
+		return goVar, fmt.Sprintf("%s := viewBuffer[%s](unsafe.Pointer(%s), %sLen)", goVar, elem, name, name)
+	}
+	return goVar, fmt.Sprintf("%s := copyBuffer[%s](unsafe.Pointer(%s), %sLen)", goVar, elem, name, name)
+}
+
+// bufferOutput converts a buffer result to a copy in C memory
+func (a *Plugin) bufferOutput(expr string, pt core.ParsedType, ct CType) string {
+	switch {
+	case pt.IsRows():
+		return fmt.Sprintf("(%s)(exportRows(%s, outLens, outRows))", ct.CTypeName, expr)
+	case pt.Kind == core.KindArray:
+		return fmt.Sprintf("(%s)(exportBuffer(%s[:], nil))", ct.CTypeName, expr)
+	}
+	return fmt.Sprintf("(%s)(exportBuffer(%s, outLen))", ct.CTypeName, expr)
+}
+
+// writeBufferRuntime writes the helpers moving numbers between Go slices and
+// C buffers
+func (a *Plugin) writeBufferRuntime(buf *bytes.Buffer) {
+	buf.WriteString(`// ============ Buffers ============
+
+// viewBuffer returns the n elements at data without copying them
+func viewBuffer[T any](data unsafe.Pointer, n C.longlong) []T {
+	if data == nil || n <= 0 {
+		return nil
+	}
+	return unsafe.Slice((*T)(data), int(n))
+}
+
+// copyBuffer returns a Go copy of the n elements at data
+func copyBuffer[T any](data unsafe.Pointer, n C.longlong) []T {
+	return append([]T(nil), viewBuffer[T](data, n)...)
+}
+
+// rowsBuffer returns a Go copy of the rows stored one after another at
+// data, with their lengths at lens
+func rowsBuffer[T any](data unsafe.Pointer, lens *C.longlong, rows C.longlong) [][]T {
+	sizes := viewBuffer[C.longlong](unsafe.Pointer(lens), rows)
+	var total C.longlong
+	for _, n := range sizes {
+		total += n
+	}
+	flat := copyBuffer[T](data, total)
+	out := make([][]T, len(sizes))
+	for i, n := range sizes {
+		out[i], flat = flat[:n:n], flat[n:]
+	}
+	return out
+}
+
+// exportBuffer copies s into C memory released with Free_Bytes, and stores
+// its length in outLen unless it is nil
+func exportBuffer[T any](s []T, outLen *C.longlong) unsafe.Pointer {
+	if outLen != nil {
+		*outLen = C.longlong(len(s))
+	}
+	if len(s) == 0 {
+		return nil
+	}
+	data := C.malloc(C.size_t(len(s)) * C.size_t(unsafe.Sizeof(s[0])))
+	copy(unsafe.Slice((*T)(data), len(s)), s)
+	return data
+}
+
+// exportRows copies rows one after another into C memory, and their lengths
+// into outLens; both are released with Free_Bytes
+func exportRows[T any](rows [][]T, outLens **C.longlong, outRows *C.longlong) unsafe.Pointer {
+	var flat []T
+	lens := make([]C.longlong, len(rows))
+	for i, row := range rows {
+		flat = append(flat, row...)
+		lens[i] = C.longlong(len(row))
+	}
+	*outLens = (*C.longlong)(exportBuffer(lens, outRows))
+	return exportBuffer(flat, nil)
+}
+
+`)
+}
@@ -0,0 +1,199 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cgo
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/riceriley59/goanywhere/internal/core"
+)
+
+// usesIterators reports whether any of pkgs returns an iterator, which the
+// generated code then pulls with the iter package
+func usesIterators(pkgs []*core.ParsedPackage) bool {
+	for _, pkg := range pkgs {
+		if pkg.ReturnsIterators() {
+			return true
+		}
+	}
+	return false
+}
+
+// writeIterRuntime writes the pulled iterator type behind iterator handles
+// and the exports stopping and releasing them
+func (a *Plugin) writeIterRuntime(buf *bytes.Buffer) {
+	a.exports = append(a.exports,
+		core.Export{Symbol: "Iter_Stop", Kind: core.ExportRuntime, Params: []core.ExportParam{{Name: "h", Type: "C.uintptr_t"}}},
+		core.Export{Symbol: "Iter_Free", Kind: core.ExportRuntime, Params: []core.ExportParam{{Name: "h", Type: "C.uintptr_t"}}},
+	)
+	buf.WriteString(`// ============ Iterators ============
+
+// pulled is a Go iterator the host pulls one element at a time. Calls are
+// serialized, since iter.Pull does not allow concurrent use.
+type pulled[E any] struct {
+	mu   sync.Mutex
+	next func() (E, bool)
+	stop func()
+}
+
+// pair is an element of an iter.Seq2
+type pair[K, V any] struct {
+	key   K
+	value V
+}
+
+func pullSeq[V any](seq iter.Seq[V]) *pulled[V] {
+	if seq == nil {
+		seq = func(func(V) bool) {}
+	}
+	next, stop := iter.Pull(seq)
+	return &pulled[V]{next: next, stop: stop}
+}
+
+func pullSeq2[K, V any](seq iter.Seq2[K, V]) *pulled[pair[K, V]] {
+	if seq == nil {
+		seq = func(func(K, V) bool) {}
+	}
+	next, stop := iter.Pull2(seq)
+	return &pulled[pair[K, V]]{
+		next: func() (pair[K, V], bool) {
+			k, v, ok := next()
+			return pair[K, V]{k, v}, ok
+		},
+		stop: stop,
+	}
+}
+
+func (p *pulled[E]) pull() (E, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.next()
+}
+
+func (p *pulled[E]) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stop()
+}
+
+// stopper is implemented by every pulled iterator
+type stopper interface {
+	close()
+}
+
+//export Iter_Stop
+func Iter_Stop(h C.uintptr_t) {
+	raw, _ := getHandle(h)
+	if it, ok := raw.(stopper); ok {
+		it.close()
+	}
+}
+
+//export Iter_Free
+func Iter_Free(h C.uintptr_t) {
+	raw, _ := getHandle(h)
+	freeHandle(h)
+	// Stop the iterator once its last handle is released
+	if it, ok := raw.(stopper); ok {
+		if _, live := getHandle(h); !live {
+			it.close()
+		}
+	}
+}
+`)
+}
+
+// writeIterNext writes the export pulling the next element of the iterators
+// returned by the export symbol, into out parameters for the key of an
+// iter.Seq2 and the value
+func (a *Plugin) writeIterNext(buf *bytes.Buffer, symbol, source string, it core.ParsedType) error {
+	export := core.Export{
+		Symbol: symbol + "_Next",
+		Kind:   core.ExportIterator,
+		Source: source,
+		Params: []core.ExportParam{{Name: "it", Type: "C.uintptr_t", GoType: it.Name}},
+		Result: &core.ExportParam{Type: "C.bool", GoType: "bool"},
+	}
+	elemType := a.goTypeExpr(*it.ElemType)
+	type out struct {
+		name, expr string
+		pt         core.ParsedType
+	}
+	outs := []out{{"outValue", "e", *it.ElemType}}
+	if it.KeyType != nil {
+		elemType = fmt.Sprintf("pair[%s, %s]", a.goTypeExpr(*it.KeyType), elemType)
+		outs = []out{{"outKey", "e.key", *it.KeyType}, {"outValue", "e.value", *it.ElemType}}
+	}
+
+	params := []string{"it C.uintptr_t"}
+	var assigns []string
+	for _, o := range outs {
+		ctype, err := a.mapper.MapType(o.pt)
+		if err != nil {
+			return err
+		}
+		params = append(params, fmt.Sprintf("%s *%s", o.name, ctype.CTypeName))
+		export.Params = append(export.Params, core.ExportParam{Name: o.name, Type: "*" + ctype.CTypeName, GoType: o.pt.DeclaredName(), Ownership: core.ResultOwnership(o.pt)})
+		assigns = append(assigns, fmt.Sprintf("\t*%s = %s\n", o.name, a.generateOutputConversion(o.expr, o.pt, ctype)))
+	}
+	a.exports = append(a.exports, export)
+
+	fmt.Fprintf(buf, "\n//export %s_Next\nfunc %s_Next(%s) C.bool {\n", symbol, symbol, strings.Join(params, ", "))
+	buf.WriteString("\traw, _ := getHandle(it)\n")
+	fmt.Fprintf(buf, "\tp, ok := raw.(*pulled[%s])\n", elemType)
+	buf.WriteString("\tif !ok {\n\t\treturn false\n\t}\n")
+	buf.WriteString("\te, ok := p.pull()\n")
+	buf.WriteString("\tif !ok {\n\t\treturn false\n\t}\n")
+	for _, assign := range assigns {
+		buf.WriteString(assign)
+	}
+	buf.WriteString("\treturn true\n}\n")
+	return nil
+}
+
+// goTypeExpr returns the Go type expression of a type in the generated code
+func (a *Plugin) goTypeExpr(pt core.ParsedType) string {
+	switch pt.Kind {
+	case core.KindStruct:
+		return a.qualify(pt)
+	case core.KindPointer:
+		return "*" + a.goTypeExpr(*pt.ElemType)
+	}
+	if pt.Named != "" {
+		return a.alias + "." + pt.Named
+	}
+	return pt.Name
+}
+
+// iterElementError returns why an iterator element of type pt cannot be
+// pulled through the C ABI, or nil
+func (m *TypeMapper) iterElementError(pt core.ParsedType) error {
+	ctype, err := m.MapType(pt)
+	if err != nil {
+		return err
+	}
+	switch {
+	case pt.Kind == core.KindPrimitive, pt.Kind == core.KindString:
+		return nil
+	case ctype.IsHandle && (pt.Kind == core.KindStruct || pt.Kind == core.KindPointer):
+		return nil
+	}
+	return &core.UnsupportedTypeError{
+		Type:   pt.Name,
+		Reason: "iterator elements must be numbers, bools, strings or structs",
+	}
+}
@@ -33,6 +33,7 @@ type CType struct {
 // TypeMapper handles Go to C type mapping
 type TypeMapper struct {
 	structRegistry map[string]*core.ParsedStruct
+	packages       map[string]bool // Import paths of packages bound in the same library
 }
 
 // NewTypeMapper creates a TypeMapper with known structs
@@ -43,9 +44,16 @@ func NewTypeMapper(structs []core.ParsedStruct) *TypeMapper {
 	}
 	return &TypeMapper{
 		structRegistry: registry,
+		packages:       make(map[string]bool),
 	}
 }
 
+// AddPackage marks a package as bound in the same library, so its struct
+// types can be passed as handles
+func (m *TypeMapper) AddPackage(importPath string) {
+	m.packages[importPath] = true
+}
+
 // MapType converts a ParsedType to CType
 func (m *TypeMapper) MapType(pt core.ParsedType) (CType, error) {
 	switch pt.Kind {
@@ -75,13 +83,18 @@ func (m *TypeMapper) MapType(pt core.ParsedType) (CType, error) {
 		}
 		// Check if it's a pointer to a known struct
 		if pt.ElemType.Kind == core.KindStruct {
-			if _, ok := m.structRegistry[pt.ElemType.Name]; ok {
+			_, local := m.structRegistry[pt.ElemType.Name]
+			if (local && pt.ElemType.PackagePath == "") || m.packages[pt.ElemType.PackagePath] {
 				return CType{
 					CTypeName:  "C.uintptr_t",
 					GoTypeName: "*" + pt.ElemType.Name,
 					IsHandle:   true,
 				}, nil
 			}
+			return CType{}, &core.UnsupportedTypeError{
+				Type:   "*" + pt.ElemType.Name,
+				Reason: "pointers to structs without bindings cannot be exposed via CGO",
+			}
 		}
 		// For other pointer types, try to map the element
 		elemType, err := m.MapType(*pt.ElemType)
@@ -94,6 +107,12 @@ func (m *TypeMapper) MapType(pt core.ParsedType) (CType, error) {
 		}, nil
 
 	case core.KindStruct:
+		if pt.PackagePath != "" && !m.packages[pt.PackagePath] {
+			return CType{}, &core.UnsupportedTypeError{
+				Type:   pt.PackagePath + "." + pt.Name,
+				Reason: "types from packages not bound in the same library cannot be exposed via CGO",
+			}
+		}
 		// Check if it's a known struct in the package
 		if _, ok := m.structRegistry[pt.Name]; ok {
 			return CType{
@@ -113,16 +132,10 @@ func (m *TypeMapper) MapType(pt core.ParsedType) (CType, error) {
 		if pt.ElemType == nil {
 			return CType{}, fmt.Errorf("slice type missing element type")
 		}
-		// Slices are represented as pointer + length
-		// Special case for []byte
-		if pt.ElemType.Kind == core.KindPrimitive && pt.ElemType.Name == "byte" {
-			return CType{
-				CTypeName:  "unsafe.Pointer", // Will generate data + len params
-				GoTypeName: "[]byte",
-				NeedsAlloc: true,
-				NeedsFree:  true,
-			}, nil
+		if pt.IsBuffer() {
+			return m.mapBuffer(pt)
 		}
+		// Slices are represented as pointer + length
 		elemType, err := m.MapType(*pt.ElemType)
 		if err != nil {
 			return CType{}, err
@@ -138,6 +151,9 @@ func (m *TypeMapper) MapType(pt core.ParsedType) (CType, error) {
 		if pt.ElemType == nil {
 			return CType{}, fmt.Errorf("array type missing element type")
 		}
+		if pt.IsBuffer() {
+			return m.mapBuffer(pt)
+		}
 		elemType, err := m.MapType(*pt.ElemType)
 		if err != nil {
 			return CType{}, err
@@ -163,6 +179,22 @@ func (m *TypeMapper) MapType(pt core.ParsedType) (CType, error) {
 			IsHandle:   true,
 		}, nil
 
+	case core.KindIter:
+		// Iterators are pulled through a handle, one element per call
+		for _, elem := range []*core.ParsedType{pt.KeyType, pt.ElemType} {
+			if elem == nil {
+				continue
+			}
+			if err := m.iterElementError(*elem); err != nil {
+				return CType{}, err
+			}
+		}
+		return CType{
+			CTypeName:  "C.uintptr_t",
+			GoTypeName: pt.Name,
+			IsHandle:   true,
+		}, nil
+
 	case core.KindChan:
 		return CType{}, &core.UnsupportedTypeError{
 			Type:   "chan",
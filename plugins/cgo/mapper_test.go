@@ -15,6 +15,7 @@
 package cgo
 
 import (
+	"errors"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -99,6 +100,21 @@ var _ = Describe("TypeMapper", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(ct.IsHandle).To(BeTrue())
 		})
+
+		It("rejects structs from packages outside the library", func() {
+			pt := core.ParsedType{Kind: core.KindStruct, Name: "Time", PackagePath: "time"}
+			_, err := mapper.MapType(pt)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("time.Time"))
+		})
+
+		It("maps structs from bundled packages as handles", func() {
+			mapper.AddPackage("example.com/geo")
+			elem := core.ParsedType{Kind: core.KindStruct, Name: "Point", PackagePath: "example.com/geo"}
+			ct, err := mapper.MapType(core.ParsedType{Kind: core.KindPointer, Name: "*geo.Point", ElemType: &elem})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ct.IsHandle).To(BeTrue())
+		})
 	})
 
 	Describe("MapType pointer", func() {
@@ -110,6 +126,14 @@ var _ = Describe("TypeMapper", func() {
 			Expect(ct.IsHandle).To(BeTrue())
 		})
 
+		It("rejects pointers to structs without bindings", func() {
+			elem := core.ParsedType{Kind: core.KindStruct, Name: "Hidden"}
+			_, err := mapper.MapType(core.ParsedType{Kind: core.KindPointer, Name: "*Hidden", ElemType: &elem})
+			var unsupported *core.UnsupportedTypeError
+			Expect(errors.As(err, &unsupported)).To(BeTrue())
+			Expect(unsupported.Type).To(Equal("*Hidden"))
+		})
+
 		It("returns error for pointer without element type", func() {
 			pt := core.ParsedType{Kind: core.KindPointer, Name: "*int"}
 			_, err := mapper.MapType(pt)
@@ -126,6 +150,18 @@ var _ = Describe("TypeMapper", func() {
 			Expect(ct.GoTypeName).To(Equal("[]byte"))
 		})
 
+		It("maps slices of numbers and their rows to a pointer to the first number", func() {
+			elem := core.ParsedType{Kind: core.KindPrimitive, Name: "float32"}
+			row := core.ParsedType{Kind: core.KindSlice, Name: "[]float32", ElemType: &elem}
+			pt := core.ParsedType{Kind: core.KindSlice, Name: "[][]float32", ElemType: &row}
+			ct, err := mapper.MapType(row)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ct.CTypeName).To(Equal("*C.float"))
+			ct, err = mapper.MapType(pt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ct.CTypeName).To(Equal("*C.float"))
+		})
+
 		It("returns error for slice without element type", func() {
 			pt := core.ParsedType{Kind: core.KindSlice, Name: "[]int"}
 			_, err := mapper.MapType(pt)
@@ -135,11 +171,19 @@ var _ = Describe("TypeMapper", func() {
 
 	Describe("MapType array", func() {
 		It("maps fixed array", func() {
+			elem := core.ParsedType{Kind: core.KindPrimitive, Name: "bool"}
+			pt := core.ParsedType{Kind: core.KindArray, Name: "[5]bool", ElemType: &elem, Size: 5}
+			ct, err := mapper.MapType(pt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ct.CTypeName).To(ContainSubstring("[5]"))
+		})
+
+		It("maps arrays of numbers to a pointer to their first element", func() {
 			elem := core.ParsedType{Kind: core.KindPrimitive, Name: "int"}
 			pt := core.ParsedType{Kind: core.KindArray, Name: "[5]int", ElemType: &elem, Size: 5}
 			ct, err := mapper.MapType(pt)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(ct.CTypeName).To(ContainSubstring("[5]"))
+			Expect(ct.CTypeName).To(Equal("*C.longlong"))
 		})
 
 		It("returns error for array without element type", func() {
@@ -179,5 +223,12 @@ var _ = Describe("TypeMapper", func() {
 			_, err := mapper.MapType(pt)
 			Expect(err).To(HaveOccurred())
 		})
+
+		It("returns error for iterators of unsupported elements", func() {
+			elem := core.ParsedType{Kind: core.KindSlice, Name: "[]int", ElemType: &core.ParsedType{Kind: core.KindPrimitive, Name: "int"}}
+			pt := core.ParsedType{Kind: core.KindIter, Name: "iter.Seq[[]int]", ElemType: &elem}
+			_, err := mapper.MapType(pt)
+			Expect(err).To(MatchError(ContainSubstring("iterator elements must be")))
+		})
 	})
 })
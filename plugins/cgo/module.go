@@ -0,0 +1,564 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cgo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// buildModulePath is the module path of the ephemeral module the generated
+// main package is built in
+const buildModulePath = "goanywhere.build/bindings"
+
+// pseudoVersion is the version required for source modules, which are always
+// replaced by their local directory
+const pseudoVersion = "v0.0.0-00010101000000-000000000000"
+
+// linkDir is the directory of the build module holding symlinks to local
+// module directories. Replacements point through it so absolute source paths
+// stay out of the build info and builds do not depend on the checkout path.
+const linkDir = "src"
+
+// sourceModule is a Go module containing a package being bound
+type sourceModule struct {
+	Path string // Module path from the module directive
+	Dir  string // Directory containing go.mod
+}
+
+// buildModule is a temporary module for building generated code outside the
+// source tree. It requires the source modules and replaces them with their
+// local directories, so builds work from any output directory.
+type buildModule struct {
+	Dir string
+	// Flags are the go build flags selecting the module mode
+	Flags []string
+	// Env holds environment overrides for go build
+	Env []string
+}
+
+// findModule returns the module containing dir
+func findModule(dir string) (sourceModule, error) {
+	for current := dir; ; current = filepath.Dir(current) {
+		data, err := os.ReadFile(filepath.Join(current, "go.mod"))
+		if err == nil {
+			for _, d := range parseDirectives(data) {
+				if d.Verb == "module" && len(d.Args) > 0 {
+					return sourceModule{Path: d.Args[0], Dir: current}, nil
+				}
+			}
+			return sourceModule{}, fmt.Errorf("no module directive in %s", filepath.Join(current, "go.mod"))
+		}
+		if filepath.Dir(current) == current {
+			return sourceModule{}, fmt.Errorf("no go.mod found for %s", dir)
+		}
+	}
+}
+
+// findWorkspace returns the go.work file that applies to dir, honoring GOWORK
+func findWorkspace(dir string) string {
+	switch gowork := os.Getenv("GOWORK"); gowork {
+	case "off":
+		return ""
+	case "":
+	default:
+		return gowork
+	}
+
+	for current := dir; ; current = filepath.Dir(current) {
+		path := filepath.Join(current, "go.work")
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		if filepath.Dir(current) == current {
+			return ""
+		}
+	}
+}
+
+// newBuildModule creates a temporary module for building the main package in
+// code against the modules containing packageDirs. The caller removes Dir.
+func newBuildModule(code []byte, packageDirs []string) (*buildModule, error) {
+	var modules []sourceModule
+	seen := make(map[string]bool)
+	for _, dir := range packageDirs {
+		mod, err := findModule(dir)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[mod.Dir] {
+			seen[mod.Dir] = true
+			modules = append(modules, mod)
+		}
+	}
+	if len(modules) == 0 {
+		return nil, fmt.Errorf("no packages to build")
+	}
+
+	tmpDir, err := os.MkdirTemp("", "goanywhere-build-")
+	if err != nil {
+		return nil, fmt.Errorf("cannot create build module: %w", err)
+	}
+	bm := &buildModule{Dir: tmpDir}
+
+	if err := bm.setup(code, modules); err != nil {
+		_ = os.RemoveAll(tmpDir)
+		return nil, err
+	}
+	return bm, nil
+}
+
+// localPaths assigns local module directories relative paths through the
+// build module's link directory
+type localPaths struct {
+	Dirs  []string // Linked directories, in link order
+	index map[string]int
+}
+
+// path returns the build module relative path linking to dir
+func (l *localPaths) path(dir string) string {
+	if l.index == nil {
+		l.index = make(map[string]int)
+	}
+	i, ok := l.index[dir]
+	if !ok {
+		i = len(l.Dirs)
+		l.index[dir] = i
+		l.Dirs = append(l.Dirs, dir)
+	}
+	return fmt.Sprintf("./%s/%d", linkDir, i)
+}
+
+// rewrite makes local replacements in data relative to dir go through links
+func (l *localPaths) rewrite(data []byte, dir string) []byte {
+	return rewritePaths(data, func(path string) string {
+		return l.path(resolveModPath(path, dir))
+	})
+}
+
+// setup writes main.go, go.mod and go.sum, and a go.work or vendor directory
+// when the source module uses one
+func (bm *buildModule) setup(code []byte, modules []sourceModule) error {
+	if err := os.WriteFile(filepath.Join(bm.Dir, "main.go"), code, 0644); err != nil {
+		return fmt.Errorf("cannot write build module: %w", err)
+	}
+
+	base := modules[0]
+	baseGoMod, err := os.ReadFile(filepath.Join(base.Dir, "go.mod"))
+	if err != nil {
+		return fmt.Errorf("cannot read go.mod: %w", err)
+	}
+
+	// Workspaces already resolve their modules; the build module joins them
+	if workFile := findWorkspace(base.Dir); workFile != "" && workspaceUses(workFile, modules) {
+		return bm.setupWorkspace(workFile, baseGoMod)
+	}
+
+	links := &localPaths{}
+	goMod := rewriteGoMod(baseGoMod, base.Dir, modules, links)
+	if err := os.WriteFile(filepath.Join(bm.Dir, "go.mod"), goMod, 0644); err != nil {
+		return fmt.Errorf("cannot write build module: %w", err)
+	}
+	if err := bm.writeGoSum(modules); err != nil {
+		return err
+	}
+
+	// Vendoring is only honored for a single module, as the source vendor
+	// directory only covers that module's dependencies
+	bm.Flags = []string{"-mod=mod"}
+	if len(modules) == 1 && useVendor(base.Dir, baseGoMod) {
+		if err := bm.setupVendor(base, baseGoMod, links); err != nil {
+			return err
+		}
+		bm.Flags = []string{"-mod=vendor"}
+	}
+	return bm.writeLinks(links)
+}
+
+// writeLinks creates the symlinks behind the local paths of the build module
+func (bm *buildModule) writeLinks(links *localPaths) error {
+	if len(links.Dirs) == 0 {
+		return nil
+	}
+	dir := filepath.Join(bm.Dir, linkDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("cannot write build module: %w", err)
+	}
+	for i, target := range links.Dirs {
+		if err := os.Symlink(target, filepath.Join(dir, strconv.Itoa(i))); err != nil {
+			return fmt.Errorf("cannot write build module: %w", err)
+		}
+	}
+	return nil
+}
+
+// setupWorkspace writes a go.work using the build module and, through links,
+// every module of the source workspace
+func (bm *buildModule) setupWorkspace(workFile string, baseGoMod []byte) error {
+	goMod := fmt.Sprintf("module %s\n", buildModulePath)
+	if version := directiveArg(baseGoMod, "go"); version != "" {
+		goMod += "\ngo " + version + "\n"
+	}
+	if err := os.WriteFile(filepath.Join(bm.Dir, "go.mod"), []byte(goMod), 0644); err != nil {
+		return fmt.Errorf("cannot write build module: %w", err)
+	}
+
+	data, err := os.ReadFile(workFile)
+	if err != nil {
+		return fmt.Errorf("cannot read go.work: %w", err)
+	}
+	links := &localPaths{}
+	work := links.rewrite(data, filepath.Dir(workFile))
+	work = append(work, []byte("\nuse .\n")...)
+	workPath := filepath.Join(bm.Dir, "go.work")
+	if err := os.WriteFile(workPath, work, 0644); err != nil {
+		return fmt.Errorf("cannot write build module: %w", err)
+	}
+	if err := bm.writeLinks(links); err != nil {
+		return err
+	}
+
+	if sum, err := os.ReadFile(workFile + ".sum"); err == nil {
+		if err := os.WriteFile(workPath+".sum", sum, 0644); err != nil {
+			return fmt.Errorf("cannot write build module: %w", err)
+		}
+	}
+
+	bm.Flags = []string{"-mod=readonly"}
+	bm.Env = []string{"GOWORK=" + workPath}
+	return nil
+}
+
+// writeGoSum merges the go.sum files of the source modules
+func (bm *buildModule) writeGoSum(modules []sourceModule) error {
+	var sum []string
+	seen := make(map[string]bool)
+	for _, mod := range modules {
+		data, err := os.ReadFile(filepath.Join(mod.Dir, "go.sum"))
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if line != "" && !seen[line] {
+				seen[line] = true
+				sum = append(sum, line)
+			}
+		}
+	}
+	if len(sum) == 0 {
+		return nil
+	}
+
+	content := strings.Join(sum, "\n") + "\n"
+	if err := os.WriteFile(filepath.Join(bm.Dir, "go.sum"), []byte(content), 0644); err != nil {
+		return fmt.Errorf("cannot write build module: %w", err)
+	}
+	return nil
+}
+
+// setupVendor mirrors the source vendor directory with symlinks and vendors
+// the source module itself, marked as replaced by its directory. Local paths
+// must match the replacements of the build module's go.mod.
+func (bm *buildModule) setupVendor(mod sourceModule, goMod []byte, links *localPaths) error {
+	srcVendor := filepath.Join(mod.Dir, "vendor")
+	dstVendor := filepath.Join(bm.Dir, "vendor")
+
+	if err := linkTree(srcVendor, dstVendor, mod.Path); err != nil {
+		return fmt.Errorf("cannot set up vendor directory: %w", err)
+	}
+	modVendor := filepath.Join(dstVendor, filepath.FromSlash(mod.Path))
+	if err := os.MkdirAll(filepath.Dir(modVendor), 0755); err != nil {
+		return fmt.Errorf("cannot set up vendor directory: %w", err)
+	}
+	if err := os.Symlink(mod.Dir, modVendor); err != nil {
+		return fmt.Errorf("cannot set up vendor directory: %w", err)
+	}
+
+	modulesTxt, err := os.ReadFile(filepath.Join(srcVendor, "modules.txt"))
+	if err != nil {
+		return fmt.Errorf("cannot read vendor/modules.txt: %w", err)
+	}
+	packages, err := modulePackages(mod)
+	if err != nil {
+		return fmt.Errorf("cannot list packages of %s: %w", mod.Path, err)
+	}
+
+	var buf strings.Builder
+	modPath := links.path(mod.Dir)
+	buf.Write(links.rewrite(modulesTxt, mod.Dir))
+	fmt.Fprintf(&buf, "# %s %s => %s\n", mod.Path, pseudoVersion, modPath)
+	explicit := "## explicit"
+	if version := directiveArg(goMod, "go"); version != "" {
+		explicit += "; go " + version
+	}
+	buf.WriteString(explicit + "\n")
+	for _, pkg := range packages {
+		buf.WriteString(pkg + "\n")
+	}
+	fmt.Fprintf(&buf, "# %s => %s\n", mod.Path, modPath)
+
+	if err := os.WriteFile(filepath.Join(dstVendor, "modules.txt"), []byte(buf.String()), 0644); err != nil {
+		return fmt.Errorf("cannot write vendor/modules.txt: %w", err)
+	}
+	return nil
+}
+
+// useVendor reports whether go would build the module in vendor mode
+func useVendor(modDir string, goMod []byte) bool {
+	for _, flag := range strings.Fields(os.Getenv("GOFLAGS")) {
+		if strings.HasPrefix(flag, "-mod=") {
+			return flag == "-mod=vendor"
+		}
+	}
+	if _, err := os.Stat(filepath.Join(modDir, "vendor", "modules.txt")); err != nil {
+		return false
+	}
+	// Vendoring is the default from go 1.14 when a vendor directory exists
+	version := directiveArg(goMod, "go")
+	return version != "" && !versionBefore(version, 1, 14)
+}
+
+// versionBefore reports whether a go directive version is older than major.minor
+func versionBefore(version string, major, minor int) bool {
+	var vMajor, vMinor int
+	if _, err := fmt.Sscanf(version, "%d.%d", &vMajor, &vMinor); err != nil {
+		return false
+	}
+	return vMajor < major || (vMajor == major && vMinor < minor)
+}
+
+// workspaceUses reports whether a go.work file uses every source module
+func workspaceUses(workFile string, modules []sourceModule) bool {
+	data, err := os.ReadFile(workFile)
+	if err != nil {
+		return false
+	}
+	used := make(map[string]bool)
+	for _, d := range parseDirectives(data) {
+		if d.Verb == "use" && len(d.Args) > 0 {
+			used[resolveModPath(d.Args[0], filepath.Dir(workFile))] = true
+		}
+	}
+	for _, mod := range modules {
+		if !used[filepath.Clean(mod.Dir)] {
+			return false
+		}
+	}
+	return true
+}
+
+// rewriteGoMod turns the base module's go.mod into the build module's go.mod:
+// it renames the module, points local replacements through links, and
+// requires each source module replaced by its directory
+func rewriteGoMod(data []byte, baseDir string, modules []sourceModule, links *localPaths) []byte {
+	sources := make(map[string]bool, len(modules))
+	for _, mod := range modules {
+		sources[mod.Path] = true
+	}
+
+	// Drop existing requirements and replacements of the source modules
+	lines := strings.Split(string(data), "\n")
+	for _, d := range parseDirectives(data) {
+		if (d.Verb == "require" || d.Verb == "replace") && len(d.Args) > 0 && sources[d.Args[0]] {
+			lines[d.Line] = ""
+		}
+		if d.Verb == "module" {
+			lines[d.Line] = "module " + buildModulePath
+		}
+	}
+
+	// Source modules take the first links, so the base module is always
+	// linked at the same path
+	for _, mod := range modules {
+		links.path(mod.Dir)
+	}
+
+	var buf strings.Builder
+	buf.Write(links.rewrite([]byte(strings.Join(lines, "\n")), baseDir))
+	buf.WriteString("\n")
+	for _, mod := range modules {
+		fmt.Fprintf(&buf, "require %s %s\n", mod.Path, pseudoVersion)
+	}
+	buf.WriteString("\n")
+	for _, mod := range modules {
+		fmt.Fprintf(&buf, "replace %s => %s\n", mod.Path, links.path(mod.Dir))
+	}
+	return []byte(buf.String())
+}
+
+// directive is a single go.mod, go.work or modules.txt directive
+type directive struct {
+	Verb string
+	Args []string
+	Line int // Index of the line holding the directive
+}
+
+// parseDirectives splits go.mod or go.work content into directives, expanding
+// blocks like "require ( ... )" into one directive per line
+func parseDirectives(data []byte) []directive {
+	var directives []directive
+	block := ""
+	for i, line := range strings.Split(string(data), "\n") {
+		if idx := strings.Index(line, "//"); idx >= 0 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if block != "" {
+			if fields[0] == ")" {
+				block = ""
+				continue
+			}
+			directives = append(directives, directive{Verb: block, Args: fields, Line: i})
+			continue
+		}
+
+		if len(fields) == 2 && fields[1] == "(" {
+			block = fields[0]
+			continue
+		}
+		directives = append(directives, directive{Verb: fields[0], Args: fields[1:], Line: i})
+	}
+	return directives
+}
+
+// directiveArg returns the first argument of a top-level directive
+func directiveArg(data []byte, verb string) string {
+	for _, d := range parseDirectives(data) {
+		if d.Verb == verb && len(d.Args) > 0 {
+			return d.Args[0]
+		}
+	}
+	return ""
+}
+
+// rewritePaths maps the filesystem paths in use directives and on the
+// right-hand side of replacements
+func rewritePaths(data []byte, mapPath func(string) string) []byte {
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		if before, after, ok := strings.Cut(line, "=>"); ok {
+			fields := strings.Fields(after)
+			if len(fields) > 0 && isLocalPath(fields[0]) {
+				rest := strings.TrimPrefix(strings.TrimSpace(after), fields[0])
+				lines[i] = before + "=> " + mapPath(fields[0]) + rest
+			}
+			continue
+		}
+
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) >= 2 && fields[0] == "use" && isLocalPath(fields[1]):
+			lines[i] = "use " + mapPath(fields[1])
+		case len(fields) == 1 && isLocalPath(fields[0]) && fields[0] != ")":
+			// Entry of a use ( ... ) block
+			lines[i] = "\t" + mapPath(fields[0])
+		}
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// isLocalPath reports whether a module path argument is a filesystem path
+func isLocalPath(path string) bool {
+	return path == "." || path == ".." || strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../") || filepath.IsAbs(path)
+}
+
+// resolveModPath makes a go.mod or go.work filesystem path absolute
+func resolveModPath(path, dir string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(dir, filepath.FromSlash(path))
+}
+
+// linkTree recreates src in dst with symlinks, descending into the directories
+// leading to keep (a slash-separated path) and leaving keep itself out
+func linkTree(src, dst, keep string) error {
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+
+	first, rest, _ := strings.Cut(keep, "/")
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if name == "modules.txt" {
+			continue
+		}
+		if name == first && entry.IsDir() && rest != "" {
+			if err := linkTree(filepath.Join(src, name), filepath.Join(dst, name), rest); err != nil {
+				return err
+			}
+			continue
+		}
+		if name == first && rest == "" {
+			// The module itself replaces any vendored copy
+			continue
+		}
+		if err := os.Symlink(filepath.Join(src, name), filepath.Join(dst, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// modulePackages lists the import paths of the packages in a module,
+// skipping vendor, testdata, hidden directories and nested modules
+func modulePackages(mod sourceModule) ([]string, error) {
+	var packages []string
+	err := filepath.WalkDir(mod.Dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+
+		name := d.Name()
+		if path != mod.Dir {
+			if name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+		}
+
+		matches, err := filepath.Glob(filepath.Join(path, "*.go"))
+		if err != nil || len(matches) == 0 {
+			return nil
+		}
+		rel, err := filepath.Rel(mod.Dir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			packages = append(packages, mod.Path)
+		} else {
+			packages = append(packages, mod.Path+"/"+filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	return packages, err
+}
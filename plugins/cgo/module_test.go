@@ -0,0 +1,195 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cgo
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Build module", func() {
+	var tmpDir string
+
+	writeFile := func(path, content string) {
+		full := filepath.Join(tmpDir, path)
+		Expect(os.MkdirAll(filepath.Dir(full), 0755)).To(Succeed())
+		Expect(os.WriteFile(full, []byte(content), 0644)).To(Succeed())
+	}
+
+	readFile := func(path string) string {
+		data, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		return string(data)
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "buildmodule")
+		Expect(err).NotTo(HaveOccurred())
+		// Resolve symlinks so paths compare equal on macOS
+		tmpDir, err = filepath.EvalSymlinks(tmpDir)
+		Expect(err).NotTo(HaveOccurred())
+
+		goflags, hadGoflags := os.LookupEnv("GOFLAGS")
+		gowork, hadGowork := os.LookupEnv("GOWORK")
+		Expect(os.Unsetenv("GOFLAGS")).To(Succeed())
+		Expect(os.Unsetenv("GOWORK")).To(Succeed())
+		DeferCleanup(func() {
+			if hadGoflags {
+				_ = os.Setenv("GOFLAGS", goflags)
+			}
+			if hadGowork {
+				_ = os.Setenv("GOWORK", gowork)
+			}
+		})
+	})
+
+	AfterEach(func() {
+		_ = os.RemoveAll(tmpDir)
+	})
+
+	Describe("parseDirectives", func() {
+		It("expands blocks and strips comments", func() {
+			directives := parseDirectives([]byte("module example.com/m // main\n\ngo 1.22\n\nrequire (\n\texample.com/a v1.0.0\n\texample.com/b v1.2.0 // indirect\n)\n"))
+			Expect(directives).To(Equal([]directive{
+				{Verb: "module", Args: []string{"example.com/m"}, Line: 0},
+				{Verb: "go", Args: []string{"1.22"}, Line: 2},
+				{Verb: "require", Args: []string{"example.com/a", "v1.0.0"}, Line: 5},
+				{Verb: "require", Args: []string{"example.com/b", "v1.2.0"}, Line: 6},
+			}))
+		})
+	})
+
+	Describe("localPaths", func() {
+		It("rewrites replacements and use directives through links", func() {
+			links := &localPaths{}
+			out := links.rewrite([]byte("replace example.com/a => ../a\nreplace example.com/b v1.0.0 => example.com/c v1.1.0\nuse ./x\nuse (\n\t../y\n\t/src/a\n)\n"), "/src/m")
+			Expect(string(out)).To(Equal("replace example.com/a => ./src/0\nreplace example.com/b v1.0.0 => example.com/c v1.1.0\nuse ./src/1\nuse (\n\t./src/2\n\t./src/0\n)\n"))
+			Expect(links.Dirs).To(Equal([]string{"/src/a", "/src/m/x", "/src/y"}))
+		})
+	})
+
+	Describe("rewriteGoMod", func() {
+		It("requires and replaces the source modules through links", func() {
+			goMod := "module example.com/m\n\ngo 1.22\n\nrequire (\n\texample.com/dep v1.0.0\n\texample.com/other v1.0.0\n)\n\nreplace example.com/dep => ./dep\n"
+			modules := []sourceModule{
+				{Path: "example.com/m", Dir: "/src/m"},
+				{Path: "example.com/other", Dir: "/src/other"},
+			}
+
+			links := &localPaths{}
+			out := string(rewriteGoMod([]byte(goMod), "/src/m", modules, links))
+			Expect(out).To(HavePrefix("module " + buildModulePath + "\n"))
+			Expect(out).To(ContainSubstring("go 1.22"))
+			Expect(out).To(ContainSubstring("example.com/dep v1.0.0"))
+			Expect(out).To(ContainSubstring("replace example.com/dep => ./src/2"))
+			Expect(out).NotTo(ContainSubstring("example.com/other v1.0.0"))
+			Expect(out).To(ContainSubstring("require example.com/m " + pseudoVersion))
+			Expect(out).To(ContainSubstring("require example.com/other " + pseudoVersion))
+			Expect(out).To(ContainSubstring("replace example.com/m => ./src/0"))
+			Expect(out).To(ContainSubstring("replace example.com/other => ./src/1"))
+			Expect(out).NotTo(ContainSubstring("/src/m"))
+			Expect(links.Dirs).To(Equal([]string{"/src/m", "/src/other", filepath.Join("/src/m", "dep")}))
+		})
+	})
+
+	Describe("findModule", func() {
+		It("finds the enclosing module", func() {
+			writeFile("go.mod", "module example.com/m\n\ngo 1.22\n")
+			writeFile("pkg/inner/inner.go", "package inner\n")
+
+			mod, err := findModule(filepath.Join(tmpDir, "pkg", "inner"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mod).To(Equal(sourceModule{Path: "example.com/m", Dir: tmpDir}))
+		})
+
+		It("returns error outside a module", func() {
+			_, err := findModule(tmpDir)
+			Expect(err).To(MatchError(ContainSubstring("no go.mod found")))
+		})
+	})
+
+	Describe("newBuildModule", func() {
+		BeforeEach(func() {
+			writeFile("go.mod", "module example.com/m\n\ngo 1.22\n")
+			writeFile("go.sum", "example.com/dep v1.0.0 h1:abc=\n")
+			writeFile("geo/geo.go", "package geo\n")
+		})
+
+		It("creates a module requiring the source module", func() {
+			bm, err := newBuildModule([]byte("package main\n"), []string{filepath.Join(tmpDir, "geo")})
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { _ = os.RemoveAll(bm.Dir) }()
+
+			Expect(readFile(filepath.Join(bm.Dir, "main.go"))).To(Equal("package main\n"))
+			Expect(readFile(filepath.Join(bm.Dir, "go.mod"))).To(ContainSubstring("replace example.com/m => ./src/0\n"))
+			target, err := os.Readlink(filepath.Join(bm.Dir, "src", "0"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(target).To(Equal(tmpDir))
+			Expect(readFile(filepath.Join(bm.Dir, "go.sum"))).To(ContainSubstring("example.com/dep v1.0.0"))
+			Expect(bm.Flags).To(Equal([]string{"-mod=mod"}))
+		})
+
+		It("vendors the source module when it uses a vendor directory", func() {
+			writeFile("vendor/modules.txt", "# example.com/dep v1.0.0\n## explicit\nexample.com/dep\n")
+			writeFile("vendor/example.com/dep/dep.go", "package dep\n")
+
+			bm, err := newBuildModule([]byte("package main\n"), []string{filepath.Join(tmpDir, "geo")})
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { _ = os.RemoveAll(bm.Dir) }()
+
+			Expect(bm.Flags).To(Equal([]string{"-mod=vendor"}))
+			modulesTxt := readFile(filepath.Join(bm.Dir, "vendor", "modules.txt"))
+			Expect(modulesTxt).To(ContainSubstring("# example.com/dep v1.0.0\n"))
+			Expect(modulesTxt).To(ContainSubstring("# example.com/m " + pseudoVersion + " => ./src/0\n## explicit; go 1.22\nexample.com/m/geo\n"))
+			Expect(modulesTxt).To(HaveSuffix("# example.com/m => ./src/0\n"))
+
+			target, err := os.Readlink(filepath.Join(bm.Dir, "vendor", "example.com", "m"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(target).To(Equal(tmpDir))
+			_, err = os.Stat(filepath.Join(bm.Dir, "vendor", "example.com", "dep", "dep.go"))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("honors -mod=mod in GOFLAGS over a vendor directory", func() {
+			writeFile("vendor/modules.txt", "")
+			Expect(os.Setenv("GOFLAGS", "-mod=mod")).To(Succeed())
+
+			bm, err := newBuildModule([]byte("package main\n"), []string{filepath.Join(tmpDir, "geo")})
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { _ = os.RemoveAll(bm.Dir) }()
+			Expect(bm.Flags).To(Equal([]string{"-mod=mod"}))
+		})
+
+		It("joins the workspace of the source module", func() {
+			writeFile("go.work", "go 1.22\n\nuse .\n")
+
+			bm, err := newBuildModule([]byte("package main\n"), []string{filepath.Join(tmpDir, "geo")})
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { _ = os.RemoveAll(bm.Dir) }()
+
+			workPath := filepath.Join(bm.Dir, "go.work")
+			Expect(readFile(workPath)).To(ContainSubstring("use ./src/0\n"))
+			Expect(readFile(workPath)).NotTo(ContainSubstring(tmpDir))
+			Expect(os.Readlink(filepath.Join(bm.Dir, "src", "0"))).To(Equal(tmpDir))
+			Expect(readFile(workPath)).To(HaveSuffix("use .\n"))
+			Expect(readFile(filepath.Join(bm.Dir, "go.mod"))).NotTo(ContainSubstring("replace"))
+			Expect(bm.Env).To(Equal([]string{"GOWORK=" + workPath}))
+		})
+	})
+})
@@ -0,0 +1,283 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cgo
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// InstallDir is the directory under the build output holding the install tree
+const InstallDir = "install"
+
+// libraryFiles describes a built library for the package files pointing at it
+type libraryFiles struct {
+	Name       string // library name, e.g. libmath
+	LibFile    string
+	HeaderFile string
+	Static     bool
+	GOOS       string
+	Version    string // Module version, such as 1.2.3
+	SOName     string // DT_SONAME of a shared library ("" for none)
+}
+
+// packageName returns the CMake package name: the library name without "lib"
+func (l *libraryFiles) packageName() string {
+	if name := strings.TrimPrefix(l.Name, "lib"); name != "" {
+		return name
+	}
+	return l.Name
+}
+
+// packageLayout places the library, header and package files under Root.
+// Directories are slash-separated and relative to Root.
+type packageLayout struct {
+	Root         string
+	LibDir       string
+	IncludeDir   string
+	PkgConfigDir string
+	CMakeDir     string
+}
+
+// flatLayout keeps every file in dir, next to the built library
+func flatLayout(dir string) packageLayout {
+	return packageLayout{Root: dir, LibDir: ".", IncludeDir: ".", PkgConfigDir: ".", CMakeDir: "."}
+}
+
+// installLayout lays files out as in an installation prefix
+func installLayout(root, packageName string) packageLayout {
+	return packageLayout{
+		Root:         root,
+		LibDir:       "lib",
+		IncludeDir:   "include",
+		PkgConfigDir: "lib/pkgconfig",
+		CMakeDir:     "lib/cmake/" + packageName,
+	}
+}
+
+// staticLinkFlags returns the system libraries a c-archive built for goos
+// must be linked with
+func staticLinkFlags(goos string) []string {
+	switch goos {
+	case "darwin", "ios":
+		return []string{"-lpthread", "-framework", "CoreFoundation", "-framework", "Security"}
+	case "windows":
+		return []string{"-lws2_32", "-lwinmm", "-lntdll"}
+	case "linux", "android":
+		return []string{"-lpthread", "-ldl", "-lm"}
+	default:
+		return []string{"-lpthread", "-lm"}
+	}
+}
+
+// sharedObjectName returns the DT_SONAME a shared library built for goos is
+// linked with: its file name on ELF platforms, "" where libraries are found
+// by install name or by file name instead
+func sharedObjectName(goos, libFile string) string {
+	switch goos {
+	case "darwin", "ios", "windows", "plan9", "js", "wasip1":
+		return ""
+	}
+	return filepath.Base(libFile)
+}
+
+// cmakeLinkItems groups static link flags into CMake link items, keeping
+// "-framework X" together
+func cmakeLinkItems(flags []string) []string {
+	var items []string
+	for i := 0; i < len(flags); i++ {
+		if flags[i] == "-framework" && i+1 < len(flags) {
+			items = append(items, "-framework "+flags[i+1])
+			i++
+			continue
+		}
+		items = append(items, flags[i])
+	}
+	return items
+}
+
+// writeLibraryPackage writes the pkg-config and CMake package files for lib in
+// layout, copying the library and header into it first when they live
+// elsewhere. It returns the files written.
+func writeLibraryPackage(layout packageLayout, lib *libraryFiles) ([]string, error) {
+	var written []string
+
+	libFile := filepath.Join(layout.Root, filepath.FromSlash(layout.LibDir), filepath.Base(lib.LibFile))
+	headerFile := filepath.Join(layout.Root, filepath.FromSlash(layout.IncludeDir), filepath.Base(lib.HeaderFile))
+	copies := []struct{ src, dst string }{{lib.LibFile, libFile}, {lib.HeaderFile, headerFile}}
+	for _, c := range copies {
+		if c.src == c.dst {
+			continue
+		}
+		if _, err := os.Stat(c.src); os.IsNotExist(err) && c.src == lib.HeaderFile {
+			continue
+		}
+		if err := copyFile(c.src, c.dst); err != nil {
+			return nil, err
+		}
+		written = append(written, c.dst)
+	}
+
+	data := packageFileData{
+		Name:       lib.Name,
+		Version:    lib.Version,
+		SOName:     lib.SOName,
+		Package:    lib.packageName(),
+		LibFile:    filepath.Base(lib.LibFile),
+		Static:     lib.Static,
+		SystemLibs: staticLinkFlags(lib.GOOS),
+		LibDir:     layout.LibDir,
+		IncludeDir: layout.IncludeDir,
+	}
+	data.CMakeLinkItems = cmakeLinkItems(data.SystemLibs)
+
+	files := []struct {
+		dir, name string
+		tmpl      *template.Template
+	}{
+		{layout.PkgConfigDir, lib.Name + ".pc", pkgConfigTemplate},
+		{layout.CMakeDir, data.Package + "Config.cmake", cmakeConfigTemplate},
+		{layout.CMakeDir, data.Package + "Targets.cmake", cmakeTargetsTemplate},
+	}
+	for _, f := range files {
+		// Paths in package files are relative to the file, so the tree can be moved
+		data.Prefix = relativeRoot(f.dir)
+
+		var buf bytes.Buffer
+		if err := f.tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("template error: %w", err)
+		}
+		file := filepath.Join(layout.Root, filepath.FromSlash(f.dir), f.name)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return nil, fmt.Errorf("cannot create package directory: %w", err)
+		}
+		if err := os.WriteFile(file, buf.Bytes(), 0644); err != nil {
+			return nil, fmt.Errorf("write error: %w", err)
+		}
+		written = append(written, file)
+	}
+
+	return written, nil
+}
+
+// relativeRoot returns the path from dir back to the layout root
+func relativeRoot(dir string) string {
+	dir = path.Clean(dir)
+	if dir == "." {
+		return "."
+	}
+	up := make([]string, strings.Count(dir, "/")+1)
+	for i := range up {
+		up[i] = ".."
+	}
+	return strings.Join(up, "/")
+}
+
+// copyFile copies src to dst, creating dst's directory
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("copy error: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("copy error: %w", err)
+	}
+	if err := os.WriteFile(dst, data, 0644); err != nil {
+		return fmt.Errorf("copy error: %w", err)
+	}
+	return nil
+}
+
+// packageFileData is the input of the package file templates
+type packageFileData struct {
+	Name           string
+	Version        string
+	SOName         string
+	Package        string
+	LibFile        string
+	Static         bool
+	SystemLibs     []string
+	CMakeLinkItems []string
+	Prefix         string // layout root, relative to the file
+	LibDir         string
+	IncludeDir     string
+}
+
+var templateFuncs = template.FuncMap{
+	"join": strings.Join,
+	// under joins a directory relative to the layout root to base
+	"under": func(base, dir string) string {
+		if dir == "." {
+			return base
+		}
+		return base + "/" + dir
+	},
+	// linkName returns the -l argument linking a shared library file
+	"linkName": func(libFile string) string {
+		name := strings.TrimSuffix(libFile, filepath.Ext(libFile))
+		if strings.HasPrefix(name, "lib") && len(name) > 3 {
+			return name[3:]
+		}
+		return ":" + libFile
+	},
+}
+
+var pkgConfigTemplate = template.Must(template.New("pc").Funcs(templateFuncs).Parse(`prefix={{under "${pcfiledir}" .Prefix}}
+libdir={{under "${prefix}" .LibDir}}
+includedir={{under "${prefix}" .IncludeDir}}
+
+Name: {{.Name}}
+Description: C bindings for Go code, generated by goanywhere
+Version: {{.Version}}
+Cflags: -I${includedir}
+{{- if .Static}}
+Libs: ${libdir}/{{.LibFile}} {{join .SystemLibs " "}}
+{{- else}}
+Libs: -L${libdir} -l{{linkName .LibFile}}
+{{- end}}
+`))
+
+var cmakeConfigTemplate = template.Must(template.New("config").Parse(`# Generated by goanywhere. Use with find_package({{.Package}}) and link {{.Package}}::{{.Package}}.
+include("${CMAKE_CURRENT_LIST_DIR}/{{.Package}}Targets.cmake")
+`))
+
+var cmakeTargetsTemplate = template.Must(template.New("targets").Funcs(templateFuncs).Parse(`# Generated by goanywhere. Imported target for {{.Name}}.
+if(TARGET {{.Package}}::{{.Package}})
+  return()
+endif()
+
+get_filename_component(_{{.Package}}_PREFIX "{{under "${CMAKE_CURRENT_LIST_DIR}" .Prefix}}" ABSOLUTE)
+
+add_library({{.Package}}::{{.Package}} {{if .Static}}STATIC{{else}}SHARED{{end}} IMPORTED)
+set_target_properties({{.Package}}::{{.Package}} PROPERTIES
+  IMPORTED_LOCATION "{{under (printf "${_%s_PREFIX}" .Package) .LibDir}}/{{.LibFile}}"
+  INTERFACE_INCLUDE_DIRECTORIES "{{under (printf "${_%s_PREFIX}" .Package) .IncludeDir}}"
+{{- if .SOName}}
+  IMPORTED_SONAME "{{.SOName}}"
+{{- else if not .Static}}
+  IMPORTED_NO_SONAME TRUE
+{{- end}}
+{{- if .Static}}
+  INTERFACE_LINK_LIBRARIES "{{join .CMakeLinkItems ";"}}"
+{{- end}}
+)
+
+unset(_{{.Package}}_PREFIX)
+`))
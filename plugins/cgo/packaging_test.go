@@ -0,0 +1,151 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cgo
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Library packaging", func() {
+	var (
+		tmpDir string
+		lib    *libraryFiles
+	)
+
+	readFile := func(path string) string {
+		data, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		return string(data)
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "packaging")
+		Expect(err).NotTo(HaveOccurred())
+
+		lib = &libraryFiles{
+			Name:       "libfoo",
+			LibFile:    filepath.Join(tmpDir, "libfoo.so"),
+			HeaderFile: filepath.Join(tmpDir, "libfoo.h"),
+			GOOS:       "linux",
+			Version:    "1.2.0",
+			SOName:     "libfoo.so",
+		}
+		Expect(os.WriteFile(lib.LibFile, []byte("lib"), 0644)).To(Succeed())
+		Expect(os.WriteFile(lib.HeaderFile, []byte("header"), 0644)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		_ = os.RemoveAll(tmpDir)
+	})
+
+	Describe("writeLibraryPackage", func() {
+		It("writes package files next to a shared library", func() {
+			files, err := writeLibraryPackage(flatLayout(tmpDir), lib)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(Equal([]string{
+				filepath.Join(tmpDir, "libfoo.pc"),
+				filepath.Join(tmpDir, "fooConfig.cmake"),
+				filepath.Join(tmpDir, "fooTargets.cmake"),
+			}))
+
+			pc := readFile(filepath.Join(tmpDir, "libfoo.pc"))
+			Expect(pc).To(ContainSubstring("prefix=${pcfiledir}\n"))
+			Expect(pc).To(ContainSubstring("libdir=${prefix}\n"))
+			Expect(pc).To(ContainSubstring("Version: 1.2.0\n"))
+			Expect(pc).To(ContainSubstring("Libs: -L${libdir} -lfoo\n"))
+
+			Expect(readFile(filepath.Join(tmpDir, "fooConfig.cmake"))).To(ContainSubstring(`include("${CMAKE_CURRENT_LIST_DIR}/fooTargets.cmake")`))
+			targets := readFile(filepath.Join(tmpDir, "fooTargets.cmake"))
+			Expect(targets).To(ContainSubstring(`get_filename_component(_foo_PREFIX "${CMAKE_CURRENT_LIST_DIR}" ABSOLUTE)`))
+			Expect(targets).To(ContainSubstring("add_library(foo::foo SHARED IMPORTED)"))
+			Expect(targets).To(ContainSubstring(`IMPORTED_LOCATION "${_foo_PREFIX}/libfoo.so"`))
+			Expect(targets).To(ContainSubstring(`INTERFACE_INCLUDE_DIRECTORIES "${_foo_PREFIX}"`))
+			Expect(targets).To(ContainSubstring(`IMPORTED_SONAME "libfoo.so"`))
+			Expect(targets).NotTo(ContainSubstring("INTERFACE_LINK_LIBRARIES"))
+		})
+
+		It("imports shared libraries without a soname by file name", func() {
+			lib.SOName = ""
+			_, err := writeLibraryPackage(flatLayout(tmpDir), lib)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(readFile(filepath.Join(tmpDir, "fooTargets.cmake"))).To(ContainSubstring("IMPORTED_NO_SONAME TRUE"))
+		})
+
+		It("lays out an install tree", func() {
+			lib.Static = true
+			lib.SOName = ""
+			lib.LibFile = filepath.Join(tmpDir, "libfoo.a")
+			Expect(os.WriteFile(lib.LibFile, []byte("archive"), 0644)).To(Succeed())
+			root := filepath.Join(tmpDir, InstallDir)
+
+			files, err := writeLibraryPackage(installLayout(root, "foo"), lib)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(Equal([]string{
+				filepath.Join(root, "lib", "libfoo.a"),
+				filepath.Join(root, "include", "libfoo.h"),
+				filepath.Join(root, "lib", "pkgconfig", "libfoo.pc"),
+				filepath.Join(root, "lib", "cmake", "foo", "fooConfig.cmake"),
+				filepath.Join(root, "lib", "cmake", "foo", "fooTargets.cmake"),
+			}))
+			Expect(readFile(filepath.Join(root, "lib", "libfoo.a"))).To(Equal("archive"))
+
+			pc := readFile(filepath.Join(root, "lib", "pkgconfig", "libfoo.pc"))
+			Expect(pc).To(ContainSubstring("prefix=${pcfiledir}/../..\n"))
+			Expect(pc).To(ContainSubstring("libdir=${prefix}/lib\n"))
+			Expect(pc).To(ContainSubstring("includedir=${prefix}/include\n"))
+			Expect(pc).To(ContainSubstring("Libs: ${libdir}/libfoo.a -lpthread -ldl -lm\n"))
+
+			targets := readFile(filepath.Join(root, "lib", "cmake", "foo", "fooTargets.cmake"))
+			Expect(targets).To(ContainSubstring(`"${CMAKE_CURRENT_LIST_DIR}/../../.."`))
+			Expect(targets).To(ContainSubstring("add_library(foo::foo STATIC IMPORTED)"))
+			Expect(targets).To(ContainSubstring(`IMPORTED_LOCATION "${_foo_PREFIX}/lib/libfoo.a"`))
+			Expect(targets).To(ContainSubstring(`INTERFACE_LINK_LIBRARIES "-lpthread;-ldl;-lm"`))
+			Expect(targets).NotTo(ContainSubstring("SONAME"))
+		})
+
+		It("links libraries without a lib prefix by file name", func() {
+			lib.Name = "foo"
+			lib.LibFile = filepath.Join(tmpDir, "foo.so")
+			Expect(os.WriteFile(lib.LibFile, []byte("lib"), 0644)).To(Succeed())
+
+			_, err := writeLibraryPackage(flatLayout(tmpDir), lib)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(readFile(filepath.Join(tmpDir, "foo.pc"))).To(ContainSubstring("Libs: -L${libdir} -l:foo.so\n"))
+		})
+	})
+
+	Describe("relativeRoot", func() {
+		It("walks back to the layout root", func() {
+			Expect(relativeRoot(".")).To(Equal("."))
+			Expect(relativeRoot("lib")).To(Equal(".."))
+			Expect(relativeRoot("lib/cmake/foo")).To(Equal("../../.."))
+		})
+	})
+
+	Describe("staticLinkFlags", func() {
+		It("returns the system libraries of the platform", func() {
+			Expect(staticLinkFlags("linux")).To(Equal([]string{"-lpthread", "-ldl", "-lm"}))
+			Expect(staticLinkFlags("windows")).To(ContainElement("-lws2_32"))
+			Expect(cmakeLinkItems(staticLinkFlags("darwin"))).To(Equal([]string{
+				"-lpthread", "-framework CoreFoundation", "-framework Security",
+			}))
+		})
+	})
+})
@@ -16,7 +16,11 @@ package cgo
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"go/token"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -28,8 +32,17 @@ import (
 	"github.com/riceriley59/goanywhere/internal/core/factory"
 )
 
-// Ensure Plugin implements core.Plugin interface
-var _ core.Plugin = (*Plugin)(nil)
+// Ensure Plugin implements core.Plugin, core.Configurable, core.Bundler,
+// core.ABIDescriber, core.BindingReporter and core.Diagnoser interfaces
+var (
+	_ core.Plugin          = (*Plugin)(nil)
+	_ core.Configurable    = (*Plugin)(nil)
+	_ core.Bundler         = (*Plugin)(nil)
+	_ core.ABIDescriber    = (*Plugin)(nil)
+	_ core.BindingReporter = (*Plugin)(nil)
+	_ core.Diagnoser       = (*Plugin)(nil)
+	_ core.LibraryBuilder  = (*Plugin)(nil)
+)
 
 func init() {
 	factory.Register("cgo", func(verbose bool) core.Plugin {
@@ -40,8 +53,13 @@ func init() {
 // Plugin implements the core.Plugin interface for CGO
 type Plugin struct {
 	verbose bool
+	async   bool // Also export <Export>_Async variants running calls on goroutines
 	mapper  *TypeMapper
 	pkg     *core.ParsedPackage
+	alias   string            // Import alias of pkg in the generated code
+	aliases map[string]string // Import path to alias of every package being generated
+	exports []core.Export     // Symbols written by the last generate
+	diags   []core.Diagnostic // Declarations dropped by the last generate
 }
 
 // NewPlugin creates a new CGO Plugin
@@ -56,15 +74,137 @@ func (a *Plugin) Name() string {
 	return "cgo"
 }
 
+// Options declares the CGO plugin options
+func (a *Plugin) Options() []core.OptionSpec {
+	return []core.OptionSpec{
+		{Name: "async", Type: core.OptionBool, Description: "Also export <Export>_Async variants of functions and methods that run on a goroutine"},
+	}
+}
+
+// Configure applies parsed CGO plugin options
+func (a *Plugin) Configure(opts core.Options) error {
+	a.async = opts.Bool("async")
+	return nil
+}
+
 // Generate produces CGO plugin code for the given parsed package
 func (a *Plugin) Generate(pkg *core.ParsedPackage) ([]byte, error) {
-	a.pkg = pkg
-	a.mapper = NewTypeMapper(pkg.Structs)
+	return a.generate([]*core.ParsedPackage{pkg}, []string{"target"})
+}
+
+// Exports returns the C symbols Generate writes for pkg
+func (a *Plugin) Exports(pkg *core.ParsedPackage) ([]core.Export, error) {
+	if _, err := a.Generate(pkg); err != nil {
+		return nil, err
+	}
+	return a.exports, nil
+}
+
+// ReportBindings describes the C symbols Generate writes for each declaration
+// of pkg and the declarations it drops
+func (a *Plugin) ReportBindings(pkg *core.ParsedPackage) (*core.BindingReport, error) {
+	exports, err := a.Exports(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &core.BindingReport{Diagnostics: a.diags}
+	for _, export := range exports {
+		kind, ok := exportDecls[export.Kind]
+		if !ok {
+			continue
+		}
+		report.Bindings = append(report.Bindings, core.Binding{
+			Decl:      core.Decl{Symbol: export.Source, Kind: kind},
+			Name:      export.Symbol,
+			Signature: exportPrototype(export),
+		})
+	}
+	return report, nil
+}
+
+// exportDecls maps the kinds of exports to the declarations they bind
+var exportDecls = map[core.ExportKind]core.DeclKind{
+	core.ExportFunction:    core.DeclFunction,
+	core.ExportConstructor: core.DeclStruct,
+	core.ExportDestructor:  core.DeclStruct,
+	core.ExportGetter:      core.DeclField,
+	core.ExportSetter:      core.DeclField,
+	core.ExportMethod:      core.DeclMethod,
+}
+
+// exportPrototype formats an export as the C prototype cgo writes for it in
+// the library header
+func exportPrototype(export core.Export) string {
+	params := make([]string, len(export.Params))
+	for i, param := range export.Params {
+		params[i] = cDecl(param.Type) + " " + param.Name
+	}
+	if len(params) == 0 {
+		params = []string{"void"}
+	}
+	result := "void"
+	if export.Result != nil {
+		result = cDecl(export.Result.Type)
+	}
+	return result + " " + export.Symbol + "(" + strings.Join(params, ", ") + ");"
+}
+
+// cNames maps cgo names of C types to their spelling in C
+var cNames = map[string]string{
+	"schar":     "signed char",
+	"uchar":     "unsigned char",
+	"ushort":    "unsigned short",
+	"uint":      "unsigned int",
+	"ulong":     "unsigned long",
+	"longlong":  "long long",
+	"ulonglong": "unsigned long long",
+	"bool":      "_Bool",
+}
+
+// cDecl returns the C spelling of a lowered type, e.g. "long long*" for
+// "*C.longlong"
+func cDecl(lowered string) string {
+	base := strings.TrimLeft(lowered, "*")
+	pointers := strings.Repeat("*", len(lowered)-len(base))
+	if base == "unsafe.Pointer" {
+		return "void*" + pointers
+	}
+	name := strings.TrimPrefix(base, "C.")
+	if c, ok := cNames[name]; ok {
+		name = c
+	}
+	return name + pointers
+}
+
+// GenerateBundle produces a single CGO main package exporting the symbols of
+// several packages. Exports are prefixed per package, and struct handles
+// created by one package can be passed to functions of another.
+func (a *Plugin) GenerateBundle(pkgs []*core.ParsedPackage) ([]byte, error) {
+	if err := core.PrepareBundle(pkgs); err != nil {
+		return nil, err
+	}
+
+	aliases := make([]string, len(pkgs))
+	for i, pkg := range pkgs {
+		aliases[i] = "target_" + pkg.Name
+	}
+	return a.generate(pkgs, aliases)
+}
+
+// generate writes the wrappers of each package, imported under the matching alias
+func (a *Plugin) generate(pkgs []*core.ParsedPackage, aliases []string) ([]byte, error) {
+	a.aliases = make(map[string]string, len(pkgs))
+	a.exports = nil
+	a.diags = nil
+	for i, pkg := range pkgs {
+		a.aliases[pkg.ImportPath] = aliases[i]
+	}
 
 	var buf bytes.Buffer
 
 	// Write header
-	if err := a.writeHeader(&buf); err != nil {
+	if err := a.writeHeader(&buf, pkgs, aliases); err != nil {
 		return nil, fmt.Errorf("failed to write header: %w", err)
 	}
 
@@ -78,29 +218,51 @@ func (a *Plugin) Generate(pkg *core.ParsedPackage) ([]byte, error) {
 		return nil, fmt.Errorf("failed to write free functions: %w", err)
 	}
 
-	// Write function wrappers
-	for _, fn := range pkg.Functions {
-		if fn.IsVariadic {
-			if a.verbose {
-				fmt.Printf("Skipping variadic function: %s\n", fn.Name)
-			}
-			continue
+	// Write the iterator runtime when any iterator is returned
+	if usesIterators(pkgs) {
+		a.writeIterRuntime(&buf)
+	}
+
+	// Write the buffer runtime when any numbers are passed in buffers
+	if usesBuffers(pkgs) {
+		a.writeBufferRuntime(&buf)
+	}
+
+	// Write the context runtime when any context is passed
+	if takesContexts(pkgs) {
+		a.writeContextRuntime(&buf)
+	}
+
+	for i, pkg := range pkgs {
+		a.pkg = pkg
+		a.alias = aliases[i]
+		a.mapper = NewTypeMapper(pkg.Structs)
+		for _, other := range pkgs {
+			a.mapper.AddPackage(other.ImportPath)
+		}
+
+		if len(pkgs) > 1 {
+			fmt.Fprintf(&buf, "\n// ============ Package %s ============\n", pkg.Name)
 		}
-		if err := a.writeFunction(&buf, fn); err != nil {
-			if a.verbose {
-				fmt.Printf("Skipping function %s: %v\n", fn.Name, err)
+
+		// Write function wrappers
+		for _, fn := range pkg.Functions {
+			if fn.IsVariadic {
+				a.skip(fn.Pos, fn.Name, core.DeclFunction, errVariadic)
+				continue
+			}
+			if err := a.writeFunction(&buf, fn); err != nil {
+				a.skip(fn.Pos, fn.Name, core.DeclFunction, err)
+				continue
 			}
-			continue
 		}
-	}
 
-	// Write struct wrappers
-	for _, st := range pkg.Structs {
-		if err := a.writeStructWrapper(&buf, st); err != nil {
-			if a.verbose {
-				fmt.Printf("Skipping struct %s: %v\n", st.Name, err)
+		// Write struct wrappers
+		for _, st := range pkg.Structs {
+			if err := a.writeStructWrapper(&buf, st); err != nil {
+				a.skip(st.Pos, st.Name, core.DeclStruct, err)
+				continue
 			}
-			continue
 		}
 	}
 
@@ -110,10 +272,29 @@ func (a *Plugin) Generate(pkg *core.ParsedPackage) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// errVariadic is the reason variadic functions and methods are skipped
+var errVariadic = errors.New("variadic parameters are not supported")
+
+// errIterResult is the reason functions returning an iterator alongside
+// other results are skipped
+var errIterResult = errors.New("iterators are only supported as the only non-error result")
+
+// skip records a diagnostic for a declaration the generated code does not bind
+func (a *Plugin) skip(pos token.Position, symbol string, kind core.DeclKind, err error) {
+	a.diags = append(a.diags, core.Skipped(pos, symbol, kind, err))
+}
+
+// Diagnostics returns the declarations dropped by the last Generate or Build
+func (a *Plugin) Diagnostics() []core.Diagnostic {
+	return a.diags
+}
+
 // writeHeader writes the file header with imports and CGO directives
-func (a *Plugin) writeHeader(buf *bytes.Buffer) error {
+func (a *Plugin) writeHeader(buf *bytes.Buffer, pkgs []*core.ParsedPackage, aliases []string) error {
 	tmpl := `// Code generated by goanywhere. DO NOT EDIT.
-// source: {{.Dir}}
+{{- range .Imports}}
+// source: {{.Source}}
+{{- end}}
 
 package main
 
@@ -121,18 +302,45 @@ package main
 #include <stdlib.h>
 #include <stdint.h>
 #include <stdbool.h>
+{{- if .Async}}
+
+// goanywhere_done is called with the call ID of an _Async export once the
+// call returns, on a thread of the Go runtime
+typedef void (*goanywhere_done)(uintptr_t);
+
+static inline void goanywhere_complete(goanywhere_done done, uintptr_t call) {
+	done(call);
+}
+{{- end}}
 */
 import "C"
 import (
+{{- if .Contexts}}
+	"context"
+{{- end}}
+	"encoding/json"
+	"errors"
+	"fmt"
+{{- if .Iterators}}
+	"iter"
+{{- end}}
 	"sync"
 	"unsafe"
-
-	target "{{.ImportPath}}"
+{{range .Imports}}
+	{{if .FirstExport}}{{.Alias}}{{else}}_{{end}} "{{.ImportPath}}"
+{{- end}}
 )
 
 // Silence unused import warnings
 var _ = unsafe.Pointer(nil)
-var _ = target.{{.FirstExport}}
+var _ = json.Marshal
+var _ = errors.New
+var _ = fmt.Errorf
+{{- range .Imports}}
+{{- if .FirstExport}}
+var _ = {{.Alias}}.{{.FirstExport}}
+{{- end}}
+{{- end}}
 
 `
 	t, err := template.New("header").Parse(tmpl)
@@ -140,45 +348,85 @@ var _ = target.{{.FirstExport}}
 		return err
 	}
 
-	// Find first exported symbol for import check
-	firstExport := ""
-	if len(a.pkg.Functions) > 0 {
-		firstExport = a.pkg.Functions[0].Name
-	} else if len(a.pkg.Structs) > 0 {
-		firstExport = a.pkg.Structs[0].Name + "{}"
-	}
-
-	data := struct {
-		Dir         string
+	type importData struct {
+		Source      string
+		Alias       string
 		ImportPath  string
 		FirstExport string
-	}{
-		Dir:         a.pkg.Dir,
-		ImportPath:  a.pkg.ImportPath,
-		FirstExport: firstExport,
 	}
 
-	return t.Execute(buf, data)
+	var imports []importData
+	for i, pkg := range pkgs {
+		// Find first exported symbol for import check; a package without
+		// any is imported for its side effects only
+		firstExport := ""
+		if len(pkg.Functions) > 0 {
+			firstExport = pkg.Functions[0].Name
+		} else if len(pkg.Structs) > 0 {
+			firstExport = pkg.Structs[0].Name + "{}"
+		}
+
+		imports = append(imports, importData{
+			Source:      pkg.Source(),
+			Alias:       aliases[i],
+			ImportPath:  pkg.ImportPath,
+			FirstExport: firstExport,
+		})
+	}
+
+	return t.Execute(buf, struct {
+		Imports   []importData
+		Iterators bool
+		Contexts  bool
+		Async     bool
+	}{imports, usesIterators(pkgs), takesContexts(pkgs), a.async})
+}
+
+// qualify returns the Go type expression of a struct type in the generated code
+func (a *Plugin) qualify(pt core.ParsedType) string {
+	if alias, ok := a.aliases[pt.PackagePath]; ok && pt.PackagePath != "" {
+		return alias + "." + pt.Name
+	}
+	return a.alias + "." + pt.Name
 }
 
 // writeHandleRegistry writes the handle management code
 func (a *Plugin) writeHandleRegistry(buf *bytes.Buffer) error {
 	code := `
-// Handle registry for preventing GC of Go objects passed to C
+// Handle registry for preventing GC of Go objects passed to C. Handles are
+// reference counted: every registerHandle or retainHandle is paired with a
+// freeHandle, and the object is released with the last one. Objects are
+// pointers, interned so that a pointer has one handle while it is registered.
 var (
 	handleMu      sync.RWMutex
 	handleMap     = make(map[uintptr]interface{})
+	handleRefs    = make(map[uintptr]int)
+	handleIndex   = make(map[interface{}]uintptr)
 	handleCounter uintptr
 )
 
 func registerHandle(obj interface{}) C.uintptr_t {
 	handleMu.Lock()
 	defer handleMu.Unlock()
+	if h, ok := handleIndex[obj]; ok {
+		handleRefs[h]++
+		return C.uintptr_t(h)
+	}
 	handleCounter++
 	handleMap[handleCounter] = obj
+	handleRefs[handleCounter] = 1
+	handleIndex[obj] = handleCounter
 	return C.uintptr_t(handleCounter)
 }
 
+func retainHandle(h C.uintptr_t) {
+	handleMu.Lock()
+	defer handleMu.Unlock()
+	if _, ok := handleMap[uintptr(h)]; ok {
+		handleRefs[uintptr(h)]++
+	}
+}
+
 func getHandle(h C.uintptr_t) (interface{}, bool) {
 	handleMu.RLock()
 	defer handleMu.RUnlock()
@@ -189,7 +437,15 @@ func getHandle(h C.uintptr_t) (interface{}, bool) {
 func freeHandle(h C.uintptr_t) {
 	handleMu.Lock()
 	defer handleMu.Unlock()
-	delete(handleMap, uintptr(h))
+	obj, ok := handleMap[uintptr(h)]
+	if !ok {
+		return
+	}
+	if handleRefs[uintptr(h)]--; handleRefs[uintptr(h)] <= 0 {
+		delete(handleMap, uintptr(h))
+		delete(handleRefs, uintptr(h))
+		delete(handleIndex, obj)
+	}
 }
 
 `
@@ -199,6 +455,11 @@ func freeHandle(h C.uintptr_t) {
 
 // writeFreeFunctions writes memory management functions
 func (a *Plugin) writeFreeFunctions(buf *bytes.Buffer) error {
+	a.exports = append(a.exports,
+		core.Export{Symbol: "Free_String", Kind: core.ExportRuntime, Params: []core.ExportParam{{Name: "s", Type: "*C.char"}}},
+		core.Export{Symbol: "Free_Bytes", Kind: core.ExportRuntime, Params: []core.ExportParam{{Name: "data", Type: "unsafe.Pointer"}}},
+		core.Export{Symbol: "Retain_Handle", Kind: core.ExportRuntime, Params: []core.ExportParam{{Name: "h", Type: "C.uintptr_t"}}},
+	)
 	code := `// ============ Memory Management ============
 
 //export Free_String
@@ -215,6 +476,11 @@ func Free_Bytes(data unsafe.Pointer) {
 	}
 }
 
+//export Retain_Handle
+func Retain_Handle(h C.uintptr_t) {
+	retainHandle(h)
+}
+
 `
 	buf.WriteString(code)
 	return nil
@@ -222,7 +488,8 @@ func Free_Bytes(data unsafe.Pointer) {
 
 // writeFunction writes a single function adapter
 func (a *Plugin) writeFunction(buf *bytes.Buffer, fn core.ParsedFunc) error {
-	exportName := a.pkg.Name + "_" + fn.Name
+	exportName := a.pkg.FuncSymbol(fn.Name)
+	export := core.Export{Symbol: exportName, Kind: core.ExportFunction, Source: fn.Name}
 
 	// Build parameter list
 	var cParams []string
@@ -232,7 +499,7 @@ func (a *Plugin) writeFunction(buf *bytes.Buffer, fn core.ParsedFunc) error {
 	var errorIndex int
 
 	for i, param := range fn.Params {
-		ctype, err := a.mapper.MapType(param.Type)
+		ctype, err := a.mapper.MapParam(param.Type)
 		if err != nil {
 			return err
 		}
@@ -243,9 +510,16 @@ func (a *Plugin) writeFunction(buf *bytes.Buffer, fn core.ParsedFunc) error {
 		}
 
 		cParams = append(cParams, fmt.Sprintf("%s %s", paramName, ctype.CTypeName))
+		export.Params = append(export.Params, core.ExportParam{Name: paramName, Type: ctype.CTypeName, GoType: param.Type.DeclaredName()})
+		extra := bufferParams(paramName, param.Type)
+		cParams = append(cParams, paramList(extra)...)
+		export.Params = append(export.Params, extra...)
 
 		// Generate conversion
 		goArg, conv := a.generateInputConversion(paramName, param.Type, ctype)
+		if param.Borrowed && param.Type.IsBuffer() {
+			goArg, conv = a.bufferInput(paramName, param.Type, true)
+		}
 		goArgs = append(goArgs, goArg)
 		if conv != "" {
 			conversions = append(conversions, conv)
@@ -258,6 +532,7 @@ func (a *Plugin) writeFunction(buf *bytes.Buffer, fn core.ParsedFunc) error {
 			hasError = true
 			errorIndex = i
 			cParams = append(cParams, "outError **C.char")
+			export.Params = append(export.Params, core.ExportParam{Name: "outError", Type: "**C.char", GoType: "error"})
 		}
 	}
 
@@ -280,20 +555,19 @@ func (a *Plugin) writeFunction(buf *bytes.Buffer, fn core.ParsedFunc) error {
 		}
 		returnType = ctype.CTypeName
 		returnConversion = a.generateOutputConversion("result", nonErrorResults[0].Type, ctype)
-	} else {
-		// Multiple return values - use out parameters
-		for i, result := range nonErrorResults {
-			ctype, err := a.mapper.MapType(result.Type)
-			if err != nil {
-				return err
-			}
-			name := result.Name
-			if name == "" {
-				name = fmt.Sprintf("out%d", i)
-			}
-			cParams = append(cParams, fmt.Sprintf("%s *%s", name, ctype.CTypeName))
-		}
+		export.Result = &core.ExportParam{Type: returnType, GoType: nonErrorResults[0].Type.DeclaredName(), Ownership: nonErrorResults[0].Ownership}
+		extra := bufferOutParams(nonErrorResults[0].Type)
+		cParams = append(cParams, paramList(extra)...)
+		export.Params = append(export.Params, extra...)
+	}
+	outs, err := a.outParams(nonErrorResults, &export)
+	if err != nil {
+		return err
 	}
+	for _, out := range outs {
+		cParams = append(cParams, fmt.Sprintf("%s *%s", out.name, out.ctype.CTypeName))
+	}
+	a.exports = append(a.exports, export)
 
 	// Generate function body
 	fmt.Fprintf(buf, "\n//export %s\n", exportName)
@@ -309,9 +583,12 @@ func (a *Plugin) writeFunction(buf *bytes.Buffer, fn core.ParsedFunc) error {
 	}
 
 	// Call the function
-	if hasError {
+	if len(outs) > 0 {
+		call := fmt.Sprintf("%s.%s(%s)", a.alias, fn.Name, strings.Join(goArgs, ", "))
+		a.writeOutResults(buf, call, len(fn.Results), hasError, errorIndex, outs)
+	} else if hasError {
 		if len(nonErrorResults) == 1 {
-			fmt.Fprintf(buf, "\tresult, err := target.%s(%s)\n", fn.Name, strings.Join(goArgs, ", "))
+			fmt.Fprintf(buf, "\tresult, err := %s.%s(%s)\n", a.alias, fn.Name, strings.Join(goArgs, ", "))
 			buf.WriteString("\tif err != nil {\n")
 			buf.WriteString("\t\t*outError = C.CString(err.Error())\n")
 			fmt.Fprintf(buf, "\t\treturn %s\n", a.zeroValue(nonErrorResults[0].Type))
@@ -323,7 +600,7 @@ func (a *Plugin) writeFunction(buf *bytes.Buffer, fn core.ParsedFunc) error {
 				buf.WriteString("\treturn result\n")
 			}
 		} else if len(nonErrorResults) == 0 {
-			fmt.Fprintf(buf, "\terr := target.%s(%s)\n", fn.Name, strings.Join(goArgs, ", "))
+			fmt.Fprintf(buf, "\terr := %s.%s(%s)\n", a.alias, fn.Name, strings.Join(goArgs, ", "))
 			buf.WriteString("\tif err != nil {\n")
 			buf.WriteString("\t\t*outError = C.CString(err.Error())\n")
 			buf.WriteString("\t\treturn\n")
@@ -332,35 +609,47 @@ func (a *Plugin) writeFunction(buf *bytes.Buffer, fn core.ParsedFunc) error {
 		}
 	} else {
 		if len(nonErrorResults) == 1 {
-			fmt.Fprintf(buf, "\tresult := target.%s(%s)\n", fn.Name, strings.Join(goArgs, ", "))
+			fmt.Fprintf(buf, "\tresult := %s.%s(%s)\n", a.alias, fn.Name, strings.Join(goArgs, ", "))
 			if returnConversion != "" {
 				fmt.Fprintf(buf, "\treturn %s\n", returnConversion)
 			} else {
 				buf.WriteString("\treturn result\n")
 			}
 		} else if len(nonErrorResults) == 0 {
-			fmt.Fprintf(buf, "\ttarget.%s(%s)\n", fn.Name, strings.Join(goArgs, ", "))
+			fmt.Fprintf(buf, "\t%s.%s(%s)\n", a.alias, fn.Name, strings.Join(goArgs, ", "))
 		}
 	}
 
 	buf.WriteString("}\n")
+
+	if a.async {
+		a.writeAsync(buf, export)
+	}
+	if export.Result != nil && nonErrorResults[0].Type.Kind == core.KindIter {
+		return a.writeIterNext(buf, exportName, export.Source, nonErrorResults[0].Type)
+	}
 	return nil
 }
 
 // writeStructWrapper writes plugin code for a struct and its methods
 func (a *Plugin) writeStructWrapper(buf *bytes.Buffer, st core.ParsedStruct) error {
-	prefix := st.Name
+	prefix := a.pkg.StructPrefix(st.Name)
 
 	fmt.Fprintf(buf, "\n// ============ %s Struct ============\n", st.Name)
+	handle := &core.ExportParam{Type: "C.uintptr_t", GoType: "*" + st.Name}
+	a.exports = append(a.exports,
+		core.Export{Symbol: prefix + "_New", Kind: core.ExportConstructor, Source: st.Name, Result: handle},
+		core.Export{Symbol: prefix + "_Free", Kind: core.ExportDestructor, Source: st.Name, Params: []core.ExportParam{{Name: "h", Type: handle.Type, GoType: handle.GoType}}},
+	)
 
 	// Write constructor
 	fmt.Fprintf(buf, `
 //export %s_New
 func %s_New() C.uintptr_t {
-	obj := &target.%s{}
+	obj := &%s.%s{}
 	return registerHandle(obj)
 }
-`, prefix, prefix, st.Name)
+`, prefix, prefix, a.alias, st.Name)
 
 	// Write destructor
 	fmt.Fprintf(buf, `
@@ -378,29 +667,46 @@ func %s_Free(h C.uintptr_t) {
 
 		ctype, err := a.mapper.MapType(field.Type)
 		if err != nil {
-			if a.verbose {
-				fmt.Printf("Skipping field %s.%s: %v\n", st.Name, field.Name, err)
-			}
+			a.skip(field.Pos, st.Name+"."+field.Name, core.DeclField, err)
 			continue
 		}
 
 		// Getter
+		source := st.Name + "." + field.Name
+		fieldParam := core.ExportParam{Type: ctype.CTypeName, GoType: field.Type.DeclaredName(), Ownership: field.Ownership}
+		getterParams := append([]core.ExportParam{{Name: "h", Type: handle.Type, GoType: handle.GoType}}, bufferOutParams(field.Type)...)
+		a.exports = append(a.exports, core.Export{
+			Symbol: prefix + "_Get" + field.Name,
+			Kind:   core.ExportGetter,
+			Source: source,
+			Params: getterParams,
+			Result: &fieldParam,
+		})
 		getterConv := a.generateOutputConversion("obj."+field.Name, field.Type, ctype)
 		fmt.Fprintf(buf, `
 //export %s_Get%s
-func %s_Get%s(h C.uintptr_t) %s {
+func %s_Get%s(%s) %s {
 	raw, ok := getHandle(h)
 	if !ok {
 		return %s
 	}
-	obj := raw.(*target.%s)
+	obj := raw.(*%s.%s)
 	return %s
 }
-`, prefix, field.Name, prefix, field.Name, ctype.CTypeName, a.zeroValue(field.Type), st.Name, getterConv)
+`, prefix, field.Name, prefix, field.Name, strings.Join(paramList(getterParams), ", "), ctype.CTypeName, a.zeroValue(field.Type), a.alias, st.Name, getterConv)
 
 		// Setter (skip for complex types that can't be easily set)
 		if !ctype.IsHandle && field.Type.Kind != core.KindSlice && field.Type.Kind != core.KindMap {
 			setterConv, _ := a.generateInputConversion("val", field.Type, ctype)
+			a.exports = append(a.exports, core.Export{
+				Symbol: prefix + "_Set" + field.Name,
+				Kind:   core.ExportSetter,
+				Source: source,
+				Params: []core.ExportParam{
+					{Name: "h", Type: handle.Type, GoType: handle.GoType},
+					{Name: "val", Type: fieldParam.Type, GoType: fieldParam.GoType},
+				},
+			})
 			fmt.Fprintf(buf, `
 //export %s_Set%s
 func %s_Set%s(h C.uintptr_t, val %s) {
@@ -408,25 +714,25 @@ func %s_Set%s(h C.uintptr_t, val %s) {
 	if !ok {
 		return
 	}
-	obj := raw.(*target.%s)
+	obj := raw.(*%s.%s)
 	obj.%s = %s
 }
-`, prefix, field.Name, prefix, field.Name, ctype.CTypeName, st.Name, field.Name, setterConv)
+`, prefix, field.Name, prefix, field.Name, ctype.CTypeName, a.alias, st.Name, field.Name, setterConv)
 		}
 	}
 
+	if a.pkg.HasData(st) {
+		a.writeDataConversion(buf, st)
+	}
+
 	// Write methods
 	for _, method := range st.Methods {
 		if method.IsVariadic {
-			if a.verbose {
-				fmt.Printf("Skipping variadic method: %s.%s\n", st.Name, method.Name)
-			}
+			a.skip(method.Pos, st.Name+"."+method.Name, core.DeclMethod, errVariadic)
 			continue
 		}
 		if err := a.writeMethod(buf, st, method); err != nil {
-			if a.verbose {
-				fmt.Printf("Skipping method %s.%s: %v\n", st.Name, method.Name, err)
-			}
+			a.skip(method.Pos, st.Name+"."+method.Name, core.DeclMethod, err)
 			continue
 		}
 	}
@@ -434,9 +740,111 @@ func %s_Set%s(h C.uintptr_t, val %s) {
 	return nil
 }
 
+// writeDataConversion writes the exports converting the data fields of a
+// struct to and from a JSON object keyed by Go field names, so that hosts
+// read or build a whole struct in one call
+func (a *Plugin) writeDataConversion(buf *bytes.Buffer, st core.ParsedStruct) {
+	prefix := a.pkg.StructPrefix(st.Name)
+	goType := a.alias + "." + st.Name
+	handle := core.ExportParam{Name: "h", Type: "C.uintptr_t", GoType: "*" + st.Name}
+	outError := core.ExportParam{Name: "outError", Type: "**C.char", GoType: "error"}
+	a.exports = append(a.exports,
+		core.Export{
+			Symbol: prefix + "_ToJSON",
+			Kind:   core.ExportConversion,
+			Source: st.Name,
+			Params: []core.ExportParam{handle, outError},
+			Result: &core.ExportParam{Type: "*C.char", GoType: "string"},
+		},
+		core.Export{
+			Symbol: prefix + "_FromJSON",
+			Kind:   core.ExportConversion,
+			Source: st.Name,
+			Params: []core.ExportParam{{Name: "data", Type: "*C.char", GoType: "string"}, outError},
+			Result: &core.ExportParam{Type: "C.uintptr_t", GoType: "*" + st.Name, Ownership: core.OwnershipOwned},
+		},
+	)
+
+	fields := a.pkg.DataFields(st)
+	fmt.Fprintf(buf, "\nfunc snapshot_%s(obj *%s) map[string]interface{} {\n", prefix, goType)
+	buf.WriteString("\treturn map[string]interface{}{\n")
+	for _, field := range fields {
+		value := "obj." + field.Name
+		if nested := a.pkg.NestedData(field.Type); nested != nil {
+			value = fmt.Sprintf("snapshot_%s(&obj.%s)", a.pkg.StructPrefix(nested.Name), field.Name)
+		} else if field.Type.Named != "" {
+			// Convert named types so their own JSON methods do not apply
+			value = fmt.Sprintf("%s(obj.%s)", field.Type.Name, field.Name)
+		}
+		fmt.Fprintf(buf, "\t\t%q: %s,\n", field.Name, value)
+	}
+	buf.WriteString("\t}\n}\n")
+
+	fmt.Fprintf(buf, "\nfunc fill_%s(obj *%s, data map[string]json.RawMessage) error {\n", prefix, goType)
+	buf.WriteString("\tfor name, raw := range data {\n")
+	buf.WriteString("\t\tvar err error\n")
+	buf.WriteString("\t\tswitch name {\n")
+	for _, field := range fields {
+		fmt.Fprintf(buf, "\t\tcase %q:\n", field.Name)
+		if nested := a.pkg.NestedData(field.Type); nested != nil {
+			buf.WriteString("\t\t\tvar fields map[string]json.RawMessage\n")
+			fmt.Fprintf(buf, "\t\t\tif err = json.Unmarshal(raw, &fields); err == nil {\n\t\t\t\terr = fill_%s(&obj.%s, fields)\n\t\t\t}\n", a.pkg.StructPrefix(nested.Name), field.Name)
+		} else if field.Type.Named != "" {
+			fmt.Fprintf(buf, "\t\t\tvar v %s\n", field.Type.Name)
+			fmt.Fprintf(buf, "\t\t\tif err = json.Unmarshal(raw, &v); err == nil {\n\t\t\t\tobj.%s = %s.%s(v)\n\t\t\t}\n", field.Name, a.alias, field.Type.Named)
+		} else {
+			fmt.Fprintf(buf, "\t\t\terr = json.Unmarshal(raw, &obj.%s)\n", field.Name)
+		}
+	}
+	buf.WriteString("\t\tdefault:\n\t\t\terr = errors.New(\"unknown field\")\n")
+	buf.WriteString("\t\t}\n")
+	buf.WriteString("\t\tif err != nil {\n\t\t\treturn fmt.Errorf(\"%s: %w\", name, err)\n\t\t}\n")
+	buf.WriteString("\t}\n\treturn nil\n}\n")
+
+	fmt.Fprintf(buf, `
+//export %[1]s_ToJSON
+func %[1]s_ToJSON(h C.uintptr_t, outError **C.char) *C.char {
+	raw, ok := getHandle(h)
+	if !ok {
+		*outError = C.CString("invalid handle")
+		return nil
+	}
+	data, err := json.Marshal(snapshot_%[1]s(raw.(*%[2]s)))
+	if err != nil {
+		*outError = C.CString(err.Error())
+		return nil
+	}
+	*outError = nil
+	return C.CString(string(data))
+}
+
+//export %[1]s_FromJSON
+func %[1]s_FromJSON(data *C.char, outError **C.char) C.uintptr_t {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(C.GoString(data)), &fields); err != nil {
+		*outError = C.CString(err.Error())
+		return 0
+	}
+	obj := &%[2]s{}
+	if err := fill_%[1]s(obj, fields); err != nil {
+		*outError = C.CString(err.Error())
+		return 0
+	}
+	*outError = nil
+	return registerHandle(obj)
+}
+`, prefix, goType)
+}
+
 // writeMethod writes a single method adapter
 func (a *Plugin) writeMethod(buf *bytes.Buffer, st core.ParsedStruct, method core.ParsedMethod) error {
-	exportName := st.Name + "_" + method.Name
+	exportName := a.pkg.StructPrefix(st.Name) + "_" + method.Name
+	export := core.Export{
+		Symbol: exportName,
+		Kind:   core.ExportMethod,
+		Source: st.Name + "." + method.Name,
+		Params: []core.ExportParam{{Name: "h", Type: "C.uintptr_t", GoType: "*" + st.Name}},
+	}
 
 	// Build parameter list (handle first, then method params)
 	cParams := []string{"h C.uintptr_t"}
@@ -446,7 +854,7 @@ func (a *Plugin) writeMethod(buf *bytes.Buffer, st core.ParsedStruct, method cor
 	var errorIndex int
 
 	for i, param := range method.Params {
-		ctype, err := a.mapper.MapType(param.Type)
+		ctype, err := a.mapper.MapParam(param.Type)
 		if err != nil {
 			return err
 		}
@@ -457,8 +865,15 @@ func (a *Plugin) writeMethod(buf *bytes.Buffer, st core.ParsedStruct, method cor
 		}
 
 		cParams = append(cParams, fmt.Sprintf("%s %s", paramName, ctype.CTypeName))
+		export.Params = append(export.Params, core.ExportParam{Name: paramName, Type: ctype.CTypeName, GoType: param.Type.DeclaredName()})
+		extra := bufferParams(paramName, param.Type)
+		cParams = append(cParams, paramList(extra)...)
+		export.Params = append(export.Params, extra...)
 
 		goArg, conv := a.generateInputConversion(paramName, param.Type, ctype)
+		if param.Borrowed && param.Type.IsBuffer() {
+			goArg, conv = a.bufferInput(paramName, param.Type, true)
+		}
 		goArgs = append(goArgs, goArg)
 		if conv != "" {
 			conversions = append(conversions, conv)
@@ -471,6 +886,7 @@ func (a *Plugin) writeMethod(buf *bytes.Buffer, st core.ParsedStruct, method cor
 			hasError = true
 			errorIndex = i
 			cParams = append(cParams, "outError **C.char")
+			export.Params = append(export.Params, core.ExportParam{Name: "outError", Type: "**C.char", GoType: "error"})
 		}
 	}
 
@@ -493,7 +909,26 @@ func (a *Plugin) writeMethod(buf *bytes.Buffer, st core.ParsedStruct, method cor
 		}
 		returnType = ctype.CTypeName
 		returnConversion = a.generateOutputConversion("result", nonErrorResults[0].Type, ctype)
+		export.Result = &core.ExportParam{Type: returnType, GoType: nonErrorResults[0].Type.DeclaredName(), Ownership: nonErrorResults[0].Ownership}
+		extra := bufferOutParams(nonErrorResults[0].Type)
+		cParams = append(cParams, paramList(extra)...)
+		export.Params = append(export.Params, extra...)
+	}
+	// A method returning its receiver returns the caller's handle, which
+	// the caller retains, instead of registering a second one
+	resultVar := "result"
+	if export.Result != nil && export.Result.Ownership == core.OwnershipBorrowed {
+		resultVar = "_"
+		returnConversion = "h"
 	}
+	outs, err := a.outParams(nonErrorResults, &export)
+	if err != nil {
+		return err
+	}
+	for _, out := range outs {
+		cParams = append(cParams, fmt.Sprintf("%s *%s", out.name, out.ctype.CTypeName))
+	}
+	a.exports = append(a.exports, export)
 
 	// Generate function body
 	fmt.Fprintf(buf, "\n//export %s\n", exportName)
@@ -515,7 +950,7 @@ func (a *Plugin) writeMethod(buf *bytes.Buffer, st core.ParsedStruct, method cor
 		buf.WriteString("\t\treturn\n")
 	}
 	buf.WriteString("\t}\n")
-	fmt.Fprintf(buf, "\tobj := raw.(*target.%s)\n", st.Name)
+	fmt.Fprintf(buf, "\tobj := raw.(*%s.%s)\n", a.alias, st.Name)
 
 	// Write conversions
 	for _, conv := range conversions {
@@ -523,9 +958,12 @@ func (a *Plugin) writeMethod(buf *bytes.Buffer, st core.ParsedStruct, method cor
 	}
 
 	// Call the method
-	if hasError {
+	if len(outs) > 0 {
+		call := fmt.Sprintf("obj.%s(%s)", method.Name, strings.Join(goArgs, ", "))
+		a.writeOutResults(buf, call, len(method.Results), hasError, errorIndex, outs)
+	} else if hasError {
 		if len(nonErrorResults) == 1 {
-			fmt.Fprintf(buf, "\tresult, err := obj.%s(%s)\n", method.Name, strings.Join(goArgs, ", "))
+			fmt.Fprintf(buf, "\t%s, err := obj.%s(%s)\n", resultVar, method.Name, strings.Join(goArgs, ", "))
 			buf.WriteString("\tif err != nil {\n")
 			buf.WriteString("\t\t*outError = C.CString(err.Error())\n")
 			fmt.Fprintf(buf, "\t\treturn %s\n", a.zeroValue(nonErrorResults[0].Type))
@@ -546,7 +984,11 @@ func (a *Plugin) writeMethod(buf *bytes.Buffer, st core.ParsedStruct, method cor
 		}
 	} else {
 		if len(nonErrorResults) == 1 {
-			fmt.Fprintf(buf, "\tresult := obj.%s(%s)\n", method.Name, strings.Join(goArgs, ", "))
+			if resultVar == "_" {
+				fmt.Fprintf(buf, "\t_ = obj.%s(%s)\n", method.Name, strings.Join(goArgs, ", "))
+			} else {
+				fmt.Fprintf(buf, "\tresult := obj.%s(%s)\n", method.Name, strings.Join(goArgs, ", "))
+			}
 			if returnConversion != "" {
 				fmt.Fprintf(buf, "\treturn %s\n", returnConversion)
 			} else {
@@ -558,28 +1000,110 @@ func (a *Plugin) writeMethod(buf *bytes.Buffer, st core.ParsedStruct, method cor
 	}
 
 	buf.WriteString("}\n")
+
+	if a.async {
+		a.writeAsync(buf, export)
+	}
+	if export.Result != nil && nonErrorResults[0].Type.Kind == core.KindIter {
+		return a.writeIterNext(buf, exportName, export.Source, nonErrorResults[0].Type)
+	}
 	return nil
 }
 
+// outParam is a C out parameter receiving one of several results
+type outParam struct {
+	name   string
+	goType core.ParsedType
+	ctype  CType
+}
+
+// outParams returns the out parameters of a function with several non-error
+// results and adds them to its export. A single result is returned directly.
+func (a *Plugin) outParams(results []core.ParsedResult, export *core.Export) ([]outParam, error) {
+	if len(results) < 2 {
+		return nil, nil
+	}
+	outs := make([]outParam, len(results))
+	for i, result := range results {
+		if result.Type.Kind == core.KindIter {
+			return nil, errIterResult
+		}
+		if result.Type.IsBuffer() {
+			return nil, errBufferResult
+		}
+		ctype, err := a.mapper.MapType(result.Type)
+		if err != nil {
+			return nil, err
+		}
+		name := result.Name
+		if name == "" {
+			name = fmt.Sprintf("out%d", i)
+		}
+		outs[i] = outParam{name: name, goType: result.Type, ctype: ctype}
+		export.Params = append(export.Params, core.ExportParam{Name: name, Type: "*" + ctype.CTypeName, GoType: result.Type.DeclaredName()})
+	}
+	return outs, nil
+}
+
+// writeOutResults writes a call returning several values and stores the
+// non-error results in the out parameters
+func (a *Plugin) writeOutResults(buf *bytes.Buffer, call string, numResults int, hasError bool, errorIndex int, outs []outParam) {
+	vars := make([]string, 0, numResults)
+	next := 0
+	for i := 0; i < numResults; i++ {
+		if hasError && i == errorIndex {
+			vars = append(vars, "err")
+			continue
+		}
+		vars = append(vars, fmt.Sprintf("result%d", next))
+		next++
+	}
+	fmt.Fprintf(buf, "\t%s := %s\n", strings.Join(vars, ", "), call)
+	if hasError {
+		buf.WriteString("\tif err != nil {\n")
+		buf.WriteString("\t\t*outError = C.CString(err.Error())\n")
+		buf.WriteString("\t\treturn\n")
+		buf.WriteString("\t}\n")
+		buf.WriteString("\t*outError = nil\n")
+	}
+	for i, out := range outs {
+		fmt.Fprintf(buf, "\t*%s = %s\n", out.name, a.generateOutputConversion(fmt.Sprintf("result%d", i), out.goType, out.ctype))
+	}
+}
+
 // generateInputConversion generates code to convert C input to Go
 func (a *Plugin) generateInputConversion(name string, pt core.ParsedType, ct CType) (string, string) {
 	switch pt.Kind {
+	case core.KindSlice, core.KindArray:
+		if pt.IsBuffer() {
+			return a.bufferInput(name, pt, false)
+		}
+		return name, ""
 	case core.KindString:
 		goVar := "go" + capitalize(name)
+		if pt.Named != "" {
+			return goVar, fmt.Sprintf("%s := %s.%s(C.GoString(%s))", goVar, a.alias, pt.Named, name)
+		}
 		return goVar, fmt.Sprintf("%s := C.GoString(%s)", goVar, name)
 	case core.KindPrimitive:
+		if pt.Named != "" {
+			return fmt.Sprintf("%s.%s(%s)", a.alias, pt.Named, name), ""
+		}
 		return fmt.Sprintf("%s(%s)", pt.Name, name), ""
 	case core.KindPointer:
 		if ct.IsHandle {
 			goVar := "go" + capitalize(name)
-			elemType := pt.ElemType.Name
-			return goVar, fmt.Sprintf("raw%s, _ := getHandle(%s); %s := raw%s.(*target.%s)", capitalize(name), name, goVar, capitalize(name), elemType)
+			return goVar, fmt.Sprintf("raw%s, _ := getHandle(%s); %s := raw%s.(*%s)", capitalize(name), name, goVar, capitalize(name), a.qualify(*pt.ElemType))
 		}
 		return name, ""
 	case core.KindStruct:
+		if pt.IsContext() {
+			return fmt.Sprintf("contextOf(%s)", name), ""
+		}
 		if ct.IsHandle {
+			// Handles hold pointers; struct values are passed as copies
 			goVar := "go" + capitalize(name)
-			return goVar, fmt.Sprintf("raw%s, _ := getHandle(%s); %s := raw%s.(*target.%s)", capitalize(name), name, goVar, capitalize(name), pt.Name)
+			return "*" + goVar, fmt.Sprintf("raw%s, _ := getHandle(%s); %s := raw%s.(*%s)", capitalize(name), name, goVar, capitalize(name), a.qualify(pt))
 		}
 		return name, ""
 	default:
@@ -591,6 +1115,9 @@ func (a *Plugin) generateInputConversion(name string, pt core.ParsedType, ct CTy
 func (a *Plugin) generateOutputConversion(expr string, pt core.ParsedType, ct CType) string {
 	switch pt.Kind {
 	case core.KindString:
+		if pt.Named != "" {
+			return fmt.Sprintf("C.CString(string(%s))", expr)
+		}
 		return fmt.Sprintf("C.CString(%s)", expr)
 	case core.KindPrimitive:
 		return fmt.Sprintf("%s(%s)", ct.CTypeName, expr)
@@ -604,6 +1131,16 @@ func (a *Plugin) generateOutputConversion(expr string, pt core.ParsedType, ct CT
 			return fmt.Sprintf("registerHandle(&%s)", expr)
 		}
 		return expr
+	case core.KindSlice, core.KindArray:
+		if pt.IsBuffer() {
+			return a.bufferOutput(expr, pt, ct)
+		}
+		return expr
+	case core.KindIter:
+		if pt.KeyType != nil {
+			return fmt.Sprintf("registerHandle(pullSeq2(%s))", expr)
+		}
+		return fmt.Sprintf("registerHandle(pullSeq(%s))", expr)
 	default:
 		return expr
 	}
@@ -611,6 +1148,9 @@ func (a *Plugin) generateOutputConversion(expr string, pt core.ParsedType, ct CT
 
 // zeroValue returns the zero value for a type in C
 func (a *Plugin) zeroValue(pt core.ParsedType) string {
+	if pt.IsBuffer() {
+		return "nil"
+	}
 	switch pt.Kind {
 	case core.KindString:
 		return "nil"
@@ -644,6 +1184,52 @@ func (a *Plugin) Build(pkg *core.ParsedPackage, inputPath string, opts *core.Bui
 	if err != nil {
 		return fmt.Errorf("generation error: %w", err)
 	}
+	if err := opts.HandleDiagnostics(a.diags); err != nil {
+		return err
+	}
+
+	libName := opts.LibraryName
+	if libName == "" {
+		libName = "lib" + pkg.Name
+	}
+
+	return a.buildLibrary(code, []string{inputPath}, libName, opts)
+}
+
+// LibraryFile returns the path of the library Build or BuildBundle writes
+// for pkgs
+func (a *Plugin) LibraryFile(pkgs []*core.ParsedPackage, opts *core.BuildOptions) (string, error) {
+	_, libExt, err := linkMode(opts.Link)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(opts.OutputDir, core.BundleLibraryName(pkgs, opts.LibraryName)+libExt), nil
+}
+
+// BuildBundle generates one CGO wrapper for several packages and compiles it
+// to a single shared library
+func (a *Plugin) BuildBundle(pkgs []*core.ParsedPackage, inputPaths []string, opts *core.BuildOptions) error {
+	if opts.Verbose {
+		fmt.Println("Generating CGO wrapper code for bundle...")
+	}
+	code, err := a.GenerateBundle(pkgs)
+	if err != nil {
+		return fmt.Errorf("generation error: %w", err)
+	}
+	if err := opts.HandleDiagnostics(a.diags); err != nil {
+		return err
+	}
+
+	return a.buildLibrary(code, inputPaths, core.BundleLibraryName(pkgs, opts.LibraryName), opts)
+}
+
+// buildLibrary writes the generated wrapper and compiles it to libName in an
+// ephemeral module requiring the modules of packageDirs
+func (a *Plugin) buildLibrary(code []byte, packageDirs []string, libName string, opts *core.BuildOptions) error {
+	buildMode, libExt, err := linkMode(opts.Link)
+	if err != nil {
+		return err
+	}
 
 	// Write generated code
 	cgoDir := filepath.Join(opts.OutputDir, "cgo_plugin")
@@ -657,21 +1243,40 @@ func (a *Plugin) Build(pkg *core.ParsedPackage, inputPath string, opts *core.Bui
 	}
 	fmt.Printf("Generated CGO wrapper: %s\n", cgoFile)
 
-	// Determine library name and extension
-	libName := opts.LibraryName
-	if libName == "" {
-		libName = "lib" + pkg.Name
-	}
-	libExt := getSharedLibExtension()
 	libFile := filepath.Join(opts.OutputDir, libName+libExt)
+	libKind := "shared library"
+	if buildMode == "c-archive" {
+		libKind = "static library"
+	}
+
+	if opts.Verbose {
+		fmt.Printf("Building %s: %s\n", libKind, libFile)
+	}
 
-	// Build shared library
+	bm, err := newBuildModule(code, packageDirs)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.RemoveAll(bm.Dir) }()
 	if opts.Verbose {
-		fmt.Printf("Building shared library: %s\n", libFile)
+		fmt.Printf("Using build module: %s (%s)\n", bm.Dir, strings.Join(bm.Flags, " "))
 	}
 
-	cmd := exec.Command("go", "build", "-buildmode=c-shared", "-o", libFile, cgoFile)
+	soname := ""
+	if buildMode == "c-shared" {
+		soname = sharedObjectName(runtime.GOOS, libFile)
+	}
+	args := append([]string{"build", "-buildmode=" + buildMode}, bm.Flags...)
+	args = append(args, goBuildFlags(opts, soname)...)
+	args = append(args, "-o", libFile, ".")
+	if opts.Verbose {
+		fmt.Printf("Running: go %s\n", strings.Join(args, " "))
+	}
+	cmd := exec.Command("go", args...)
+	cmd.Dir = bm.Dir
 	cmd.Env = append(os.Environ(), "CGO_ENABLED=1")
+	cmd.Env = append(cmd.Env, bm.Env...)
+	cmd.Env = append(cmd.Env, buildEnv(opts)...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
@@ -679,17 +1284,157 @@ func (a *Plugin) Build(pkg *core.ParsedPackage, inputPath string, opts *core.Bui
 		return fmt.Errorf("build failed: %w", err)
 	}
 
-	fmt.Printf("Built shared library: %s\n", libFile)
+	fmt.Printf("Built %s: %s\n", libKind, libFile)
 
 	// Note about header file
+	artifacts := []string{libFile}
 	headerFile := filepath.Join(opts.OutputDir, libName+".h")
 	if _, err := os.Stat(headerFile); err == nil {
 		fmt.Printf("Generated header file: %s\n", headerFile)
+		artifacts = append(artifacts, headerFile)
+	}
+
+	// Package files for C/C++ consumers, next to the library and optionally
+	// in an install tree
+	lib := &libraryFiles{
+		Name:       libName,
+		LibFile:    libFile,
+		HeaderFile: headerFile,
+		Static:     buildMode == "c-archive",
+		GOOS:       runtime.GOOS,
+		Version:    libraryVersion(packageDirs),
+		SOName:     soname,
+	}
+	layouts := []packageLayout{flatLayout(opts.OutputDir)}
+	if opts.Install {
+		layouts = append(layouts, installLayout(filepath.Join(opts.OutputDir, InstallDir), lib.packageName()))
+	}
+	for _, layout := range layouts {
+		files, err := writeLibraryPackage(layout, lib)
+		if err != nil {
+			return err
+		}
+		if opts.Verbose {
+			for _, file := range files {
+				fmt.Printf("Generated package file: %s\n", file)
+			}
+		}
+		artifacts = append(artifacts, files...)
 	}
+	if opts.Install {
+		fmt.Printf("Install tree: %s\n", filepath.Join(opts.OutputDir, InstallDir))
+	}
+
+	sumsFile, err := writeChecksums(opts.OutputDir, artifacts)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Wrote checksums: %s\n", sumsFile)
 
 	return nil
 }
 
+// goBuildFlags returns the go build flags selected in opts. A shared library
+// is linked with the given DT_SONAME ("" for none), unless the selected
+// ldflags pass their own -extldflags.
+func goBuildFlags(opts *core.BuildOptions, soname string) []string {
+	var flags []string
+	if len(opts.Tags) > 0 {
+		flags = append(flags, "-tags="+strings.Join(opts.Tags, ","))
+	}
+	if opts.TrimPath || opts.Reproducible {
+		flags = append(flags, "-trimpath")
+	}
+	if opts.Race {
+		flags = append(flags, "-race")
+	}
+
+	buildVCS := opts.BuildVCS
+	if opts.Reproducible {
+		buildVCS = "false"
+	}
+	if buildVCS != "" {
+		flags = append(flags, "-buildvcs="+buildVCS)
+	}
+
+	ldflags := opts.LDFlags
+	if opts.Reproducible {
+		ldflags = strings.TrimSpace(ldflags + " -buildid=")
+	}
+	if soname != "" && !strings.Contains(ldflags, "-extldflags") {
+		ldflags = strings.TrimSpace(ldflags + " -extldflags=-Wl,-soname," + soname)
+	}
+	if ldflags != "" {
+		flags = append(flags, "-ldflags="+ldflags)
+	}
+	return flags
+}
+
+// libraryVersion returns the version of the module of the first package
+// bound in the library, as packaged for Python, or 0.0.0 without a tag
+func libraryVersion(packageDirs []string) string {
+	if len(packageDirs) > 0 {
+		if version := core.ModuleVersion(packageDirs[0]); version != "" {
+			return version
+		}
+	}
+	return "0.0.0"
+}
+
+// buildEnv returns the cgo environment selected in opts
+func buildEnv(opts *core.BuildOptions) []string {
+	var env []string
+	if opts.CC != "" {
+		env = append(env, "CC="+opts.CC)
+	}
+	if opts.CGOCFlags != "" {
+		env = append(env, "CGO_CFLAGS="+opts.CGOCFlags)
+	}
+	if opts.CGOLDFlags != "" {
+		env = append(env, "CGO_LDFLAGS="+opts.CGOLDFlags)
+	}
+	return env
+}
+
+// ChecksumFile is the name of the SHA-256 checksum list written next to the
+// built artifacts, in the format read by sha256sum -c
+const ChecksumFile = "SHA256SUMS"
+
+// writeChecksums writes the SHA-256 checksums of files to dir/ChecksumFile
+func writeChecksums(dir string, files []string) (string, error) {
+	var sums strings.Builder
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("checksum error: %w", err)
+		}
+		sum := sha256.Sum256(data)
+		name, err := filepath.Rel(dir, file)
+		if err != nil {
+			name = file
+		}
+		fmt.Fprintf(&sums, "%s  %s\n", hex.EncodeToString(sum[:]), filepath.ToSlash(name))
+	}
+
+	sumsFile := filepath.Join(dir, ChecksumFile)
+	if err := os.WriteFile(sumsFile, []byte(sums.String()), 0644); err != nil {
+		return "", fmt.Errorf("write error: %w", err)
+	}
+	return sumsFile, nil
+}
+
+// linkMode returns the go build mode and library extension for a link mode
+func linkMode(link string) (string, string, error) {
+	switch link {
+	case "", core.LinkShared:
+		return "c-shared", getSharedLibExtension(), nil
+	case core.LinkStatic:
+		return "c-archive", ".a", nil
+	default:
+		return "", "", fmt.Errorf("unknown link mode %q (expected %s or %s)", link, core.LinkShared, core.LinkStatic)
+	}
+}
+
 // getSharedLibExtension returns the platform-specific shared library extension
 func getSharedLibExtension() string {
 	switch runtime.GOOS {
@@ -15,6 +15,10 @@
 package cgo
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
@@ -77,6 +81,13 @@ var _ = Describe("Plugin", func() {
 			Expect(codeStr).To(ContainSubstring("func main()"))
 		})
 
+		It("imports a package without exports for its side effects", func() {
+			code, err := plugin.Generate(&core.ParsedPackage{Name: "test", ImportPath: "github.com/test/test"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(code)).To(ContainSubstring("\t_ \"github.com/test/test\"\n"))
+			Expect(string(code)).NotTo(ContainSubstring("var _ = target."))
+		})
+
 		It("generates struct wrappers", func() {
 			pkg := &core.ParsedPackage{
 				Name:       "test",
@@ -283,6 +294,37 @@ var _ = Describe("Plugin", func() {
 
 			codeStr := string(code)
 			Expect(codeStr).To(ContainSubstring("test_DivMod"))
+			Expect(codeStr).To(ContainSubstring("func test_DivMod(a C.longlong, b C.longlong, out0 *C.longlong, out1 *C.longlong) {"))
+			Expect(codeStr).To(ContainSubstring("\tresult0, result1 := target.DivMod(int(a), int(b))\n\t*out0 = C.longlong(result0)\n\t*out1 = C.longlong(result1)\n"))
+		})
+
+		It("stores several method results in out parameters after the error check", func() {
+			pkg := &core.ParsedPackage{
+				Name:       "test",
+				ImportPath: "github.com/test/test",
+				Structs: []core.ParsedStruct{
+					{
+						Name: "Grid",
+						Methods: []core.ParsedMethod{
+							{
+								Name:         "Size",
+								ReceiverType: "Grid",
+								Results: []core.ParsedResult{
+									{Name: "width", Type: core.ParsedType{Kind: core.KindPrimitive, Name: "int"}},
+									{Name: "label", Type: core.ParsedType{Kind: core.KindString, Name: "string"}},
+									{Type: core.ParsedType{Kind: core.KindError, Name: "error"}},
+								},
+							},
+						},
+					},
+				},
+			}
+
+			code, err := plugin.Generate(pkg)
+			Expect(err).NotTo(HaveOccurred())
+			codeStr := string(code)
+			Expect(codeStr).To(ContainSubstring("func Grid_Size(h C.uintptr_t, outError **C.char, width *C.longlong, label **C.char) {"))
+			Expect(codeStr).To(ContainSubstring("\tresult0, result1, err := obj.Size()\n\tif err != nil {\n\t\t*outError = C.CString(err.Error())\n\t\treturn\n\t}\n\t*outError = nil\n\t*width = C.longlong(result0)\n\t*label = C.CString(result1)\n"))
 		})
 
 		It("generates handle registry code", func() {
@@ -302,6 +344,222 @@ var _ = Describe("Plugin", func() {
 			Expect(codeStr).To(ContainSubstring("registerHandle"))
 			Expect(codeStr).To(ContainSubstring("getHandle"))
 			Expect(codeStr).To(ContainSubstring("freeHandle"))
+			Expect(codeStr).To(ContainSubstring("handleRefs[uintptr(h)]++"))
+			Expect(codeStr).To(ContainSubstring("if h, ok := handleIndex[obj]; ok {"))
+			Expect(codeStr).To(ContainSubstring("delete(handleIndex, obj)"))
+			Expect(codeStr).To(ContainSubstring("//export Retain_Handle"))
+		})
+
+		It("converts struct data to and from JSON in one call", func() {
+			point := core.ParsedType{Kind: core.KindStruct, Name: "Point"}
+			pkg := &core.ParsedPackage{
+				Name:       "test",
+				ImportPath: "github.com/test/test",
+				Structs: []core.ParsedStruct{
+					{Name: "Point", Fields: []core.ParsedField{{Name: "X", Type: core.ParsedType{Kind: core.KindPrimitive, Name: "int"}, Exported: true}}},
+					{Name: "Reading", Fields: []core.ParsedField{
+						{Name: "Temp", Type: core.ParsedType{Kind: core.KindPrimitive, Name: "float64", Named: "Celsius"}, Exported: true},
+						{Name: "Pos", Type: point, Exported: true},
+						{Name: "Owner", Type: core.ParsedType{Kind: core.KindPointer, Name: "*Point", ElemType: &point}, Exported: true},
+					}},
+					{Name: "Marker"},
+				},
+			}
+
+			code, err := plugin.Generate(pkg)
+			Expect(err).NotTo(HaveOccurred())
+
+			codeStr := string(code)
+			Expect(codeStr).To(ContainSubstring("\t\t\"Temp\": float64(obj.Temp),\n\t\t\"Pos\": snapshot_Point(&obj.Pos),\n\t}"))
+			Expect(codeStr).NotTo(ContainSubstring("\"Owner\""))
+			Expect(codeStr).To(ContainSubstring("obj.Temp = target.Celsius(v)"))
+			Expect(codeStr).To(ContainSubstring("err = fill_Point(&obj.Pos, fields)"))
+			Expect(codeStr).To(ContainSubstring("//export Reading_ToJSON"))
+			Expect(codeStr).To(ContainSubstring("data, err := json.Marshal(snapshot_Reading(raw.(*target.Reading)))"))
+			Expect(codeStr).To(ContainSubstring("//export Reading_FromJSON"))
+			Expect(codeStr).NotTo(ContainSubstring("Marker_ToJSON"), "structs without data fields get no conversion")
+		})
+
+		It("passes struct values by copy", func() {
+			point := core.ParsedType{Kind: core.KindStruct, Name: "Point"}
+			pkg := &core.ParsedPackage{
+				Name:       "test",
+				ImportPath: "github.com/test/test",
+				Functions: []core.ParsedFunc{
+					{Name: "Norm", Params: []core.ParsedParam{{Name: "p", Type: point}}, Results: []core.ParsedResult{{Type: core.ParsedType{Kind: core.KindPrimitive, Name: "float64"}}}},
+				},
+				Structs: []core.ParsedStruct{{Name: "Point"}},
+			}
+
+			code, err := plugin.Generate(pkg)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(code)).To(ContainSubstring("result := target.Norm(*goP)"))
+		})
+
+		It("returns the receiver's handle for borrowed results", func() {
+			point := core.ParsedType{Kind: core.KindStruct, Name: "Point"}
+			pointPtr := core.ParsedType{Kind: core.KindPointer, Name: "*Point", ElemType: &point}
+			pkg := &core.ParsedPackage{
+				Name:       "test",
+				ImportPath: "github.com/test/test",
+				Structs: []core.ParsedStruct{{
+					Name: "Point",
+					Methods: []core.ParsedMethod{
+						{Name: "Scale", ReceiverType: "Point", ReceiverIsPtr: true, Results: []core.ParsedResult{{Type: pointPtr, Ownership: core.OwnershipBorrowed}}},
+						{Name: "Clone", ReceiverType: "Point", ReceiverIsPtr: true, Results: []core.ParsedResult{{Type: pointPtr, Ownership: core.OwnershipOwned}}},
+					},
+				}},
+			}
+
+			code, err := plugin.Generate(pkg)
+			Expect(err).NotTo(HaveOccurred())
+
+			codeStr := string(code)
+			Expect(codeStr).To(ContainSubstring("\t_ = obj.Scale()\n\treturn h\n"))
+			Expect(codeStr).To(ContainSubstring("result := obj.Clone()\n\treturn registerHandle(result)"))
+		})
+
+		It("pulls returned iterators through handles", func() {
+			intType := core.ParsedType{Kind: core.KindPrimitive, Name: "int"}
+			point := core.ParsedType{Kind: core.KindStruct, Name: "Point"}
+			pointPtr := core.ParsedType{Kind: core.KindPointer, Name: "*Point", ElemType: &point}
+			pkg := &core.ParsedPackage{
+				Name:       "test",
+				ImportPath: "github.com/test/test",
+				Functions: []core.ParsedFunc{
+					{Name: "Count", Results: []core.ParsedResult{{Type: core.ParsedType{Kind: core.KindIter, Name: "iter.Seq[int]", ElemType: &intType}}}},
+					{Name: "Indexed", Results: []core.ParsedResult{{Type: core.ParsedType{Kind: core.KindIter, Name: "iter.Seq2[int, *Point]", KeyType: &intType, ElemType: &pointPtr}}}},
+					{Name: "Both", Results: []core.ParsedResult{{Type: core.ParsedType{Kind: core.KindIter, Name: "iter.Seq[int]", ElemType: &intType}}, {Type: intType}}},
+				},
+				Structs: []core.ParsedStruct{{Name: "Point"}},
+			}
+
+			code, err := plugin.Generate(pkg)
+			Expect(err).NotTo(HaveOccurred())
+
+			codeStr := string(code)
+			Expect(codeStr).To(ContainSubstring("\t\"iter\"\n"))
+			Expect(codeStr).To(ContainSubstring("//export Iter_Free"))
+			Expect(codeStr).To(ContainSubstring("//export Iter_Stop"))
+			Expect(codeStr).To(ContainSubstring("return registerHandle(pullSeq(result))"))
+			Expect(codeStr).To(ContainSubstring("return registerHandle(pullSeq2(result))"))
+			Expect(codeStr).To(ContainSubstring("func test_Count_Next(it C.uintptr_t, outValue *C.longlong) C.bool {"))
+			Expect(codeStr).To(ContainSubstring("p, ok := raw.(*pulled[pair[int, *target.Point]])"))
+			Expect(codeStr).To(ContainSubstring("*outKey = C.longlong(e.key)\n\t*outValue = registerHandle(e.value)\n"))
+			Expect(codeStr).NotTo(ContainSubstring("test_Both"))
+			Expect(plugin.Diagnostics()).To(HaveLen(1))
+			Expect(plugin.Diagnostics()[0].Reason).To(Equal("iterators are only supported as the only non-error result"))
+		})
+
+		It("passes numeric slices and arrays as buffers", func() {
+			float64Type := core.ParsedType{Kind: core.KindPrimitive, Name: "float64"}
+			float32Type := core.ParsedType{Kind: core.KindPrimitive, Name: "float32"}
+			floats := core.ParsedType{Kind: core.KindSlice, Name: "[]float64", ElemType: &float64Type}
+			row := core.ParsedType{Kind: core.KindSlice, Name: "[]float32", ElemType: &float32Type}
+			rows := core.ParsedType{Kind: core.KindSlice, Name: "[][]float32", ElemType: &row}
+			triple := core.ParsedType{Kind: core.KindArray, Name: "[3]float64", ElemType: &float64Type, Size: 3}
+			pkg := &core.ParsedPackage{
+				Name:       "test",
+				ImportPath: "github.com/test/test",
+				Functions: []core.ParsedFunc{
+					{Name: "Sum", Params: []core.ParsedParam{{Name: "xs", Type: floats, Borrowed: true}}, Results: []core.ParsedResult{{Type: float64Type}}},
+					{Name: "Keep", Params: []core.ParsedParam{{Name: "xs", Type: floats}}},
+					{Name: "RowSums", Params: []core.ParsedParam{{Name: "m", Type: rows, Borrowed: true}}, Results: []core.ParsedResult{{Type: row}}},
+					{Name: "Grid", Results: []core.ParsedResult{{Type: rows}}},
+					{Name: "Triple", Params: []core.ParsedParam{{Name: "v", Type: triple}}, Results: []core.ParsedResult{{Type: triple}}},
+					{Name: "Split", Results: []core.ParsedResult{{Type: floats}, {Type: floats}}},
+				},
+				Structs: []core.ParsedStruct{{Name: "Series", Fields: []core.ParsedField{{Name: "Values", Type: floats, Exported: true}}}},
+			}
+
+			code, err := plugin.Generate(pkg)
+			Expect(err).NotTo(HaveOccurred())
+
+			codeStr := string(code)
+			Expect(codeStr).To(ContainSubstring("func viewBuffer[T any](data unsafe.Pointer, n C.longlong) []T {"))
+			Expect(codeStr).To(ContainSubstring("func test_Sum(xs *C.double, xsLen C.longlong) C.double {\n\tgoXs := viewBuffer[float64](unsafe.Pointer(xs), xsLen)\n"))
+			Expect(codeStr).To(ContainSubstring("goXs := copyBuffer[float64](unsafe.Pointer(xs), xsLen)"))
+			Expect(codeStr).To(ContainSubstring("func test_RowSums(m *C.float, mLens *C.longlong, mRows C.longlong, outLen *C.longlong) *C.float {"))
+			Expect(codeStr).To(ContainSubstring("goM := rowsBuffer[float32](unsafe.Pointer(m), mLens, mRows)"))
+			Expect(codeStr).To(ContainSubstring("return (*C.float)(exportBuffer(result, outLen))"))
+			Expect(codeStr).To(ContainSubstring("return (*C.float)(exportRows(result, outLens, outRows))"))
+			Expect(codeStr).To(ContainSubstring("result := target.Triple(*(*[3]float64)(unsafe.Pointer(v)))\n\treturn (*C.double)(exportBuffer(result[:], nil))"))
+			Expect(codeStr).To(ContainSubstring("func Series_GetValues(h C.uintptr_t, outLen *C.longlong) *C.double {"))
+			Expect(codeStr).NotTo(ContainSubstring("test_Split"))
+			Expect(plugin.Diagnostics()).To(HaveLen(1))
+			Expect(plugin.Diagnostics()[0].Reason).To(Equal("slices and arrays of numbers are only supported as the only non-error result"))
+		})
+
+		It("passes byte slices and arrays as buffers", func() {
+			byteType := core.ParsedType{Kind: core.KindPrimitive, Name: "byte"}
+			raw := core.ParsedType{Kind: core.KindSlice, Name: "[]byte", ElemType: &byteType}
+			digest := core.ParsedType{Kind: core.KindArray, Name: "[4]byte", ElemType: &byteType, Size: 4}
+			pkg := &core.ParsedPackage{
+				Name:       "test",
+				ImportPath: "github.com/test/test",
+				Functions: []core.ParsedFunc{
+					{Name: "Echo", Params: []core.ParsedParam{{Name: "b", Type: raw}}, Results: []core.ParsedResult{{Type: raw}}},
+					{Name: "Hash", Results: []core.ParsedResult{{Type: digest}}},
+				},
+			}
+
+			code, err := plugin.Generate(pkg)
+			Expect(err).NotTo(HaveOccurred())
+
+			codeStr := string(code)
+			Expect(codeStr).To(ContainSubstring("func test_Echo(b *C.uint8_t, bLen C.longlong, outLen *C.longlong) *C.uint8_t {\n\tgoB := copyBuffer[byte](unsafe.Pointer(b), bLen)\n"))
+			Expect(codeStr).To(ContainSubstring("return (*C.uint8_t)(exportBuffer(result, outLen))"))
+			Expect(codeStr).To(ContainSubstring("func test_Hash() *C.uint8_t {"))
+			Expect(plugin.Diagnostics()).To(BeEmpty())
+		})
+
+		It("passes contexts as handles and exports async variants", func() {
+			ctx := core.ParsedType{Kind: core.KindStruct, Name: "Context", PackagePath: "context"}
+			str := core.ParsedType{Kind: core.KindString, Name: "string"}
+			pkg := &core.ParsedPackage{
+				Name:       "test",
+				ImportPath: "github.com/test/test",
+				Functions: []core.ParsedFunc{
+					{Name: "Fetch", Params: []core.ParsedParam{{Name: "ctx", Type: ctx}, {Name: "url", Type: str}}, Results: []core.ParsedResult{{Type: str}, {Type: core.ParsedType{Kind: core.KindError, Name: "error"}}}},
+				},
+				Structs: []core.ParsedStruct{{Name: "Client", Methods: []core.ParsedMethod{{Name: "Close", ReceiverType: "Client", ReceiverIsPtr: true}}}},
+			}
+
+			code, err := plugin.Generate(pkg)
+			Expect(err).NotTo(HaveOccurred())
+			codeStr := string(code)
+			Expect(codeStr).To(ContainSubstring("\t\"context\"\n"))
+			Expect(codeStr).To(ContainSubstring("//export Context_Cancel"))
+			Expect(codeStr).To(ContainSubstring("func test_Fetch(ctx C.uintptr_t, url *C.char, outError **C.char) *C.char {"))
+			Expect(codeStr).To(ContainSubstring("target.Fetch(contextOf(ctx), goUrl)"))
+			Expect(codeStr).NotTo(ContainSubstring("_Async"))
+
+			Expect(core.ApplyOptions(plugin, map[string]string{"async": "true"})).To(Succeed())
+			code, err = plugin.Generate(pkg)
+			Expect(err).NotTo(HaveOccurred())
+			codeStr = string(code)
+			Expect(codeStr).To(ContainSubstring("typedef void (*goanywhere_done)(uintptr_t);"))
+			Expect(codeStr).To(ContainSubstring("func test_Fetch_Async(ctx C.uintptr_t, url *C.char, outError **C.char, outResult **C.char, onDone C.goanywhere_done, callID C.uintptr_t) {\n" +
+				"\tgo func() {\n\t\tdefer C.goanywhere_complete(onDone, callID)\n\t\t*outResult = test_Fetch(ctx, url, outError)\n\t}()\n}\n"))
+			Expect(codeStr).To(ContainSubstring("func Client_Close_Async(h C.uintptr_t, onDone C.goanywhere_done, callID C.uintptr_t) {"))
+			Expect(codeStr).To(ContainSubstring("\t\tClient_Close(h)\n"))
+
+			exports, err := plugin.Exports(pkg)
+			Expect(err).NotTo(HaveOccurred())
+			var async []string
+			for _, export := range exports {
+				if export.Kind == core.ExportAsync {
+					async = append(async, export.Symbol)
+				}
+			}
+			Expect(async).To(Equal([]string{"test_Fetch_Async", "Client_Close_Async"}))
+		})
+
+		It("imports iter only for packages returning iterators", func() {
+			code, err := plugin.Generate(&core.ParsedPackage{Name: "test", ImportPath: "github.com/test/test"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(code)).NotTo(ContainSubstring("\"iter\""))
+			Expect(string(code)).NotTo(ContainSubstring("Iter_Free"))
 		})
 
 		It("generates free functions", func() {
@@ -383,6 +641,232 @@ var _ = Describe("Plugin", func() {
 			codeStr := string(code)
 			Expect(codeStr).To(ContainSubstring("Calculator_Add"))
 		})
+
+		It("applies the export prefix to functions and structs", func() {
+			pkg := &core.ParsedPackage{
+				Name:         "test",
+				ImportPath:   "github.com/test/test",
+				ExportPrefix: "tst",
+				Functions:    []core.ParsedFunc{{Name: "Ping"}},
+				Structs: []core.ParsedStruct{{
+					Name:    "Conn",
+					Methods: []core.ParsedMethod{{Name: "Close", ReceiverIsPtr: true}},
+				}},
+			}
+
+			code, err := plugin.Generate(pkg)
+			Expect(err).NotTo(HaveOccurred())
+
+			codeStr := string(code)
+			Expect(codeStr).To(ContainSubstring("//export tst_Ping"))
+			Expect(codeStr).To(ContainSubstring("//export tst_Conn_New"))
+			Expect(codeStr).To(ContainSubstring("//export tst_Conn_Close"))
+			Expect(codeStr).NotTo(ContainSubstring("test_Ping"))
+		})
+
+		It("converts overridden named types", func() {
+			pkg := &core.ParsedPackage{
+				Name:       "test",
+				ImportPath: "github.com/test/test",
+				Functions: []core.ParsedFunc{{
+					Name: "Warm",
+					Params: []core.ParsedParam{
+						{Name: "c", Type: core.ParsedType{Kind: core.KindPrimitive, Name: "float64", Named: "Celsius"}},
+						{Name: "l", Type: core.ParsedType{Kind: core.KindString, Name: "string", Named: "Label"}},
+					},
+					Results: []core.ParsedResult{
+						{Type: core.ParsedType{Kind: core.KindString, Name: "string", Named: "Label"}},
+					},
+				}},
+			}
+
+			code, err := plugin.Generate(pkg)
+			Expect(err).NotTo(HaveOccurred())
+
+			codeStr := string(code)
+			Expect(codeStr).To(ContainSubstring("target.Celsius(c)"))
+			Expect(codeStr).To(ContainSubstring("target.Label(C.GoString(l))"))
+			Expect(codeStr).To(ContainSubstring("C.CString(string(result))"))
+		})
+	})
+
+	Describe("GenerateBundle", func() {
+		bundle := func() []*core.ParsedPackage {
+			point := core.ParsedType{Kind: core.KindStruct, Name: "Point", PackagePath: "github.com/test/geo"}
+			return []*core.ParsedPackage{
+				{
+					Name:       "geo",
+					ImportPath: "github.com/test/geo",
+					Structs:    []core.ParsedStruct{{Name: "Point"}},
+				},
+				{
+					Name:       "shapes",
+					ImportPath: "github.com/test/shapes",
+					Functions: []core.ParsedFunc{{
+						Name:    "Shift",
+						Params:  []core.ParsedParam{{Name: "p", Type: core.ParsedType{Kind: core.KindPointer, Name: "*geo.Point", ElemType: &point}}},
+						Results: []core.ParsedResult{{Type: core.ParsedType{Kind: core.KindPointer, Name: "*geo.Point", ElemType: &point}}},
+					}},
+				},
+			}
+		}
+
+		It("imports every package under its own alias", func() {
+			code, err := plugin.GenerateBundle(bundle())
+			Expect(err).NotTo(HaveOccurred())
+
+			codeStr := string(code)
+			Expect(codeStr).To(ContainSubstring(`target_geo "github.com/test/geo"`))
+			Expect(codeStr).To(ContainSubstring(`target_shapes "github.com/test/shapes"`))
+			Expect(strings.Count(codeStr, "func registerHandle")).To(Equal(1))
+			Expect(strings.Count(codeStr, "func main()")).To(Equal(1))
+		})
+
+		It("prefixes exports with the package name", func() {
+			code, err := plugin.GenerateBundle(bundle())
+			Expect(err).NotTo(HaveOccurred())
+
+			codeStr := string(code)
+			Expect(codeStr).To(ContainSubstring("//export geo_Point_New"))
+			Expect(codeStr).To(ContainSubstring("//export shapes_Shift"))
+		})
+
+		It("passes handles across packages", func() {
+			code, err := plugin.GenerateBundle(bundle())
+			Expect(err).NotTo(HaveOccurred())
+
+			codeStr := string(code)
+			Expect(codeStr).To(ContainSubstring("goP := rawP.(*target_geo.Point)"))
+			Expect(codeStr).To(ContainSubstring("result := target_shapes.Shift(goP)"))
+			Expect(codeStr).To(ContainSubstring("return registerHandle(result)"))
+		})
+
+		It("rejects packages with the same name", func() {
+			pkgs := bundle()
+			pkgs[1].Name = "geo"
+			_, err := plugin.GenerateBundle(pkgs)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Generate with imported types", func() {
+		It("skips functions using types from other packages", func() {
+			pkg := &core.ParsedPackage{
+				Name:       "test",
+				ImportPath: "github.com/test/test",
+				Functions: []core.ParsedFunc{{
+					Name:   "Stamp",
+					Params: []core.ParsedParam{{Name: "t", Type: core.ParsedType{Kind: core.KindStruct, Name: "Time", PackagePath: "time"}}},
+				}},
+			}
+
+			code, err := plugin.Generate(pkg)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(code)).NotTo(ContainSubstring("test_Stamp"))
+		})
+	})
+
+	Describe("Exports", func() {
+		It("describes the generated symbols", func() {
+			pkg := &core.ParsedPackage{
+				Name: "geo",
+				Functions: []core.ParsedFunc{
+					{
+						Name:   "Div",
+						Params: []core.ParsedParam{{Name: "a", Type: core.ParsedType{Kind: core.KindPrimitive, Name: "float64"}}},
+						Results: []core.ParsedResult{
+							{Type: core.ParsedType{Kind: core.KindPrimitive, Name: "float64"}},
+							{Type: core.ParsedType{Kind: core.KindError, Name: "error"}},
+						},
+					},
+				},
+				Structs: []core.ParsedStruct{
+					{
+						Name:   "Point",
+						Fields: []core.ParsedField{{Name: "Label", Type: core.ParsedType{Kind: core.KindString, Name: "string"}, Exported: true}},
+						Methods: []core.ParsedMethod{
+							{Name: "Reset", ReceiverType: "Point", ReceiverIsPtr: true},
+						},
+					},
+				},
+			}
+
+			exports, err := plugin.Exports(pkg)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exports).To(HaveLen(11))
+			Expect(exports[3]).To(Equal(core.Export{
+				Symbol: "geo_Div",
+				Kind:   core.ExportFunction,
+				Source: "Div",
+				Params: []core.ExportParam{
+					{Name: "a", Type: "C.double", GoType: "float64"},
+					{Name: "outError", Type: "**C.char", GoType: "error"},
+				},
+				Result: &core.ExportParam{Type: "C.double", GoType: "float64"},
+			}))
+
+			handle := core.ExportParam{Name: "h", Type: "C.uintptr_t", GoType: "*Point"}
+			Expect(exports[4].Symbol).To(Equal("Point_New"))
+			Expect(exports[5].Symbol).To(Equal("Point_Free"))
+			Expect(exports[6]).To(Equal(core.Export{
+				Symbol: "Point_GetLabel",
+				Kind:   core.ExportGetter,
+				Source: "Point.Label",
+				Params: []core.ExportParam{handle},
+				Result: &core.ExportParam{Type: "*C.char", GoType: "string"},
+			}))
+			Expect(exports[7].Params).To(Equal([]core.ExportParam{handle, {Name: "val", Type: "*C.char", GoType: "string"}}))
+			Expect(exports[8].Symbol).To(Equal("Point_ToJSON"))
+			Expect(exports[9].Result).To(Equal(&core.ExportParam{Type: "C.uintptr_t", GoType: "*Point", Ownership: core.OwnershipOwned}))
+			Expect(exports[10]).To(Equal(core.Export{
+				Symbol: "Point_Reset",
+				Kind:   core.ExportMethod,
+				Source: "Point.Reset",
+				Params: []core.ExportParam{handle},
+			}))
+		})
+	})
+
+	Describe("ReportBindings", func() {
+		It("describes the bindings and diagnostics", func() {
+			intType := core.ParsedType{Kind: core.KindPrimitive, Name: "int"}
+			pkg := &core.ParsedPackage{
+				Name: "geo",
+				Functions: []core.ParsedFunc{
+					{Name: "Add", Params: []core.ParsedParam{{Name: "a", Type: intType}}, Results: []core.ParsedResult{{Type: intType}}},
+					{Name: "Sum", Params: []core.ParsedParam{{Name: "xs", Type: core.ParsedType{Kind: core.KindSlice, Name: "[]int", ElemType: &intType}}}, IsVariadic: true},
+				},
+				Structs: []core.ParsedStruct{
+					{
+						Name:    "Point",
+						Methods: []core.ParsedMethod{{Name: "Sum", ReceiverType: "Point", IsVariadic: true}},
+					},
+				},
+			}
+
+			report, err := plugin.ReportBindings(pkg)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Bindings).To(Equal([]core.Binding{
+				{Decl: core.Decl{Symbol: "Add", Kind: core.DeclFunction}, Name: "geo_Add", Signature: "long long geo_Add(long long a);"},
+				{Decl: core.Decl{Symbol: "Point", Kind: core.DeclStruct}, Name: "Point_New", Signature: "uintptr_t Point_New(void);"},
+				{Decl: core.Decl{Symbol: "Point", Kind: core.DeclStruct}, Name: "Point_Free", Signature: "void Point_Free(uintptr_t h);"},
+			}))
+			Expect(report.Diagnostics).To(Equal([]core.Diagnostic{
+				{Severity: core.SeverityWarning, Decl: core.Decl{Symbol: "Sum", Kind: core.DeclFunction}, Reason: "variadic parameters are not supported"},
+				{Severity: core.SeverityWarning, Decl: core.Decl{Symbol: "Point.Sum", Kind: core.DeclMethod}, Reason: "variadic parameters are not supported"},
+			}))
+		})
+	})
+
+	Describe("cDecl", func() {
+		It("spells lowered types as in the C header", func() {
+			Expect(cDecl("C.longlong")).To(Equal("long long"))
+			Expect(cDecl("C.ulonglong")).To(Equal("unsigned long long"))
+			Expect(cDecl("**C.char")).To(Equal("char**"))
+			Expect(cDecl("*C.int32_t")).To(Equal("int32_t*"))
+			Expect(cDecl("C.bool")).To(Equal("_Bool"))
+			Expect(cDecl("unsafe.Pointer")).To(Equal("void*"))
+		})
 	})
 
 	Describe("capitalize", func() {
@@ -554,4 +1038,82 @@ var _ = Describe("Plugin", func() {
 			Expect(ext).To(BeElementOf(".so", ".dylib", ".dll"))
 		})
 	})
+
+	Describe("goBuildFlags", func() {
+		It("returns no flags by default", func() {
+			Expect(goBuildFlags(&core.BuildOptions{}, "")).To(BeEmpty())
+		})
+
+		It("passes through the selected flags", func() {
+			flags := goBuildFlags(&core.BuildOptions{
+				Tags:     []string{"netgo", "osusergo"},
+				LDFlags:  "-s -w",
+				TrimPath: true,
+				Race:     true,
+				BuildVCS: "false",
+			}, "")
+			Expect(flags).To(Equal([]string{"-tags=netgo,osusergo", "-trimpath", "-race", "-buildvcs=false", "-ldflags=-s -w"}))
+		})
+
+		It("fixes the build ID in reproducible mode", func() {
+			flags := goBuildFlags(&core.BuildOptions{Reproducible: true, LDFlags: "-s", BuildVCS: "true"}, "")
+			Expect(flags).To(Equal([]string{"-trimpath", "-buildvcs=false", "-ldflags=-s -buildid="}))
+		})
+
+		It("links shared libraries with a soname unless ldflags pass -extldflags", func() {
+			Expect(goBuildFlags(&core.BuildOptions{LDFlags: "-s"}, "libfoo.so")).To(Equal([]string{"-ldflags=-s -extldflags=-Wl,-soname,libfoo.so"}))
+			Expect(goBuildFlags(&core.BuildOptions{LDFlags: "-extldflags=-static"}, "libfoo.so")).To(Equal([]string{"-ldflags=-extldflags=-static"}))
+		})
+	})
+
+	Describe("buildEnv", func() {
+		It("sets the cgo environment", func() {
+			env := buildEnv(&core.BuildOptions{CC: "clang", CGOCFlags: "-O2", CGOLDFlags: "-lm"})
+			Expect(env).To(Equal([]string{"CC=clang", "CGO_CFLAGS=-O2", "CGO_LDFLAGS=-lm"}))
+			Expect(buildEnv(&core.BuildOptions{})).To(BeEmpty())
+		})
+	})
+
+	Describe("writeChecksums", func() {
+		It("writes sha256sum compatible checksums", func() {
+			tmpDir, err := os.MkdirTemp("", "checksums")
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { _ = os.RemoveAll(tmpDir) }()
+
+			libFile := filepath.Join(tmpDir, "libfoo.so")
+			Expect(os.WriteFile(libFile, []byte("hello\n"), 0644)).To(Succeed())
+
+			sumsFile, err := writeChecksums(tmpDir, []string{libFile})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sumsFile).To(Equal(filepath.Join(tmpDir, ChecksumFile)))
+
+			data, err := os.ReadFile(sumsFile)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal("5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03  libfoo.so\n"))
+		})
+
+		It("returns error for missing artifacts", func() {
+			_, err := writeChecksums(os.TempDir(), []string{"/nonexistent/libfoo.so"})
+			Expect(err).To(MatchError(ContainSubstring("checksum error")))
+		})
+	})
+
+	Describe("linkMode", func() {
+		It("maps link modes to go build modes", func() {
+			mode, ext, err := linkMode("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mode).To(Equal("c-shared"))
+			Expect(ext).To(Equal(getSharedLibExtension()))
+
+			mode, ext, err = linkMode(core.LinkStatic)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mode).To(Equal("c-archive"))
+			Expect(ext).To(Equal(".a"))
+		})
+
+		It("rejects unknown link modes", func() {
+			_, _, err := linkMode("dynamic")
+			Expect(err).To(MatchError(ContainSubstring(`unknown link mode "dynamic"`)))
+		})
+	})
 })
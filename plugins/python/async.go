@@ -0,0 +1,208 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package python
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/riceriley59/goanywhere/internal/core"
+)
+
+// MapParam converts the type of a parameter, which may also be a
+// context.Context. Contexts are not part of the Python signature: sync
+// wrappers pass context.Background and async wrappers a cancellable context.
+func (m *TypeMapper) MapParam(pt core.ParsedType) (PyType, error) {
+	if pt.IsContext() {
+		return PyType{CtypesType: "c_size_t", PyType: "int"}, nil
+	}
+	return m.MapType(pt)
+}
+
+// restype returns the ctypes type of a returned value
+func restype(pyType PyType) string {
+	if pyType.CtypesReturnType != "" {
+		return pyType.CtypesReturnType
+	}
+	return pyType.CtypesType
+}
+
+// writeAsyncSetup writes argtypes/restype for the _Async export of symbol,
+// whose sync export takes argtypes and returns result ("" for none)
+func writeAsyncSetup(buf *bytes.Buffer, symbol string, argtypes []string, result string) {
+	argtypes = append([]string(nil), argtypes...)
+	if result != "" {
+		argtypes = append(argtypes, "POINTER("+result+")")
+	}
+	argtypes = append(argtypes, "_AsyncDone", "c_size_t")
+	fmt.Fprintf(buf, "    lib.%s_Async.argtypes = [%s]\n", symbol, strings.Join(argtypes, ", "))
+	fmt.Fprintf(buf, "    lib.%s_Async.restype = None\n", symbol)
+}
+
+// writeContextSetup writes argtypes/restype for the exports managing the
+// contexts of async calls
+func writeContextSetup(buf *bytes.Buffer) {
+	buf.WriteString("    lib.Context_New.argtypes = []\n")
+	buf.WriteString("    lib.Context_New.restype = c_size_t\n")
+	buf.WriteString("    lib.Context_Cancel.argtypes = [c_size_t]\n")
+	buf.WriteString("    lib.Context_Cancel.restype = None\n")
+	buf.WriteString("    lib.Context_Free.argtypes = [c_size_t]\n")
+	buf.WriteString("    lib.Context_Free.restype = None\n")
+}
+
+// writeAsyncHelpers writes the bridge between the _Async exports and
+// asyncio: Go calls back on one of its threads when a call returns, and the
+// callback completes a future on the loop that started the call
+func (a *Plugin) writeAsyncHelpers(buf *bytes.Buffer) {
+	buf.WriteString(`_AsyncDone = ctypes.CFUNCTYPE(None, c_size_t)
+
+class _AsyncCall:
+    """A call running on a goroutine, keeping its arguments alive until done."""
+    __slots__ = ("loop", "future", "start", "finish", "ctx", "keep")
+
+    def __init__(self, loop: Any, start: Any, finish: Any, ctx: int, keep: Tuple[Any, ...]) -> None:
+        self.loop = loop
+        self.future = loop.create_future()
+        self.start = start
+        self.finish = finish
+        self.ctx = ctx
+        self.keep = keep
+
+# Running calls by call ID
+_async_calls: Dict[int, _AsyncCall] = {}
+
+def _async_release(state: _AsyncCall) -> None:
+    if state.ctx:
+        get_library().Context_Free(state.ctx)
+
+def _async_discard(finish: Any) -> None:
+    """Convert and drop the result of a cancelled call, releasing it."""
+    try:
+        finish()
+    except Exception:
+        pass
+
+def _async_resolve(call: int) -> None:
+    state = _async_calls.pop(call)
+    _async_release(state)
+    if state.future.cancelled():
+        _async_discard(state.finish)
+    else:
+        state.future.set_result(None)
+
+@_AsyncDone
+def _async_done(call: int) -> None:
+    """Called by Go on one of its threads once a call returns."""
+    state = _async_calls.get(call)
+    if state is None:
+        return
+    try:
+        state.loop.call_soon_threadsafe(_async_resolve, call)
+    except RuntimeError:
+        # The event loop is closed, so nobody awaits the result
+        _async_calls.pop(call, None)
+        _async_release(state)
+
+async def _go_call(start: Any, finish: Any, cancellable: bool, *keep: Any) -> Any:
+    """Run a Go call on a goroutine and return finish() once it is done.
+
+    start(ctx, call) starts the call; keep holds the buffers it passes by
+    address. Cancelling the awaiting task cancels the context passed to Go
+    functions taking one; the Go call still runs to completion, and its
+    result is then released.
+    """
+    lib = get_library()
+    state = _AsyncCall(asyncio.get_running_loop(), start, finish, lib.Context_New() if cancellable else 0, keep)
+    call = id(state)
+    _async_calls[call] = state
+    try:
+        start(state.ctx, call)
+    except BaseException:
+        del _async_calls[call]
+        _async_release(state)
+        raise
+    try:
+        await state.future
+    except asyncio.CancelledError:
+        if call in _async_calls:
+            if state.ctx:
+                lib.Context_Cancel(state.ctx)
+        elif not state.future.cancelled():
+            _async_discard(finish)
+        raise
+    return finish()
+
+`)
+}
+
+// asyncImport returns the import of asyncio, for modules with async wrappers
+func (a *Plugin) asyncImport() string {
+	if a.async {
+		return "import asyncio\n"
+	}
+	return ""
+}
+
+// asyncKeyword returns the keyword preceding def in async wrappers
+func asyncKeyword(async bool) string {
+	if async {
+		return "async "
+	}
+	return ""
+}
+
+// contextArg returns the context handle a wrapper passes to a context.Context
+// parameter: 0 for context.Background, or the cancellable context of an
+// async call
+func contextArg(async bool) string {
+	if async {
+		return "_ctx"
+	}
+	return "0"
+}
+
+// bufferOwners returns the locals holding the numbers of buffer parameters,
+// which are passed to the export by address
+func bufferOwners(params []paramInfo) []string {
+	var owners []string
+	for _, p := range params {
+		if p.goType.IsBuffer() {
+			owners = append(owners, "_"+p.name)
+		}
+	}
+	return owners
+}
+
+// writeAwait writes the end of an async wrapper, starting the _Async export
+// of call and awaiting _finish
+func (a *Plugin) writeAwait(buf *bytes.Buffer, indent string, call wrapperCall) {
+	args := append([]string(nil), call.args...)
+	if call.result != nil {
+		args = append(args, "byref(_out)")
+	}
+	args = append(args, "_async_done", "_call")
+
+	goArgs := []string{
+		fmt.Sprintf("lambda _ctx, _call: lib.%s_Async(%s)", call.symbol, strings.Join(args, ", ")),
+		"_finish",
+		"False",
+	}
+	if call.cancellable {
+		goArgs[2] = "True"
+	}
+	goArgs = append(goArgs, call.keep...)
+	fmt.Fprintf(buf, "%sreturn await _go_call(%s)\n", indent, strings.Join(goArgs, ", "))
+}
@@ -0,0 +1,230 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package python
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/riceriley59/goanywhere/internal/core"
+)
+
+// bufferType is the type of numbers passed in buffers: any buffer or
+// sequence of numbers in, NumPy arrays or lists out
+var bufferType = PyType{CtypesType: "c_void_p", PyType: "Any", NeedsFree: true}
+
+// dtype returns the NumPy dtype of a Go number type
+func dtype(elem core.ParsedType) string {
+	switch elem.Name {
+	case "int":
+		return "int64"
+	case "uint":
+		return "uint64"
+	case "rune":
+		return "int32"
+	case "byte":
+		return "uint8"
+	}
+	return elem.Name
+}
+
+// bufferArgtypes returns the ctypes of the parameters following the pointer
+// of a buffer parameter
+func bufferArgtypes(pt core.ParsedType) []string {
+	switch {
+	case pt.IsRows():
+		return []string{"c_void_p", "c_longlong"}
+	case pt.Kind == core.KindSlice:
+		return []string{"c_longlong"}
+	}
+	return nil
+}
+
+// bufferOutArgtypes returns the ctypes of the out parameters receiving the
+// length of a buffer result
+func bufferOutArgtypes(pt core.ParsedType) []string {
+	switch {
+	case pt.IsRows():
+		return []string{"POINTER(c_void_p)", "POINTER(c_longlong)"}
+	case pt.Kind == core.KindSlice:
+		return []string{"POINTER(c_longlong)"}
+	}
+	return nil
+}
+
+// writeBufferArg writes the conversion of the buffer parameter name and
+// returns the arguments passing it
+func writeBufferArg(buf *bytes.Buffer, indent, name string, pt core.ParsedType) []string {
+	v := "_" + name
+	if pt.IsRows() {
+		fmt.Fprintf(buf, "%s%s, %s_ptr, %s_lens, %s_rows = _rows_arg(%s, %q)\n", indent, v, v, v, v, name, dtype(pt.BufferElem()))
+		return []string{v + "_ptr", v + "_lens", v + "_rows"}
+	}
+	fmt.Fprintf(buf, "%s%s, %s_ptr, %s_len = _buffer_arg(%s, %q)\n", indent, v, v, v, name, dtype(pt.BufferElem()))
+	if pt.Kind == core.KindArray {
+		fmt.Fprintf(buf, "%sif %s_len != %d:\n", indent, v, pt.Size)
+		fmt.Fprintf(buf, "%s    raise ValueError(f\"%s must have %d elements, got {%s_len}\")\n", indent, name, pt.Size, v)
+		return []string{v + "_ptr"}
+	}
+	return []string{v + "_ptr", v + "_len"}
+}
+
+// writeBufferOut writes the out variables receiving the length of a buffer
+// result and returns the arguments passing them
+func writeBufferOut(buf *bytes.Buffer, indent string, pt core.ParsedType) []string {
+	switch {
+	case pt.IsRows():
+		fmt.Fprintf(buf, "%s_out_lens = c_void_p()\n%s_out_rows = c_longlong()\n", indent, indent)
+		return []string{"byref(_out_lens)", "byref(_out_rows)"}
+	case pt.Kind == core.KindSlice:
+		fmt.Fprintf(buf, "%s_out_len = c_longlong()\n", indent)
+		return []string{"byref(_out_len)"}
+	}
+	return nil
+}
+
+// bufferResult returns the expression converting the buffer result in
+// _result to Python
+func bufferResult(pt core.ParsedType) string {
+	elem := dtype(pt.BufferElem())
+	switch {
+	case pt.IsRows():
+		return fmt.Sprintf("_rows_result(_result, _out_lens.value, _out_rows.value, %q)", elem)
+	case pt.Kind == core.KindArray:
+		return fmt.Sprintf("_buffer_result(_result, %d, %q)", pt.Size, elem)
+	}
+	return fmt.Sprintf("_buffer_result(_result, _out_len.value, %q)", elem)
+}
+
+// writeBufferHelpers writes the helpers passing numbers in buffers
+func (a *Plugin) writeBufferHelpers(buf *bytes.Buffer) {
+	buf.WriteString(`try:
+    import numpy as _np
+except ImportError:  # NumPy is optional; results are lists without it
+    _np = None
+
+# ctypes of the NumPy dtypes of Go numbers
+_DTYPES: Dict[str, Any] = {
+    "int8": c_int8, "int16": c_int16, "int32": c_int32, "int64": c_int64,
+    "uint8": c_uint8, "uint16": c_uint16, "uint32": c_uint32, "uint64": c_uint64,
+    "float32": c_float, "float64": c_double,
+}
+
+def _buffer_dtype(view: memoryview) -> str:
+    """Return the dtype of the numbers in a buffer of native byte order, or ''."""
+    fmt = view.format.lstrip("@=")
+    if fmt[:1] == ("<" if sys.byteorder == "little" else ">"):
+        fmt = fmt[1:]
+    if len(fmt) != 1:
+        return ""
+    if fmt in "fd":
+        return f"float{view.itemsize * 8}"
+    if fmt in "bhilqn":
+        return f"int{view.itemsize * 8}"
+    if fmt in "BHILQN":
+        return f"uint{view.itemsize * 8}"
+    return ""
+
+def _buffer_arg(value: Any, dtype: str, ndim: int = 1) -> Tuple[Any, int, int]:
+    """Return an object holding the numbers of value as dtype, their address and count.
+
+    C-contiguous buffers of dtype, such as NumPy arrays, array.array and
+    memoryview, are lent to Go without copying. Other values are converted:
+    by NumPy when it is installed, element by element otherwise.
+    """
+    ctype = _DTYPES[dtype]
+    try:
+        view = memoryview(value)
+    except TypeError:
+        view = None
+    if view is not None and view.ndim == ndim and view.c_contiguous and _buffer_dtype(view) == dtype:
+        count = view.nbytes // view.itemsize
+        if not view.readonly:
+            data = (ctype * count).from_buffer(view)
+            return data, ctypes.addressof(data), count
+        if _np is not None:
+            array = _np.frombuffer(view, dtype=dtype)
+            return array, array.ctypes.data, count
+    if _np is not None:
+        array = _np.ascontiguousarray(value, dtype=dtype)
+        if array.ndim != ndim:
+            raise ValueError(f"expected {ndim} dimension(s), got {array.ndim}")
+        return array, array.ctypes.data, array.size
+    items = view.tolist() if view is not None else list(value)
+    if ndim == 2:
+        items = [x for row in items for x in row]
+    data = (ctype * len(items))(*items)
+    return data, ctypes.addressof(data), len(items)
+
+def _rows_arg(value: Any, dtype: str) -> Tuple[Any, int, Any, int]:
+    """Return the rows of value as one buffer of dtype numbers.
+
+    Returns an object holding the numbers, their address, the row lengths
+    and the number of rows. Two-dimensional buffers are passed like
+    one-dimensional ones; other rows are copied one after another.
+    """
+    shape = getattr(value, "shape", None)
+    if shape is not None and len(shape) == 2:
+        data, address, _ = _buffer_arg(value, dtype, 2)
+        return data, address, (c_longlong * shape[0])(*([shape[1]] * shape[0])), shape[0]
+    rows = [_buffer_arg(row, dtype) for row in value]
+    total = 0
+    for _, _, n in rows:
+        total += n
+    size = ctypes.sizeof(_DTYPES[dtype])
+    data = (_DTYPES[dtype] * total)()
+    offset = ctypes.addressof(data)
+    for _, address, n in rows:
+        ctypes.memmove(offset, address, n * size)
+        offset += n * size
+    return data, ctypes.addressof(data), (c_longlong * len(rows))(*(n for _, _, n in rows)), len(rows)
+
+def _buffer_result(address: Optional[int], count: int, dtype: str) -> Any:
+    """Copy count dtype numbers out of C memory and free it.
+
+    Returns a NumPy array, or a list when NumPy is not installed.
+    """
+    try:
+        if not address or not count:
+            return _np.zeros(0, dtype=dtype) if _np is not None else []
+        data = (_DTYPES[dtype] * count).from_address(address)
+        if _np is not None:
+            return _np.frombuffer(data, dtype=dtype).copy()
+        return list(data)
+    finally:
+        get_library().Free_Bytes(address)
+
+def _rows_result(address: Optional[int], lens: Optional[int], rows: int, dtype: str) -> Any:
+    """Copy rows of dtype numbers out of C memory and free it.
+
+    Rows of equal length form a two-dimensional NumPy array; otherwise each
+    row is a separate array, or a list when NumPy is not installed.
+    """
+    sizes = [int(n) for n in _buffer_result(lens, rows, "int64")]
+    total, uniform = 0, True
+    for n in sizes:
+        total += n
+        uniform = uniform and n == sizes[0]
+    flat = _buffer_result(address, total, dtype)
+    if _np is not None and uniform:
+        return flat.reshape(rows, sizes[0] if sizes else 0)
+    out, offset = [], 0
+    for n in sizes:
+        out.append(flat[offset:offset + n])
+        offset += n
+    return out
+
+`)
+}
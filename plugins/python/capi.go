@@ -0,0 +1,653 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package python
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/riceriley59/goanywhere/internal/core"
+)
+
+// backends lists the ways generated bindings call the library: through
+// ctypes, or through a C extension module calling the exports directly
+var backends = []string{"ctypes", "capi"}
+
+// nativeKind is how the C extension converts a value between Python and C
+type nativeKind int
+
+const (
+	nativeSigned nativeKind = iota
+	nativeUnsigned
+	nativeFloat
+	nativeBool
+	nativeString
+	nativeHandle
+	nativeContext // Not a Python argument; context.Background is passed
+)
+
+// nativeValue is a parameter or result of an export called by the C extension
+type nativeValue struct {
+	kind  nativeKind
+	cType string // C type of the export
+}
+
+// nativeExport is an export the C extension calls on behalf of a wrapper
+type nativeExport struct {
+	symbol   string
+	params   []nativeValue // Receiver handle first for methods
+	result   *nativeValue  // nil for none
+	hasError bool
+}
+
+// extensionName returns the name of the C extension module of the package
+func (a *Plugin) extensionName() string {
+	return "_" + a.pkg.Name + "_native"
+}
+
+// nativeValueOf returns how the C extension passes a value of type t, or
+// false when t is left to ctypes
+func (a *Plugin) nativeValueOf(t core.ParsedType) (nativeValue, bool) {
+	switch {
+	case t.IsContext():
+		return nativeValue{kind: nativeContext, cType: "uintptr_t"}, true
+	case t.Kind == core.KindPrimitive:
+		return nativePrimitive(t.Name), true
+	case t.Kind == core.KindString:
+		return nativeValue{kind: nativeString, cType: "char *"}, true
+	case t.Kind == core.KindStruct, t.Kind == core.KindPointer && t.ElemType != nil && t.ElemType.Kind == core.KindStruct:
+		if pyType, err := a.mapper.MapType(t); err == nil && pyType.IsHandle {
+			return nativeValue{kind: nativeHandle, cType: "uintptr_t"}, true
+		}
+	}
+	return nativeValue{}, false
+}
+
+// nativePrimitive returns how the C extension passes a Go primitive
+func nativePrimitive(name string) nativeValue {
+	switch name {
+	case "int8":
+		return nativeValue{kind: nativeSigned, cType: "int8_t"}
+	case "int16":
+		return nativeValue{kind: nativeSigned, cType: "int16_t"}
+	case "int32", "rune":
+		return nativeValue{kind: nativeSigned, cType: "int32_t"}
+	case "int64":
+		return nativeValue{kind: nativeSigned, cType: "int64_t"}
+	case "uint":
+		return nativeValue{kind: nativeUnsigned, cType: "unsigned long long"}
+	case "uint8", "byte":
+		return nativeValue{kind: nativeUnsigned, cType: "uint8_t"}
+	case "uint16":
+		return nativeValue{kind: nativeUnsigned, cType: "uint16_t"}
+	case "uint32":
+		return nativeValue{kind: nativeUnsigned, cType: "uint32_t"}
+	case "uint64":
+		return nativeValue{kind: nativeUnsigned, cType: "uint64_t"}
+	case "uintptr":
+		return nativeValue{kind: nativeUnsigned, cType: "uintptr_t"}
+	case "float32":
+		return nativeValue{kind: nativeFloat, cType: "float"}
+	case "float64":
+		return nativeValue{kind: nativeFloat, cType: "double"}
+	case "bool":
+		return nativeValue{kind: nativeBool, cType: "_Bool"}
+	default:
+		return nativeValue{kind: nativeSigned, cType: "long long"}
+	}
+}
+
+// nativeCall returns the export the C extension calls for symbol, recording
+// it, or false when the ctypes backend is used or a type is left to ctypes
+func (a *Plugin) nativeCall(symbol string, receiver bool, params []core.ParsedParam, results []core.ParsedResult) (nativeExport, bool) {
+	if a.backend != "capi" {
+		return nativeExport{}, false
+	}
+	export := nativeExport{symbol: symbol}
+	if receiver {
+		export.params = append(export.params, nativeValue{kind: nativeHandle, cType: "uintptr_t"})
+	}
+	for _, param := range params {
+		value, ok := a.nativeValueOf(param.Type)
+		if !ok {
+			return nativeExport{}, false
+		}
+		export.params = append(export.params, value)
+	}
+	for _, result := range results {
+		if result.Type.Kind == core.KindError {
+			export.hasError = true
+			continue
+		}
+		value, ok := a.nativeValueOf(result.Type)
+		if !ok || value.kind == nativeContext {
+			return nativeExport{}, false
+		}
+		export.result = &value
+	}
+	a.natives = append(a.natives, export)
+	return export, true
+}
+
+// writeNativeImport writes the import of the C extension, inside the
+// package or next to a standalone module
+func (a *Plugin) writeNativeImport(buf *bytes.Buffer) {
+	fmt.Fprintf(buf, "try:\n    from . import %[1]s as _native\nexcept ImportError:\n    import %[1]s as _native\n", a.extensionName())
+}
+
+// writeNativeCall writes the body of a wrapper calling export through the C
+// extension, which converts the arguments and the result itself
+func (a *Plugin) writeNativeCall(buf *bytes.Buffer, indent string, export nativeExport, receiver bool, params []paramInfo, result *core.ParsedResult) {
+	var args []string
+	if receiver {
+		args = append(args, "self._handle")
+	}
+	for _, p := range params {
+		switch {
+		case p.goType.IsContext():
+		case p.pyType.IsHandle:
+			args = append(args, p.name+"._handle")
+		default:
+			args = append(args, p.name)
+		}
+	}
+	a.writeNativeReturn(buf, indent, fmt.Sprintf("_native.%s(%s)", export.symbol, strings.Join(args, ", ")), result)
+}
+
+// writeNativeReturn writes the return of the result of a call of the C
+// extension, wrapping handles in their class
+func (a *Plugin) writeNativeReturn(buf *bytes.Buffer, indent, call string, result *core.ParsedResult) {
+	if result == nil {
+		fmt.Fprintf(buf, "%s%s\n", indent, call)
+		return
+	}
+	pyType, _ := a.mapper.MapType(result.Type)
+	switch {
+	case pyType.IsHandle && result.Ownership == core.OwnershipBorrowed:
+		// The method returned its receiver
+		fmt.Fprintf(buf, "%s%s\n", indent, call)
+		fmt.Fprintf(buf, "%sreturn self\n", indent)
+	case pyType.IsHandle:
+		className := a.mapper.className(result.Type)
+		if result.Type.Kind == core.KindPointer && result.Type.ElemType != nil {
+			className = a.mapper.className(*result.Type.ElemType)
+		}
+		if result.Ownership == core.OwnershipChild {
+			call += ", self"
+		}
+		fmt.Fprintf(buf, "%sreturn %s._from_handle(%s)\n", indent, className, call)
+	default:
+		fmt.Fprintf(buf, "%sreturn %s\n", indent, call)
+	}
+}
+
+// extensionSource returns the C source of the extension module calling the
+// exports recorded by the last generate. The module resolves the exports
+// from the library the bindings loaded through ctypes, so that
+// load_library() still picks the library and it is loaded only once.
+func (a *Plugin) extensionSource() []byte {
+	var buf bytes.Buffer
+	name := a.extensionName()
+
+	fmt.Fprintf(&buf, `/*
+ * Generated by goanywhere - Python C extension
+ * Source: %s
+ *
+ * Calls the exports of the shared library for the %s bindings module.
+ */
+
+#define PY_SSIZE_T_CLEAN
+#include <Python.h>
+#include <stdint.h>
+#include <string.h>
+
+`, a.pkg.Source(), a.pkg.Name)
+
+	// Exports resolved by bind(), in the order of SYMBOLS
+	buf.WriteString("enum {\n    SYM_Free_String,\n")
+	for _, export := range a.natives {
+		fmt.Fprintf(&buf, "    SYM_%s,\n", export.symbol)
+	}
+	buf.WriteString("    SYM_COUNT\n};\n\n")
+	buf.WriteString("static const char *const goanywhere_symbol_names[SYM_COUNT] = {\n    \"Free_String\",\n")
+	for _, export := range a.natives {
+		fmt.Fprintf(&buf, "    \"%s\",\n", export.symbol)
+	}
+	buf.WriteString("};\n\n")
+
+	buf.WriteString("typedef void (*Free_String_fn)(char *);\n")
+	for _, export := range a.natives {
+		fmt.Fprintf(&buf, "typedef %s (*%s_fn)(%s);\n", export.resultCType(), export.symbol, strings.Join(export.paramCTypes(), ", "))
+	}
+	buf.WriteString("\n")
+	buf.WriteString(extensionRuntime)
+
+	for _, export := range a.natives {
+		writeNativeFunction(&buf, export)
+	}
+
+	buf.WriteString("static PyMethodDef goanywhere_methods[] = {\n")
+	buf.WriteString("    {\"bind\", goanywhere_bind, METH_O, \"Resolve the exports from their addresses, in the order of SYMBOLS.\"},\n")
+	buf.WriteString("    {\"set_loader\", goanywhere_set_loader, METH_O, \"Register the function loading the library on first use.\"},\n")
+	for _, export := range a.natives {
+		fmt.Fprintf(&buf, "    {\"%[1]s\", (PyCFunction)(void (*)(void))native_%[1]s, METH_FASTCALL, NULL},\n", export.symbol)
+	}
+	buf.WriteString("    {NULL, NULL, 0, NULL}\n};\n\n")
+
+	fmt.Fprintf(&buf, `static struct PyModuleDef goanywhere_module = {
+    PyModuleDef_HEAD_INIT, "%[1]s", "Native calls of the %[2]s library.", -1, goanywhere_methods,
+};
+
+PyMODINIT_FUNC
+PyInit_%[1]s(void)
+{
+    PyObject *module;
+    PyObject *names;
+    Py_ssize_t i;
+
+    module = PyModule_Create(&goanywhere_module);
+    if (module == NULL)
+        return NULL;
+    names = PyTuple_New(SYM_COUNT);
+    if (names == NULL) {
+        Py_DECREF(module);
+        return NULL;
+    }
+    for (i = 0; i < SYM_COUNT; i++) {
+        PyObject *symbol = PyUnicode_FromString(goanywhere_symbol_names[i]);
+        if (symbol == NULL) {
+            Py_DECREF(names);
+            Py_DECREF(module);
+            return NULL;
+        }
+        PyTuple_SET_ITEM(names, i, symbol);
+    }
+    if (PyModule_AddObject(module, "SYMBOLS", names) < 0) {
+        Py_DECREF(names);
+        Py_DECREF(module);
+        return NULL;
+    }
+    return module;
+}
+`, name, a.pkg.Name)
+
+	return buf.Bytes()
+}
+
+// resultCType returns the C type the export returns
+func (e nativeExport) resultCType() string {
+	if e.result == nil {
+		return "void"
+	}
+	return e.result.cType
+}
+
+// paramCTypes returns the C types of the export's parameters, ending with
+// the error out parameter
+func (e nativeExport) paramCTypes() []string {
+	var types []string
+	for _, param := range e.params {
+		types = append(types, param.cType)
+	}
+	if e.hasError {
+		types = append(types, "char **")
+	}
+	if len(types) == 0 {
+		types = append(types, "void")
+	}
+	return types
+}
+
+// nativeConverter is how the C extension converts an argument: the
+// declaration of the local it converts to, with %s for the name, and the
+// runtime function converting it
+type nativeConverter struct {
+	local   string
+	convert string
+}
+
+// nativeConverters holds the converter of each kind of argument
+var nativeConverters = map[nativeKind]nativeConverter{
+	nativeSigned:   {"long long %s", "goanywhere_signed"},
+	nativeUnsigned: {"unsigned long long %s", "goanywhere_unsigned"},
+	nativeFloat:    {"double %s", "goanywhere_double"},
+	nativeBool:     {"int %s", "goanywhere_bool"},
+	nativeString:   {"const char *%s", "goanywhere_string"},
+	nativeHandle:   {"uintptr_t %s", "goanywhere_handle"},
+}
+
+// nativeResults holds the conversion of each kind of result to Python
+var nativeResults = map[nativeKind]string{
+	nativeSigned:   "PyLong_FromLongLong((long long)result)",
+	nativeUnsigned: "PyLong_FromUnsignedLongLong((unsigned long long)result)",
+	nativeFloat:    "PyFloat_FromDouble((double)result)",
+	nativeBool:     "PyBool_FromLong(result)",
+	nativeString:   "goanywhere_string_result(result)",
+	nativeHandle:   "PyLong_FromSize_t((size_t)result)",
+}
+
+// writeNativeFunction writes the extension function calling an export: it
+// converts the arguments, calls the export without holding the GIL, like
+// ctypes does, and converts the error and the result
+func writeNativeFunction(buf *bytes.Buffer, export nativeExport) {
+	fmt.Fprintf(buf, "static PyObject *\nnative_%s(PyObject *module, PyObject *const *args, Py_ssize_t nargs)\n{\n", export.symbol)
+
+	var nargs int
+	var callArgs []string
+	var conversions strings.Builder
+	for _, param := range export.params {
+		if param.kind == nativeContext {
+			callArgs = append(callArgs, "(uintptr_t)0")
+			continue
+		}
+		local := fmt.Sprintf("a%d", nargs)
+		converter := nativeConverters[param.kind]
+		fmt.Fprintf(buf, "    "+converter.local+";\n", local)
+		fmt.Fprintf(&conversions, "    if (%s(args[%d], &%s) < 0)\n        return NULL;\n", converter.convert, nargs, local)
+		callArgs = append(callArgs, fmt.Sprintf("(%s)%s", param.cType, local))
+		nargs++
+	}
+	if export.hasError {
+		buf.WriteString("    char *error = NULL;\n")
+		callArgs = append(callArgs, "&error")
+	}
+	if export.result != nil {
+		fmt.Fprintf(buf, "    %s result;\n", export.result.cType)
+	}
+	buf.WriteString("\n")
+
+	fmt.Fprintf(buf, "    if (goanywhere_arity(\"%s\", nargs, %d) < 0)\n        return NULL;\n", export.symbol, nargs)
+	buf.WriteString(conversions.String())
+
+	call := fmt.Sprintf("((%[1]s_fn)goanywhere_symbols[SYM_%[1]s])(%[2]s)", export.symbol, strings.Join(callArgs, ", "))
+	if export.result != nil {
+		call = "result = " + call
+	}
+	fmt.Fprintf(buf, "    Py_BEGIN_ALLOW_THREADS\n    %s;\n    Py_END_ALLOW_THREADS\n", call)
+
+	if export.hasError {
+		buf.WriteString("    if (error != NULL) {\n")
+		if export.result != nil && export.result.kind == nativeString {
+			buf.WriteString("        goanywhere_free_string(result);\n")
+		}
+		buf.WriteString("        return goanywhere_error(error);\n    }\n")
+	}
+	if export.result != nil {
+		fmt.Fprintf(buf, "    return %s;\n", nativeResults[export.result.kind])
+	} else {
+		buf.WriteString("    Py_RETURN_NONE;\n")
+	}
+	buf.WriteString("}\n\n")
+}
+
+// extensionRuntime holds the state and the conversions shared by the
+// functions of the C extension
+const extensionRuntime = `/* Addresses of the exports, resolved by bind() */
+static void *goanywhere_symbols[SYM_COUNT];
+static int goanywhere_bound;
+
+/* get_library() of the bindings module, loading the library on first use */
+static PyObject *goanywhere_loader;
+
+static int
+goanywhere_load(void)
+{
+    PyObject *lib;
+
+    if (goanywhere_loader == NULL) {
+        PyErr_SetString(PyExc_RuntimeError, "the bindings module did not register its library loader");
+        return -1;
+    }
+    lib = PyObject_CallObject(goanywhere_loader, NULL);
+    if (lib == NULL)
+        return -1;
+    Py_DECREF(lib);
+    if (!goanywhere_bound) {
+        PyErr_SetString(PyExc_RuntimeError, "the library exports were not bound");
+        return -1;
+    }
+    return 0;
+}
+
+static int
+goanywhere_arity(const char *name, Py_ssize_t nargs, Py_ssize_t expected)
+{
+    if (nargs != expected) {
+        PyErr_Format(PyExc_TypeError, "%s() takes %zd arguments (%zd given)", name, expected, nargs);
+        return -1;
+    }
+    if (!goanywhere_bound)
+        return goanywhere_load();
+    return 0;
+}
+
+static int
+goanywhere_signed(PyObject *obj, long long *out)
+{
+    if (!PyLong_Check(obj)) {
+        PyErr_Format(PyExc_TypeError, "expected int, got %.200s", Py_TYPE(obj)->tp_name);
+        return -1;
+    }
+    *out = PyLong_AsLongLong(obj);
+    return (*out == -1 && PyErr_Occurred()) ? -1 : 0;
+}
+
+static int
+goanywhere_unsigned(PyObject *obj, unsigned long long *out)
+{
+    if (!PyLong_Check(obj)) {
+        PyErr_Format(PyExc_TypeError, "expected int, got %.200s", Py_TYPE(obj)->tp_name);
+        return -1;
+    }
+    /* Wraps around like the ctypes integer types */
+    *out = PyLong_AsUnsignedLongLongMask(obj);
+    return (*out == (unsigned long long)-1 && PyErr_Occurred()) ? -1 : 0;
+}
+
+static int
+goanywhere_double(PyObject *obj, double *out)
+{
+    *out = PyFloat_AsDouble(obj);
+    return (*out == -1.0 && PyErr_Occurred()) ? -1 : 0;
+}
+
+static int
+goanywhere_bool(PyObject *obj, int *out)
+{
+    *out = PyObject_IsTrue(obj);
+    return *out < 0 ? -1 : 0;
+}
+
+static int
+goanywhere_string(PyObject *obj, const char **out)
+{
+    if (PyUnicode_Check(obj)) {
+        *out = PyUnicode_AsUTF8(obj);
+        return *out == NULL ? -1 : 0;
+    }
+    if (PyBytes_Check(obj)) {
+        *out = PyBytes_AS_STRING(obj);
+        return 0;
+    }
+    PyErr_Format(PyExc_TypeError, "expected str or bytes, got %.200s", Py_TYPE(obj)->tp_name);
+    return -1;
+}
+
+static int
+goanywhere_handle(PyObject *obj, uintptr_t *out)
+{
+    size_t handle = PyLong_AsSize_t(obj);
+
+    if (handle == (size_t)-1 && PyErr_Occurred())
+        return -1;
+    *out = (uintptr_t)handle;
+    return 0;
+}
+
+static void
+goanywhere_free_string(char *s)
+{
+    if (s != NULL)
+        ((Free_String_fn)goanywhere_symbols[SYM_Free_String])(s);
+}
+
+/* Converts and frees a string returned by Go */
+static PyObject *
+goanywhere_string_result(char *s)
+{
+    PyObject *result;
+
+    if (s == NULL)
+        Py_RETURN_NONE;
+    result = PyUnicode_DecodeUTF8(s, (Py_ssize_t)strlen(s), NULL);
+    goanywhere_free_string(s);
+    return result;
+}
+
+/* Raises the error returned by Go as RuntimeError */
+static PyObject *
+goanywhere_error(char *error)
+{
+    PyObject *message = PyUnicode_DecodeUTF8(error, (Py_ssize_t)strlen(error), "replace");
+
+    goanywhere_free_string(error);
+    if (message != NULL) {
+        PyErr_SetObject(PyExc_RuntimeError, message);
+        Py_DECREF(message);
+    }
+    return NULL;
+}
+
+static PyObject *
+goanywhere_bind(PyObject *module, PyObject *addresses)
+{
+    void *symbols[SYM_COUNT];
+    PyObject *seq;
+    Py_ssize_t i;
+
+    seq = PySequence_Fast(addresses, "bind() expects a sequence of addresses");
+    if (seq == NULL)
+        return NULL;
+    if (PySequence_Fast_GET_SIZE(seq) != SYM_COUNT) {
+        PyErr_Format(PyExc_ValueError, "bind() expects %d addresses", (int)SYM_COUNT);
+        Py_DECREF(seq);
+        return NULL;
+    }
+    for (i = 0; i < SYM_COUNT; i++) {
+        symbols[i] = PyLong_AsVoidPtr(PySequence_Fast_GET_ITEM(seq, i));
+        if (symbols[i] == NULL) {
+            if (!PyErr_Occurred())
+                PyErr_Format(PyExc_ValueError, "%s is not exported", goanywhere_symbol_names[i]);
+            Py_DECREF(seq);
+            return NULL;
+        }
+    }
+    Py_DECREF(seq);
+    memcpy(goanywhere_symbols, symbols, sizeof(symbols));
+    goanywhere_bound = 1;
+    Py_RETURN_NONE;
+}
+
+static PyObject *
+goanywhere_set_loader(PyObject *module, PyObject *loader)
+{
+    Py_INCREF(loader);
+    Py_XDECREF(goanywhere_loader);
+    goanywhere_loader = loader;
+    Py_RETURN_NONE;
+}
+
+`
+
+// pythonConfig is the interpreter a C extension is compiled for
+type pythonConfig struct {
+	include   string // Directory of Python.h
+	extSuffix string // File name suffix of extension modules
+	tag       string // Wheel interpreter and ABI tag, such as cp312
+}
+
+// pythonConfigScript prints the pythonConfig of the running interpreter
+const pythonConfigScript = `import sys, sysconfig
+print(sysconfig.get_paths()["include"])
+print(sysconfig.get_config_var("EXT_SUFFIX"))
+print("cp%d%d" % sys.version_info[:2])`
+
+// loadPythonConfig queries the interpreter the extension is compiled for
+func loadPythonConfig(python string) (*pythonConfig, error) {
+	out, err := exec.Command(python, "-c", pythonConfigScript).Output()
+	if err != nil {
+		return nil, fmt.Errorf("cannot query Python interpreter %s: %w", python, err)
+	}
+	lines := strings.Fields(string(out))
+	if len(lines) != 3 {
+		return nil, fmt.Errorf("unexpected configuration of Python interpreter %s: %q", python, out)
+	}
+	return &pythonConfig{include: lines[0], extSuffix: lines[1], tag: lines[2]}, nil
+}
+
+// pyExtension is a C extension module of a Python package
+type pyExtension struct {
+	name   string // Module name
+	source []byte
+}
+
+// compileExtension writes the source of ext to pkgDir and compiles it next
+// to it with the C compiler selected by --cc, $CC or cc. The extension does
+// not link the Go library, whose exports it receives from the bindings module.
+func compileExtension(ext pyExtension, pkgDir string, cfg *pythonConfig, opts *core.BuildOptions) error {
+	goos, _ := targetPlatform()
+	if goos == "windows" {
+		return fmt.Errorf("the capi backend cannot build extensions for windows")
+	}
+	if goos != runtime.GOOS {
+		return fmt.Errorf("the capi backend cannot cross-compile extensions for %s", goos)
+	}
+
+	srcFile := filepath.Join(pkgDir, ext.name+".c")
+	if err := os.WriteFile(srcFile, ext.source, 0644); err != nil {
+		return fmt.Errorf("write error: %w", err)
+	}
+	fmt.Printf("Generated C extension: %s\n", srcFile)
+
+	cc := strings.Fields(opts.CC)
+	if len(cc) == 0 {
+		cc = strings.Fields(os.Getenv("CC"))
+	}
+	if len(cc) == 0 {
+		cc = []string{"cc"}
+	}
+	extFile := filepath.Join(pkgDir, ext.name+cfg.extSuffix)
+	args := append(cc[1:], "-shared", "-fPIC", "-O2", "-I"+cfg.include, "-o", extFile, srcFile)
+	if goos == "darwin" {
+		// Python symbols are resolved from the interpreter loading the module
+		args = append(args, "-undefined", "dynamic_lookup")
+	}
+	if opts.Verbose {
+		fmt.Printf("Running: %s %s\n", cc[0], strings.Join(args, " "))
+	}
+	cmd := exec.Command(cc[0], args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to compile C extension: %w", err)
+	}
+	fmt.Printf("Built C extension: %s\n", extFile)
+	return nil
+}
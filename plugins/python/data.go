@@ -0,0 +1,163 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package python
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/riceriley59/goanywhere/internal/core"
+)
+
+// writeDataSetup writes argtypes/restype for the bulk conversion exports of
+// a struct
+func (a *Plugin) writeDataSetup(buf *bytes.Buffer, prefix string) {
+	fmt.Fprintf(buf, "    lib.%s_ToJSON.argtypes = [c_size_t, POINTER(c_char_p)]\n", prefix)
+	fmt.Fprintf(buf, "    lib.%s_ToJSON.restype = c_void_p\n", prefix)
+	fmt.Fprintf(buf, "    lib.%s_FromJSON.argtypes = [c_char_p, POINTER(c_char_p)]\n", prefix)
+	fmt.Fprintf(buf, "    lib.%s_FromJSON.restype = c_size_t\n", prefix)
+}
+
+// writeDataClass writes the frozen dataclass returned by snapshot, nested in
+// the wrapper class as Data
+func (a *Plugin) writeDataClass(buf *bytes.Buffer, st core.ParsedStruct) {
+	buf.WriteString("    @dataclasses.dataclass(frozen=True)\n")
+	buf.WriteString("    class Data:\n")
+	fmt.Fprintf(buf, "        \"\"\"Snapshot of the data fields of %s.\"\"\"\n", st.Name)
+	for _, field := range a.pkg.DataFields(st) {
+		fmt.Fprintf(buf, "        %s: %s\n", toSnakeCase(field.Name), a.dataHint(field.Type))
+	}
+}
+
+// dataHint returns the type hint of a data field in a snapshot
+func (a *Plugin) dataHint(t core.ParsedType) string {
+	if nested := a.pkg.NestedData(t); nested != nil {
+		return nested.Name + ".Data"
+	}
+	switch t.Kind {
+	case core.KindString:
+		return "str"
+	case core.KindPrimitive:
+		return a.mapper.mapPrimitive(t.Name).PyType
+	case core.KindMap:
+		return "dict"
+	}
+	return "tuple"
+}
+
+// isFloatData reports whether a data field holds floats, directly or as the
+// elements of a slice, array or map
+func (a *Plugin) isFloatData(t core.ParsedType) bool {
+	if t.Kind != core.KindPrimitive && t.ElemType != nil {
+		t = *t.ElemType
+	}
+	return t.Kind == core.KindPrimitive && a.mapper.mapPrimitive(t.Name).PyType == "float"
+}
+
+// writeDataMethods writes to_dict, from_dict and snapshot, which convert all
+// data fields of a struct with one call through its JSON exports. Fields are
+// keyed by Go name across the boundary and by property name in Python.
+func (a *Plugin) writeDataMethods(buf *bytes.Buffer, st core.ParsedStruct) {
+	className := st.Name
+	prefix := a.pkg.StructPrefix(st.Name)
+	fields := a.pkg.DataFields(st)
+
+	fmt.Fprintf(buf, `    def _to_json(self) -> Dict[str, Any]:
+        """Read the data fields in one call, keyed by Go field name."""
+        lib = get_library()
+        _error = (c_char_p * 1)()
+        _result = lib.%[1]s_ToJSON(self._handle, _error)
+        _check_error(_error)
+        _ret = _decode_string(_result)
+        lib.Free_String(_result)
+        return json.loads(_ret)
+
+`, prefix)
+
+	// Go field names to property names, recursing into nested structs
+	var entries, args []string
+	buf.WriteString("    @staticmethod\n")
+	buf.WriteString("    def _dict_from_json(data: Dict[str, Any]) -> Dict[str, Any]:\n")
+	for _, field := range fields {
+		name := toSnakeCase(field.Name)
+		value := fmt.Sprintf("data[%q]", field.Name)
+		if a.isFloatData(field.Type) {
+			// Go encodes whole floats without a fraction, which JSON decodes as int
+			value = fmt.Sprintf("_as_float(%s)", value)
+		}
+		data := value
+		if nested := a.pkg.NestedData(field.Type); nested != nil {
+			value = fmt.Sprintf("%s._dict_from_json(%s)", nested.Name, value)
+			data = fmt.Sprintf("%s._data_from_json(data[%q])", nested.Name, field.Name)
+		} else if field.Type.Kind == core.KindSlice || field.Type.Kind == core.KindArray {
+			data = fmt.Sprintf("tuple(%s or ())", value)
+		}
+		entries = append(entries, fmt.Sprintf("%q: %s", name, value))
+		args = append(args, fmt.Sprintf("%s=%s", name, data))
+	}
+	fmt.Fprintf(buf, "        return {%s}\n\n", strings.Join(entries, ", "))
+
+	buf.WriteString("    @staticmethod\n")
+	fmt.Fprintf(buf, "    def _data_from_json(data: Dict[str, Any]) -> %s.Data:\n", className)
+	fmt.Fprintf(buf, "        return %s.Data(%s)\n\n", className, strings.Join(args, ", "))
+
+	// Property names to Go field names, rejecting unknown fields
+	buf.WriteString("    @staticmethod\n")
+	buf.WriteString("    def _json_from_dict(data: Dict[str, Any]) -> Dict[str, Any]:\n")
+	buf.WriteString("        _json: Dict[str, Any] = {}\n")
+	buf.WriteString("        for key, value in data.items():\n")
+	keyword := "if"
+	for _, field := range fields {
+		value := "value"
+		if nested := a.pkg.NestedData(field.Type); nested != nil {
+			value = fmt.Sprintf("%s._json_from_dict(_as_dict(value))", nested.Name)
+		} else if field.Type.Kind == core.KindSlice || field.Type.Kind == core.KindArray {
+			value = "list(value)"
+		}
+		fmt.Fprintf(buf, "            %s key == %q:\n", keyword, toSnakeCase(field.Name))
+		fmt.Fprintf(buf, "                _json[%q] = %s\n", field.Name, value)
+		keyword = "elif"
+	}
+	if keyword == "if" {
+		buf.WriteString("            raise TypeError(f\"" + className + " has no data field {key!r}\")\n")
+	} else {
+		buf.WriteString("            else:\n")
+		buf.WriteString("                raise TypeError(f\"" + className + " has no data field {key!r}\")\n")
+	}
+	buf.WriteString("        return _json\n\n")
+
+	fmt.Fprintf(buf, `    def to_dict(self) -> Dict[str, Any]:
+        """Return the data fields as a dict, read in one call."""
+        return %[1]s._dict_from_json(self._to_json())
+
+    @classmethod
+    def from_dict(cls, data: Any) -> "%[1]s":
+        """Create an instance from a dict or snapshot of data fields, set in one call.
+
+        Fields missing from data keep their zero value.
+        """
+        lib = get_library()
+        _error = (c_char_p * 1)()
+        _result = lib.%[2]s_FromJSON(_encode_string(json.dumps(cls._json_from_dict(_as_dict(data)))), _error)
+        _check_error(_error)
+        return cls._from_handle(_result)
+
+    def snapshot(self) -> %[1]s.Data:
+        """Return a frozen copy of the data fields, read in one call."""
+        return %[1]s._data_from_json(self._to_json())
+
+`, className, prefix)
+}
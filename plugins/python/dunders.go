@@ -0,0 +1,174 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package python
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/riceriley59/goanywhere/internal/core"
+)
+
+// specialMethods are the Go methods of a struct that implement Python
+// special methods, detected by signature
+type specialMethods struct {
+	str, goStr, equal, length, less, item, iter *core.ParsedMethod
+}
+
+// findSpecialMethods returns the bound methods of st that implement Python
+// special methods:
+//
+//	String() string             __str__, and __repr__ without GoString
+//	GoString() string           __repr__
+//	Equal(other T) bool         __eq__
+//	Len() int                   __len__
+//	Less(other T) bool          __lt__
+//	Get(i int) V, or At(i int)  __getitem__, together with Len
+//	All() iter.Seq[V]           __iter__
+//
+// where T is the struct or a pointer to it. __getitem__ needs Len to check
+// bounds: Python iterates and tests membership by indexing until IndexError,
+// while an index out of range panics in Go.
+func findSpecialMethods(st core.ParsedStruct, bound []core.ParsedMethod) specialMethods {
+	var sm specialMethods
+	for i := range bound {
+		m := &bound[i]
+		switch {
+		case m.Name == "String" && len(m.Params) == 0 && returnsOnly(m, core.KindString):
+			sm.str = m
+		case m.Name == "GoString" && len(m.Params) == 0 && returnsOnly(m, core.KindString):
+			sm.goStr = m
+		case m.Name == "Equal" && takesSelf(st, m) && returnsBool(m):
+			sm.equal = m
+		case m.Name == "Len" && len(m.Params) == 0 && len(m.Results) == 1 && isInteger(m.Results[0].Type):
+			sm.length = m
+		case m.Name == "Less" && takesSelf(st, m) && returnsBool(m):
+			sm.less = m
+		case (m.Name == "Get" || (m.Name == "At" && sm.item == nil)) && len(m.Params) == 1 &&
+			isInteger(m.Params[0].Type) && valueResults(m.Results) == 1:
+			sm.item = m
+		case m.Name == "All" && len(m.Params) == 0 && returnsOnly(m, core.KindIter):
+			sm.iter = m
+		}
+	}
+	if sm.length == nil {
+		sm.item = nil
+	}
+	return sm
+}
+
+// returnsOnly reports whether m returns a single value of kind
+func returnsOnly(m *core.ParsedMethod, kind core.TypeKind) bool {
+	return len(m.Results) == 1 && m.Results[0].Type.Kind == kind
+}
+
+// returnsBool reports whether m returns a single bool
+func returnsBool(m *core.ParsedMethod) bool {
+	return len(m.Results) == 1 && m.Results[0].Type.Kind == core.KindPrimitive && m.Results[0].Type.Name == "bool"
+}
+
+// takesSelf reports whether the only parameter of m has the type of st or a
+// pointer to it
+func takesSelf(st core.ParsedStruct, m *core.ParsedMethod) bool {
+	if len(m.Params) != 1 {
+		return false
+	}
+	t := m.Params[0].Type
+	if t.Kind == core.KindPointer && t.ElemType != nil {
+		t = *t.ElemType
+	}
+	return t.Kind == core.KindStruct && t.Name == st.Name && t.PackagePath == ""
+}
+
+// isInteger reports whether t is an integer type
+func isInteger(t core.ParsedType) bool {
+	return t.Kind == core.KindPrimitive && (strings.HasPrefix(t.Name, "int") || strings.HasPrefix(t.Name, "uint") ||
+		t.Name == "byte" || t.Name == "rune") && t.Name != "uintptr"
+}
+
+// writeSpecialMethods writes the Python special methods of a class, calling
+// the wrappers of the Go methods that implement them. Without an Equal
+// method instances compare and hash by the Go pointer they wrap.
+func (a *Plugin) writeSpecialMethods(buf *bytes.Buffer, st core.ParsedStruct, bound []core.ParsedMethod) {
+	className := st.Name
+	sm := findSpecialMethods(st, bound)
+	call := func(m *core.ParsedMethod, args string) string {
+		return fmt.Sprintf("self.%s(%s)", toSnakeCase(m.Name), args)
+	}
+
+	if sm.str != nil {
+		fmt.Fprintf(buf, "    def __str__(self) -> str:\n        return %s\n\n", call(sm.str, ""))
+	}
+	switch {
+	case sm.goStr != nil:
+		fmt.Fprintf(buf, "    def __repr__(self) -> str:\n        return %s\n\n", call(sm.goStr, ""))
+	case sm.str != nil:
+		fmt.Fprintf(buf, "    def __repr__(self) -> str:\n        return f\"<%s {%s}>\"\n\n", className, call(sm.str, ""))
+	}
+
+	if sm.equal != nil {
+		fmt.Fprintf(buf, `    def __eq__(self, other: object) -> bool:
+        """Compare with Go's %[1]s.Equal."""
+        if not isinstance(other, %[1]s):
+            return NotImplemented
+        return %[2]s
+
+    # Equal compares values, which the Go pointer cannot hash
+    __hash__ = None  # type: ignore[assignment]
+
+`, className, call(sm.equal, "other"))
+	} else {
+		fmt.Fprintf(buf, `    def __eq__(self, other: object) -> bool:
+        """Instances are equal when they wrap the same Go pointer."""
+        if not isinstance(other, %s):
+            return NotImplemented
+        return self._handle == other._handle
+
+    def __hash__(self) -> int:
+        return hash(self._handle)
+
+`, className)
+	}
+
+	if sm.less != nil {
+		fmt.Fprintf(buf, `    def __lt__(self, other: object) -> bool:
+        if not isinstance(other, %s):
+            return NotImplemented
+        return %s
+
+`, className, call(sm.less, "other"))
+	}
+
+	if sm.length != nil {
+		fmt.Fprintf(buf, "    def __len__(self) -> int:\n        return %s\n\n", call(sm.length, ""))
+	}
+
+	if sm.item != nil {
+		buf.WriteString("    def __getitem__(self, index: int) -> Any:\n")
+		buf.WriteString("        if not isinstance(index, int):\n")
+		fmt.Fprintf(buf, "            raise TypeError(f\"%s indices must be integers, not {type(index).__name__}\")\n", className)
+		// Bounds checks make negative indices and iteration work
+		buf.WriteString("        if index < 0:\n            index += len(self)\n")
+		buf.WriteString("        if not 0 <= index < len(self):\n")
+		fmt.Fprintf(buf, "            raise IndexError(\"%s index out of range\")\n", className)
+		fmt.Fprintf(buf, "        return %s\n\n", call(sm.item, "index"))
+	}
+
+	if sm.iter != nil {
+		pyType, _ := a.mapper.MapType(sm.iter.Results[0].Type)
+		fmt.Fprintf(buf, "    def __iter__(self) -> %s:\n        return %s\n\n", pyType.PyType, call(sm.iter, ""))
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package python
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/riceriley59/goanywhere/internal/core"
+)
+
+// iterElems returns the element types of an iterator: the value of an
+// iter.Seq, or the key and value of an iter.Seq2
+func iterElems(pt core.ParsedType) []core.ParsedType {
+	if pt.KeyType != nil {
+		return []core.ParsedType{*pt.KeyType, *pt.ElemType}
+	}
+	return []core.ParsedType{*pt.ElemType}
+}
+
+// mapIter maps an iterator, which Python receives as a handle it pulls
+// elements from
+func (m *TypeMapper) mapIter(pt core.ParsedType) (PyType, error) {
+	var hints []string
+	for _, elem := range iterElems(pt) {
+		pyType, err := m.MapType(elem)
+		if err != nil {
+			return PyType{}, err
+		}
+		if !pyType.IsString && !(pyType.IsHandle && elem.IsHandle()) && elem.Kind != core.KindPrimitive {
+			return PyType{}, &core.UnsupportedTypeError{
+				Type:   elem.Name,
+				Reason: "iterator elements must be numbers, bools, strings or structs",
+			}
+		}
+		hints = append(hints, pyType.PyType)
+	}
+	hint := hints[0]
+	if len(hints) == 2 {
+		hint = "Tuple[" + strings.Join(hints, ", ") + "]"
+	}
+	return PyType{CtypesType: "c_size_t", PyType: "Iterator[" + hint + "]"}, nil
+}
+
+// iterOut returns the ctypes type of the out parameter receiving an
+// iterator element
+func (a *Plugin) iterOut(elem core.ParsedType) string {
+	pyType, _ := a.mapper.MapType(elem)
+	switch {
+	case pyType.IsString:
+		return "c_void_p"
+	case pyType.IsHandle:
+		return "c_size_t"
+	}
+	return pyType.CtypesType
+}
+
+// writeIterSetup writes argtypes/restype for the export pulling the
+// elements of the iterators returned by symbol
+func (a *Plugin) writeIterSetup(buf *bytes.Buffer, symbol string, pt core.ParsedType) {
+	argtypes := []string{"c_size_t"}
+	for _, elem := range iterElems(pt) {
+		argtypes = append(argtypes, "POINTER("+a.iterOut(elem)+")")
+	}
+	fmt.Fprintf(buf, "    lib.%s_Next.argtypes = [%s]\n", symbol, strings.Join(argtypes, ", "))
+	fmt.Fprintf(buf, "    lib.%s_Next.restype = c_bool\n", symbol)
+}
+
+// writeIterReturn writes the end of a wrapper returning the iterator handle
+// in _result as a generator, pulling elements through symbol's Next export
+func (a *Plugin) writeIterReturn(buf *bytes.Buffer, indent, symbol string, pt core.ParsedType) {
+	names := []string{"_value"}
+	if pt.KeyType != nil {
+		names = []string{"_key", "_value"}
+	}
+	elems := iterElems(pt)
+
+	fmt.Fprintf(buf, "%sdef _pull(it: int) -> Tuple[bool, Any]:\n", indent)
+	for i, elem := range elems {
+		fmt.Fprintf(buf, "%s    %s = %s()\n", indent, names[i], a.iterOut(elem))
+	}
+	refs := make([]string, len(names))
+	for i, name := range names {
+		refs[i] = "byref(" + name + ")"
+	}
+	fmt.Fprintf(buf, "%s    if not lib.%s_Next(it, %s):\n", indent, symbol, strings.Join(refs, ", "))
+	fmt.Fprintf(buf, "%s        return False, None\n", indent)
+	values := make([]string, len(names))
+	for i, elem := range elems {
+		pyType, _ := a.mapper.MapType(elem)
+		switch {
+		case pyType.IsString:
+			fmt.Fprintf(buf, "%s    %s_str = _decode_string(%s.value)\n", indent, names[i], names[i])
+			fmt.Fprintf(buf, "%s    lib.Free_String(%s.value)\n", indent, names[i])
+			values[i] = names[i] + "_str"
+		case pyType.IsHandle:
+			values[i] = fmt.Sprintf("%s._from_handle(%s.value)", pyType.PyType, names[i])
+		default:
+			values[i] = names[i] + ".value"
+		}
+	}
+	if len(values) == 2 {
+		fmt.Fprintf(buf, "%s    return True, (%s)\n", indent, strings.Join(values, ", "))
+	} else {
+		fmt.Fprintf(buf, "%s    return True, %s\n", indent, values[0])
+	}
+	fmt.Fprintf(buf, "%sreturn _iterate(_result, _pull)\n", indent)
+}
+
+// writeIterHelpers writes the generator wrapping iterator handles
+func (a *Plugin) writeIterHelpers(buf *bytes.Buffer) {
+	buf.WriteString(`def _iterate(handle: int, pull: Any) -> Iterator[Any]:
+    """Wrap a Go iterator handle in a generator.
+
+    Leaving a for loop early closes the generator, which stops the Go
+    iterator; an abandoned generator stops it when garbage collected.
+    """
+    def generate() -> Iterator[Any]:
+        try:
+            while True:
+                ok, value = pull(handle)
+                if not ok:
+                    return
+                yield value
+        finally:
+            release()
+
+    it = generate()
+    release = weakref.finalize(it, get_library().Iter_Free, handle)
+    return it
+
+`)
+}
@@ -34,6 +34,7 @@ type PyType struct {
 // TypeMapper handles Go to Python/ctypes type mapping
 type TypeMapper struct {
 	structRegistry map[string]*core.ParsedStruct
+	modules        map[string]string // Import path to Python module of packages bound in the same library
 }
 
 // NewTypeMapper creates a TypeMapper with known structs
@@ -44,9 +45,24 @@ func NewTypeMapper(structs []core.ParsedStruct) *TypeMapper {
 	}
 	return &TypeMapper{
 		structRegistry: registry,
+		modules:        make(map[string]string),
 	}
 }
 
+// AddPackage marks a package as bound in the same library, with its classes
+// referenced through the given module name
+func (m *TypeMapper) AddPackage(importPath, module string) {
+	m.modules[importPath] = module
+}
+
+// className returns the Python class wrapping a struct type
+func (m *TypeMapper) className(pt core.ParsedType) string {
+	if module, ok := m.modules[pt.PackagePath]; ok && pt.PackagePath != "" {
+		return module + "." + pt.Name
+	}
+	return pt.Name
+}
+
 // MapType converts a ParsedType to PyType
 func (m *TypeMapper) MapType(pt core.ParsedType) (PyType, error) {
 	switch pt.Kind {
@@ -74,10 +90,11 @@ func (m *TypeMapper) MapType(pt core.ParsedType) (PyType, error) {
 			return PyType{}, fmt.Errorf("pointer type missing element type")
 		}
 		if pt.ElemType.Kind == core.KindStruct {
-			if _, ok := m.structRegistry[pt.ElemType.Name]; ok {
+			_, local := m.structRegistry[pt.ElemType.Name]
+			if _, bound := m.modules[pt.ElemType.PackagePath]; (local && pt.ElemType.PackagePath == "") || bound {
 				return PyType{
 					CtypesType: "c_size_t",
-					PyType:     pt.ElemType.Name,
+					PyType:     m.className(*pt.ElemType),
 					IsHandle:   true,
 				}, nil
 			}
@@ -92,9 +109,15 @@ func (m *TypeMapper) MapType(pt core.ParsedType) (PyType, error) {
 		}, nil
 
 	case core.KindStruct:
+		if _, bound := m.modules[pt.PackagePath]; pt.PackagePath != "" && !bound {
+			return PyType{}, &core.UnsupportedTypeError{
+				Type:   pt.PackagePath + "." + pt.Name,
+				Reason: "types from packages not bound in the same library cannot be exposed to Python",
+			}
+		}
 		return PyType{
 			CtypesType: "c_size_t",
-			PyType:     pt.Name,
+			PyType:     m.className(pt),
 			IsHandle:   true,
 		}, nil
 
@@ -102,6 +125,9 @@ func (m *TypeMapper) MapType(pt core.ParsedType) (PyType, error) {
 		if pt.ElemType == nil {
 			return PyType{}, fmt.Errorf("slice type missing element type")
 		}
+		if pt.IsBuffer() {
+			return bufferType, nil
+		}
 		elemType, err := m.MapType(*pt.ElemType)
 		if err != nil {
 			return PyType{}, err
@@ -116,6 +142,9 @@ func (m *TypeMapper) MapType(pt core.ParsedType) (PyType, error) {
 		if pt.ElemType == nil {
 			return PyType{}, fmt.Errorf("array type missing element type")
 		}
+		if pt.IsBuffer() {
+			return bufferType, nil
+		}
 		elemType, err := m.MapType(*pt.ElemType)
 		if err != nil {
 			return PyType{}, err
@@ -139,6 +168,9 @@ func (m *TypeMapper) MapType(pt core.ParsedType) (PyType, error) {
 			IsHandle:   true,
 		}, nil
 
+	case core.KindIter:
+		return m.mapIter(pt)
+
 	case core.KindChan:
 		return PyType{}, &core.UnsupportedTypeError{
 			Type:   "chan",
@@ -93,6 +93,20 @@ var _ = Describe("TypeMapper", func() {
 			Expect(pyType.CtypesType).To(Equal("c_size_t"))
 			Expect(pyType.IsHandle).To(BeTrue())
 		})
+
+		It("qualifies structs from bundled packages with their module", func() {
+			mapper.AddPackage("example.com/geo", "_geo")
+			pt := core.ParsedType{Kind: core.KindStruct, Name: "Point", PackagePath: "example.com/geo"}
+			pyType, err := mapper.MapType(pt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pyType.PyType).To(Equal("_geo.Point"))
+		})
+
+		It("rejects structs from packages outside the library", func() {
+			pt := core.ParsedType{Kind: core.KindStruct, Name: "Time", PackagePath: "time"}
+			_, err := mapper.MapType(pt)
+			Expect(err).To(HaveOccurred())
+		})
 	})
 
 	Describe("MapType pointer", func() {
@@ -113,11 +127,20 @@ var _ = Describe("TypeMapper", func() {
 
 	Describe("MapType slice", func() {
 		It("maps slice", func() {
+			elem := core.ParsedType{Kind: core.KindPrimitive, Name: "bool"}
+			pt := core.ParsedType{Kind: core.KindSlice, Name: "[]bool", ElemType: &elem}
+			pyType, err := mapper.MapType(pt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pyType.PyType).To(Equal("list"))
+		})
+
+		It("maps slices of numbers to buffers", func() {
 			elem := core.ParsedType{Kind: core.KindPrimitive, Name: "int"}
 			pt := core.ParsedType{Kind: core.KindSlice, Name: "[]int", ElemType: &elem}
 			pyType, err := mapper.MapType(pt)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(pyType.PyType).To(Equal("list"))
+			Expect(pyType.CtypesType).To(Equal("c_void_p"))
+			Expect(pyType.PyType).To(Equal("Any"))
 		})
 
 		It("returns error for slice without element type", func() {
@@ -129,8 +152,8 @@ var _ = Describe("TypeMapper", func() {
 
 	Describe("MapType array", func() {
 		It("maps fixed array", func() {
-			elem := core.ParsedType{Kind: core.KindPrimitive, Name: "int"}
-			pt := core.ParsedType{Kind: core.KindArray, Name: "[5]int", ElemType: &elem, Size: 5}
+			elem := core.ParsedType{Kind: core.KindPrimitive, Name: "bool"}
+			pt := core.ParsedType{Kind: core.KindArray, Name: "[5]bool", ElemType: &elem, Size: 5}
 			pyType, err := mapper.MapType(pt)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(pyType.PyType).To(Equal("list"))
@@ -174,5 +197,12 @@ var _ = Describe("TypeMapper", func() {
 			_, err := mapper.MapType(pt)
 			Expect(err).To(HaveOccurred())
 		})
+
+		It("returns error for iterators of unsupported elements", func() {
+			elem := core.ParsedType{Kind: core.KindSlice, Name: "[]int", ElemType: &core.ParsedType{Kind: core.KindPrimitive, Name: "int"}}
+			pt := core.ParsedType{Kind: core.KindIter, Name: "iter.Seq[[]int]", ElemType: &elem}
+			_, err := mapper.MapType(pt)
+			Expect(err).To(MatchError(ContainSubstring("iterator elements must be")))
+		})
 	})
 })
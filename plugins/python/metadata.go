@@ -0,0 +1,211 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package python
+
+import (
+	"bytes"
+	"debug/buildinfo"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/riceriley59/goanywhere/internal/core"
+)
+
+// defaultVersion is the package version when neither the version option nor
+// a VCS tag gives one
+const defaultVersion = "0.1.0"
+
+// packageMetadata describes the Python distribution of the bindings
+type packageMetadata struct {
+	Name           string
+	Version        string
+	Description    string
+	Authors        []string // "Name <email>", "Name" or "email"
+	License        string
+	Classifiers    []string
+	Dependencies   []string // PEP 508 requirements
+	RequiresPython string
+}
+
+// resolveMetadata fills in the defaults of the configured metadata for the
+// Python package pythonPkgName bound from the Go package in inputPath
+func (a *Plugin) resolveMetadata(pythonPkgName, inputPath string) packageMetadata {
+	meta := a.metadata
+	if meta.Name == "" {
+		meta.Name = pythonPkgName
+	}
+	if meta.Description == "" {
+		meta.Description = "Python bindings for " + pythonPkgName
+	}
+	if meta.Version == "" {
+		meta.Version = core.ModuleVersion(inputPath)
+	}
+	if meta.Version == "" {
+		meta.Version = defaultVersion
+	}
+	return meta
+}
+
+var nameSeparators = regexp.MustCompile(`[-_.]+`)
+
+// distName returns the distribution name as written in wheel file names
+func (m packageMetadata) distName() string {
+	return strings.ToLower(nameSeparators.ReplaceAllString(m.Name, "_"))
+}
+
+// author splits an author into its name and email
+func author(value string) (string, string) {
+	if name, email, ok := strings.Cut(value, "<"); ok {
+		return strings.TrimSpace(name), strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(email), ">"))
+	}
+	if strings.Contains(value, "@") && !strings.Contains(value, " ") {
+		return "", value
+	}
+	return value, ""
+}
+
+// quote returns s as a double-quoted string valid in TOML and Python
+func quote(s string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// goBuildInfo returns the build information embedded in the Go shared
+// library as a Python dict literal, or None if it has none
+func goBuildInfo(libFile string) string {
+	info, err := buildinfo.ReadFile(libFile)
+	if err != nil {
+		return "None"
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "{\n    \"go_version\": %s,\n    \"path\": %s,\n", quote(info.GoVersion), quote(info.Path))
+	fmt.Fprintf(&buf, "    \"main\": {\"path\": %s, \"version\": %s},\n", quote(info.Main.Path), quote(info.Main.Version))
+	buf.WriteString("    \"deps\": {\n")
+	for _, dep := range info.Deps {
+		version := dep.Version
+		if dep.Replace != nil {
+			version = dep.Replace.Path
+			if dep.Replace.Version != "" && dep.Replace.Version != "(devel)" {
+				version += "@" + dep.Replace.Version
+			}
+		}
+		fmt.Fprintf(&buf, "        %s: %s,\n", quote(dep.Path), quote(version))
+	}
+	buf.WriteString("    },\n    \"settings\": {\n")
+	for _, setting := range info.Settings {
+		fmt.Fprintf(&buf, "        %s: %s,\n", quote(setting.Key), quote(setting.Value))
+	}
+	buf.WriteString("    },\n}")
+	return buf.String()
+}
+
+// initModule returns the __init__.py of the Python package: the imports of
+// its modules, the package version and the build information of the library
+func initModule(doc, imports, version, libFile string) string {
+	return fmt.Sprintf(`"""%s"""
+%s
+
+__version__ = %s
+
+# Build information of the Go shared library (runtime/debug.BuildInfo)
+__go_build_info__ = %s
+`, doc, imports, quote(version), goBuildInfo(libFile))
+}
+
+// pyprojectProject returns the [project] table of pyproject.toml
+func pyprojectProject(meta packageMetadata) string {
+	var buf strings.Builder
+	buf.WriteString("[project]\n")
+	fmt.Fprintf(&buf, "name = %s\n", quote(meta.Name))
+	fmt.Fprintf(&buf, "version = %s\n", quote(meta.Version))
+	fmt.Fprintf(&buf, "description = %s\n", quote(meta.Description))
+	fmt.Fprintf(&buf, "requires-python = %s\n", quote(meta.RequiresPython))
+	if meta.License != "" {
+		fmt.Fprintf(&buf, "license = {text = %s}\n", quote(meta.License))
+	}
+	if len(meta.Authors) > 0 {
+		buf.WriteString("authors = [\n")
+		for _, value := range meta.Authors {
+			name, email := author(value)
+			var fields []string
+			if name != "" {
+				fields = append(fields, "name = "+quote(name))
+			}
+			if email != "" {
+				fields = append(fields, "email = "+quote(email))
+			}
+			fmt.Fprintf(&buf, "    {%s},\n", strings.Join(fields, ", "))
+		}
+		buf.WriteString("]\n")
+	}
+	writeTomlList(&buf, "classifiers", meta.Classifiers)
+	writeTomlList(&buf, "dependencies", meta.Dependencies)
+	return buf.String()
+}
+
+// writeTomlList writes a TOML array of strings, unless it is empty
+func writeTomlList(buf *strings.Builder, key string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	fmt.Fprintf(buf, "%s = [\n", key)
+	for _, value := range values {
+		fmt.Fprintf(buf, "    %s,\n", quote(value))
+	}
+	buf.WriteString("]\n")
+}
+
+// wheelMetadata returns the METADATA file of a wheel
+func wheelMetadata(meta packageMetadata) string {
+	var buf strings.Builder
+	buf.WriteString("Metadata-Version: 2.1\n")
+	fmt.Fprintf(&buf, "Name: %s\nVersion: %s\nSummary: %s\n", meta.Name, meta.Version, meta.Description)
+
+	var names, emails []string
+	for _, value := range meta.Authors {
+		name, email := author(value)
+		switch {
+		case email == "":
+			names = append(names, name)
+		case name == "":
+			emails = append(emails, email)
+		default:
+			emails = append(emails, fmt.Sprintf("%s <%s>", quote(name), email))
+		}
+	}
+	if len(names) > 0 {
+		fmt.Fprintf(&buf, "Author: %s\n", strings.Join(names, ", "))
+	}
+	if len(emails) > 0 {
+		fmt.Fprintf(&buf, "Author-email: %s\n", strings.Join(emails, ", "))
+	}
+	if meta.License != "" {
+		fmt.Fprintf(&buf, "License: %s\n", meta.License)
+	}
+	for _, classifier := range meta.Classifiers {
+		fmt.Fprintf(&buf, "Classifier: %s\n", classifier)
+	}
+	fmt.Fprintf(&buf, "Requires-Python: %s\n", meta.RequiresPython)
+	for _, dep := range meta.Dependencies {
+		fmt.Fprintf(&buf, "Requires-Dist: %s\n", dep)
+	}
+	return buf.String()
+}
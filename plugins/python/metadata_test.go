@@ -0,0 +1,90 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package python
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Metadata", func() {
+	Describe("resolveMetadata", func() {
+		It("defaults the name, description and version", func() {
+			meta := NewPlugin(false).resolveMetadata("geo", GinkgoT().TempDir())
+			Expect(meta.Name).To(Equal("geo"))
+			Expect(meta.Description).To(Equal("Python bindings for geo"))
+			Expect(meta.Version).To(Equal(defaultVersion))
+			Expect(meta.distName()).To(Equal("geo"))
+		})
+	})
+
+	Describe("pyprojectProject", func() {
+		It("writes the configured metadata", func() {
+			content := pyprojectProject(packageMetadata{
+				Name:           "geo-bindings",
+				Version:        "1.0.0",
+				Description:    "Geometry",
+				Authors:        []string{"Ada <ada@example.com>", "grace@example.com"},
+				License:        "MIT",
+				Classifiers:    []string{"Programming Language :: Python :: 3"},
+				Dependencies:   []string{"numpy>=1.20,<2"},
+				RequiresPython: ">=3.9",
+			})
+			Expect(content).To(ContainSubstring("name = \"geo-bindings\"\nversion = \"1.0.0\"\n"))
+			Expect(content).To(ContainSubstring("requires-python = \">=3.9\"\nlicense = {text = \"MIT\"}\n"))
+			Expect(content).To(ContainSubstring("    {name = \"Ada\", email = \"ada@example.com\"},\n    {email = \"grace@example.com\"},\n"))
+			Expect(content).To(ContainSubstring("classifiers = [\n    \"Programming Language :: Python :: 3\",\n]\n"))
+			Expect(content).To(ContainSubstring("dependencies = [\n    \"numpy>=1.20,<2\",\n]\n"))
+		})
+	})
+
+	Describe("wheelMetadata", func() {
+		It("writes core metadata fields", func() {
+			content := wheelMetadata(packageMetadata{
+				Name:           "geo",
+				Version:        "1.0.0",
+				Description:    "Geometry",
+				Authors:        []string{"Ada <ada@example.com>", "Grace"},
+				Dependencies:   []string{"numpy"},
+				RequiresPython: ">=3.8",
+			})
+			Expect(content).To(Equal(`Metadata-Version: 2.1
+Name: geo
+Version: 1.0.0
+Summary: Geometry
+Author: Grace
+Author-email: "Ada" <ada@example.com>
+Requires-Python: >=3.8
+Requires-Dist: numpy
+`))
+		})
+	})
+
+	Describe("initModule", func() {
+		It("exposes the version and the Go build info", func() {
+			content := initModule("Python bindings for geo", "from .bindings import *", "1.0.0", "/nonexistent")
+			Expect(content).To(ContainSubstring("__version__ = \"1.0.0\"\n"))
+			Expect(content).To(ContainSubstring("__go_build_info__ = None\n"))
+
+			exe, err := os.Executable()
+			Expect(err).NotTo(HaveOccurred())
+			content = initModule("Python bindings for geo", "from .bindings import *", "1.0.0", exe)
+			Expect(content).To(ContainSubstring("__go_build_info__ = {\n    \"go_version\": \"go"))
+			Expect(content).To(ContainSubstring("\"settings\": {"))
+		})
+	})
+})
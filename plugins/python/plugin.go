@@ -16,7 +16,9 @@ package python
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"go/token"
 	"os"
 	"path/filepath"
 	"strings"
@@ -25,8 +27,15 @@ import (
 	"github.com/riceriley59/goanywhere/internal/core/factory"
 )
 
-// Ensure Plugin implements core.Plugin interface
-var _ core.Plugin = (*Plugin)(nil)
+// Ensure Plugin implements core.Plugin, core.Configurable, core.Bundler,
+// core.BindingReporter and core.Diagnoser interfaces
+var (
+	_ core.Plugin          = (*Plugin)(nil)
+	_ core.Configurable    = (*Plugin)(nil)
+	_ core.Bundler         = (*Plugin)(nil)
+	_ core.BindingReporter = (*Plugin)(nil)
+	_ core.Diagnoser       = (*Plugin)(nil)
+)
 
 func init() {
 	factory.Register("python", func(verbose bool) core.Plugin {
@@ -34,17 +43,43 @@ func init() {
 	})
 }
 
+// buildSystems lists the supported Python build systems
+var buildSystems = []string{"setuptools", "hatch", "poetry", "uv"}
+
 // Plugin implements the core.Plugin interface for Python ctypes
 type Plugin struct {
-	verbose bool
-	mapper  *TypeMapper
-	pkg     *core.ParsedPackage
+	verbose     bool
+	mapper      *TypeMapper
+	pkg         *core.ParsedPackage
+	bundle      []*core.ParsedPackage // Sibling packages sharing the library (empty unless bundling)
+	libName     string                // Shared library name without the lib prefix
+	buildSystem string
+	async       bool              // Also generate async def variants awaiting _Async exports
+	backend     string            // How wrappers call the library: ctypes or capi
+	python      string            // Interpreter the capi extension is compiled for
+	metadata    packageMetadata   // Configured distribution metadata
+	bindings    []core.Binding    // Python API written by the last generate
+	natives     []nativeExport    // Exports called by the C extension of the last generate
+	diags       []core.Diagnostic // Declarations dropped by the last generate
 }
 
+// Reasons declarations are dropped from the Python API
+var (
+	errVariadic        = errors.New("variadic parameters are not supported")
+	errMultipleResults = errors.New("multiple return values are not supported")
+)
+
+// errGenerateCapi rejects generating capi bindings without their extension
+var errGenerateCapi = errors.New("the capi backend requires build, which compiles the C extension the bindings import; use the ctypes backend with generate")
+
 // NewPlugin creates a new Python Plugin
 func NewPlugin(verbose bool) *Plugin {
 	return &Plugin{
-		verbose: verbose,
+		verbose:     verbose,
+		buildSystem: "setuptools",
+		backend:     "ctypes",
+		python:      "python3",
+		metadata:    packageMetadata{RequiresPython: ">=3.8"},
 	}
 }
 
@@ -53,10 +88,99 @@ func (a *Plugin) Name() string {
 	return "python"
 }
 
-// Generate produces Python ctypes wrapper code for the given parsed package
+// Options declares the Python plugin options
+func (a *Plugin) Options() []core.OptionSpec {
+	return []core.OptionSpec{
+		{
+			Name:        "build-system",
+			Type:        core.OptionString,
+			Default:     "setuptools",
+			Choices:     buildSystems,
+			Description: "Python build system used for the generated pyproject.toml",
+		},
+		{Name: "name", Type: core.OptionString, Description: "Distribution name (default: the Go package name)"},
+		{Name: "version", Type: core.OptionString, Description: "Package version (default: the module's VCS tag, or " + defaultVersion + ")"},
+		{Name: "description", Type: core.OptionString, Description: "One-line package summary"},
+		{Name: "authors", Type: core.OptionList, Description: "Authors as \"Name <email>\", one per line"},
+		{Name: "license", Type: core.OptionString, Description: "License of the package, such as an SPDX expression"},
+		{Name: "classifiers", Type: core.OptionList, Description: "Trove classifiers, one per line"},
+		{Name: "dependencies", Type: core.OptionList, Description: "PEP 508 requirements, one per line"},
+		{Name: "requires-python", Type: core.OptionString, Default: ">=3.8", Description: "Supported Python versions"},
+		{Name: "async", Type: core.OptionBool, Description: "Also generate <name>_async coroutines running Go calls on goroutines (needs a library built with the cgo async option)"},
+		{
+			Name:        "backend",
+			Type:        core.OptionString,
+			Default:     "ctypes",
+			Choices:     backends,
+			Description: "How the bindings call the library: ctypes, or a C extension module built with the package",
+		},
+		{Name: "python", Type: core.OptionString, Default: "python3", Description: "Python interpreter the capi backend compiles its extension for"},
+	}
+}
+
+// Configure applies parsed Python plugin options
+func (a *Plugin) Configure(opts core.Options) error {
+	if buildSystem := opts.String("build-system"); buildSystem != "" {
+		a.buildSystem = buildSystem
+	}
+	a.metadata = packageMetadata{
+		Name:           opts.String("name"),
+		Version:        opts.String("version"),
+		Description:    opts.String("description"),
+		Authors:        opts.List("authors"),
+		License:        opts.String("license"),
+		Classifiers:    opts.List("classifiers"),
+		Dependencies:   opts.List("dependencies"),
+		RequiresPython: opts.String("requires-python"),
+	}
+	a.async = opts.Bool("async")
+	if backend := opts.String("backend"); backend != "" {
+		a.backend = backend
+	}
+	if python := opts.String("python"); python != "" {
+		a.python = python
+	}
+	return nil
+}
+
+// Generate produces Python ctypes wrapper code for the given parsed package.
+// Bindings of the capi backend are only written by Build, together with the
+// C extension they import.
 func (a *Plugin) Generate(pkg *core.ParsedPackage) ([]byte, error) {
+	if a.backend == "capi" {
+		return nil, errGenerateCapi
+	}
+	return a.generate(pkg, nil, pkg.Name)
+}
+
+// ReportBindings describes the Python API Generate writes for each
+// declaration of pkg and the declarations it drops
+func (a *Plugin) ReportBindings(pkg *core.ParsedPackage) (*core.BindingReport, error) {
+	if _, err := a.generate(pkg, nil, pkg.Name); err != nil {
+		return nil, err
+	}
+	return &core.BindingReport{Bindings: a.bindings, Diagnostics: a.diags}, nil
+}
+
+// GenerateBundleModule produces the module of one package of a bundle. All
+// modules load the same shared library and import their sibling modules, so
+// handles returned by one package are wrapped in the other package's classes.
+func (a *Plugin) GenerateBundleModule(pkg *core.ParsedPackage, pkgs []*core.ParsedPackage, libName string) ([]byte, error) {
+	return a.generate(pkg, pkgs, strings.TrimPrefix(libName, "lib"))
+}
+
+// generate writes the bindings of pkg, loading the library named libName
+func (a *Plugin) generate(pkg *core.ParsedPackage, bundle []*core.ParsedPackage, libName string) ([]byte, error) {
 	a.pkg = pkg
+	a.bundle = bundle
+	a.libName = libName
+	a.bindings = nil
+	a.natives = nil
+	a.diags = nil
 	a.mapper = NewTypeMapper(pkg.Structs)
+	for _, other := range a.siblings() {
+		a.mapper.AddPackage(other.ImportPath, "_"+other.Name)
+	}
 
 	var buf bytes.Buffer
 
@@ -77,15 +201,11 @@ func (a *Plugin) Generate(pkg *core.ParsedPackage) ([]byte, error) {
 	// Write function wrappers
 	for _, fn := range pkg.Functions {
 		if fn.IsVariadic {
-			if a.verbose {
-				fmt.Printf("Skipping variadic function: %s\n", fn.Name)
-			}
+			a.skip(fn.Pos, fn.Name, core.DeclFunction, errVariadic)
 			continue
 		}
 		if err := a.writeFunction(&buf, fn); err != nil {
-			if a.verbose {
-				fmt.Printf("Skipping function %s: %v\n", fn.Name, err)
-			}
+			a.skip(fn.Pos, fn.Name, core.DeclFunction, err)
 			continue
 		}
 	}
@@ -93,9 +213,7 @@ func (a *Plugin) Generate(pkg *core.ParsedPackage) ([]byte, error) {
 	// Write class wrappers for structs
 	for _, st := range pkg.Structs {
 		if err := a.writeClass(&buf, st); err != nil {
-			if a.verbose {
-				fmt.Printf("Skipping struct %s: %v\n", st.Name, err)
-			}
+			a.skip(st.Pos, st.Name, core.DeclStruct, err)
 			continue
 		}
 	}
@@ -103,11 +221,37 @@ func (a *Plugin) Generate(pkg *core.ParsedPackage) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// skip records a diagnostic for a declaration the generated module does not bind
+func (a *Plugin) skip(pos token.Position, symbol string, kind core.DeclKind, err error) {
+	a.diags = append(a.diags, core.Skipped(pos, symbol, kind, err))
+}
+
+// Diagnostics returns the declarations dropped by the last Generate or Build
+func (a *Plugin) Diagnostics() []core.Diagnostic {
+	return a.diags
+}
+
+// bind records part of the Python API generated for a declaration
+func (a *Plugin) bind(symbol string, kind core.DeclKind, name, signature string) {
+	a.bindings = append(a.bindings, core.Binding{Decl: core.Decl{Symbol: symbol, Kind: kind}, Name: name, Signature: signature})
+}
+
+// valueResults counts the results that are not errors
+func valueResults(results []core.ParsedResult) int {
+	n := 0
+	for _, result := range results {
+		if result.Type.Kind != core.KindError {
+			n++
+		}
+	}
+	return n
+}
+
 // writeHeader writes the Python file header
 func (a *Plugin) writeHeader(buf *bytes.Buffer) {
 	buf.WriteString(`"""
 Generated by goanywhere - Python ctypes bindings
-Source: ` + a.pkg.Dir + `
+Source: ` + a.pkg.Source() + `
 
 This module provides Python bindings for the Go package using ctypes.
 Requires the shared library to be built first using the CGO plugin.
@@ -121,9 +265,12 @@ Usage:
 """
 
 from __future__ import annotations
-import ctypes
+` + a.asyncImport() + `import ctypes
+import dataclasses
+import json
 import os
 import sys
+import weakref
 from ctypes import (
     c_bool, c_char_p, c_double, c_float, c_void_p, c_size_t,
     c_int8, c_int16, c_int32, c_int64,
@@ -131,9 +278,28 @@ from ctypes import (
     c_longlong, c_ulonglong,
     POINTER, byref, cast,
 )
-from typing import Optional, Any, List
-
+from typing import Optional, Any, Dict, Iterator, List, Tuple
 `)
+
+	// Sibling modules of a bundle
+	for _, other := range a.siblings() {
+		fmt.Fprintf(buf, "from . import %s as _%s\n", other.Name, other.Name)
+	}
+	if a.backend == "capi" {
+		a.writeNativeImport(buf)
+	}
+	buf.WriteString("\n")
+}
+
+// siblings returns the other packages of the bundle being generated
+func (a *Plugin) siblings() []*core.ParsedPackage {
+	var others []*core.ParsedPackage
+	for _, other := range a.bundle {
+		if other.ImportPath != a.pkg.ImportPath {
+			others = append(others, other)
+		}
+	}
+	return others
 }
 
 // writeLibraryLoader writes the library loading code
@@ -165,7 +331,7 @@ def load_library(path: Optional[str] = None) -> ctypes.CDLL:
         return _lib
 
     # Search for library in common locations
-    lib_name = "` + a.pkg.Name + `"
+    lib_name = "` + a.libName + `"
     search_paths = []
 
     # Current directory
@@ -179,14 +345,16 @@ def load_library(path: Optional[str] = None) -> ctypes.CDLL:
         search_paths.append(f"./lib{lib_name}.so")
         search_paths.append(f"lib{lib_name}.so")
 
-    # Directory of this Python file
+    # Directory of this Python file and its lib directory, where built
+    # packages and wheels keep the library
     this_dir = os.path.dirname(os.path.abspath(__file__))
-    if sys.platform == "darwin":
-        search_paths.append(os.path.join(this_dir, f"lib{lib_name}.dylib"))
-    elif sys.platform == "win32":
-        search_paths.append(os.path.join(this_dir, f"{lib_name}.dll"))
-    else:
-        search_paths.append(os.path.join(this_dir, f"lib{lib_name}.so"))
+    for lib_dir in (this_dir, os.path.join(this_dir, "lib")):
+        if sys.platform == "darwin":
+            search_paths.append(os.path.join(lib_dir, f"lib{lib_name}.dylib"))
+        elif sys.platform == "win32":
+            search_paths.append(os.path.join(lib_dir, f"{lib_name}.dll"))
+        else:
+            search_paths.append(os.path.join(lib_dir, f"lib{lib_name}.so"))
 
     for lib_path in search_paths:
         try:
@@ -209,6 +377,9 @@ def get_library() -> ctypes.CDLL:
     return _lib
 
 `)
+	if a.backend == "capi" {
+		buf.WriteString("_native.set_loader(get_library)\n\n")
+	}
 }
 
 // writeSetupFunctions writes the function signature setup
@@ -221,18 +392,25 @@ def _setup_functions(lib: ctypes.CDLL) -> None:
     lib.Free_String.restype = None
     lib.Free_Bytes.argtypes = [c_void_p]
     lib.Free_Bytes.restype = None
-
 `)
+	if a.async && a.pkg.TakesContexts() {
+		writeContextSetup(buf)
+	}
+	if a.pkg.ReturnsIterators() {
+		buf.WriteString("    lib.Iter_Stop.argtypes = [c_size_t]\n")
+		buf.WriteString("    lib.Iter_Stop.restype = None\n")
+		buf.WriteString("    lib.Iter_Free.argtypes = [c_size_t]\n")
+		buf.WriteString("    lib.Iter_Free.restype = None\n")
+	}
+	buf.WriteString("\n")
 
 	// Setup package functions
 	for _, fn := range a.pkg.Functions {
-		if fn.IsVariadic {
+		if fn.IsVariadic || valueResults(fn.Results) > 1 {
 			continue
 		}
 		if err := a.writeFunctionSetup(buf, fn); err != nil {
-			if a.verbose {
-				fmt.Printf("Skipping function setup %s: %v\n", fn.Name, err)
-			}
+			// writeFunction reports the function as skipped
 			continue
 		}
 	}
@@ -242,24 +420,30 @@ def _setup_functions(lib: ctypes.CDLL) -> None:
 		a.writeStructSetup(buf, st)
 	}
 
+	// Hand the exports to the C extension
+	if a.backend == "capi" {
+		buf.WriteString("    _native.bind([cast(getattr(lib, name), c_void_p).value for name in _native.SYMBOLS])\n")
+	}
+
 	buf.WriteString("\n")
 	return nil
 }
 
 // writeFunctionSetup writes argtypes/restype for a function
 func (a *Plugin) writeFunctionSetup(buf *bytes.Buffer, fn core.ParsedFunc) error {
-	cFuncName := a.pkg.Name + "_" + fn.Name
+	cFuncName := a.pkg.FuncSymbol(fn.Name)
 
 	// Collect argtypes
 	var argtypes []string
 	var hasError bool
 
 	for _, param := range fn.Params {
-		pyType, err := a.mapper.MapType(param.Type)
+		pyType, err := a.mapper.MapParam(param.Type)
 		if err != nil {
 			return err
 		}
 		argtypes = append(argtypes, pyType.CtypesType)
+		argtypes = append(argtypes, bufferArgtypes(param.Type)...)
 	}
 
 	// Check for error return
@@ -272,6 +456,9 @@ func (a *Plugin) writeFunctionSetup(buf *bytes.Buffer, fn core.ParsedFunc) error
 			returnType = &result.Type
 		}
 	}
+	if returnType != nil {
+		argtypes = append(argtypes, bufferOutArgtypes(*returnType)...)
+	}
 
 	// Write argtypes
 	fmt.Fprintf(buf, "    lib.%s.argtypes = [%s]\n", cFuncName, strings.Join(argtypes, ", "))
@@ -282,14 +469,18 @@ func (a *Plugin) writeFunctionSetup(buf *bytes.Buffer, fn core.ParsedFunc) error
 		if err != nil {
 			return err
 		}
-		// Use CtypesReturnType if available (for strings), otherwise CtypesType
-		restype := pyType.CtypesType
-		if pyType.CtypesReturnType != "" {
-			restype = pyType.CtypesReturnType
+		fmt.Fprintf(buf, "    lib.%s.restype = %s\n", cFuncName, restype(pyType))
+		if returnType.Kind == core.KindIter {
+			a.writeIterSetup(buf, cFuncName, *returnType)
+		}
+		if a.async {
+			writeAsyncSetup(buf, cFuncName, argtypes, restype(pyType))
 		}
-		fmt.Fprintf(buf, "    lib.%s.restype = %s\n", cFuncName, restype)
 	} else {
 		fmt.Fprintf(buf, "    lib.%s.restype = None\n", cFuncName)
+		if a.async {
+			writeAsyncSetup(buf, cFuncName, argtypes, "")
+		}
 	}
 
 	// Add blank line if there was an error param for readability
@@ -302,7 +493,7 @@ func (a *Plugin) writeFunctionSetup(buf *bytes.Buffer, fn core.ParsedFunc) error
 
 // writeStructSetup writes argtypes/restype for struct functions
 func (a *Plugin) writeStructSetup(buf *bytes.Buffer, st core.ParsedStruct) {
-	prefix := st.Name
+	prefix := a.pkg.StructPrefix(st.Name)
 
 	// Constructor
 	fmt.Fprintf(buf, "    lib.%s_New.argtypes = []\n", prefix)
@@ -323,13 +514,9 @@ func (a *Plugin) writeStructSetup(buf *bytes.Buffer, st core.ParsedStruct) {
 			continue
 		}
 
-		// Getter - use CtypesReturnType if available
-		restype := pyType.CtypesType
-		if pyType.CtypesReturnType != "" {
-			restype = pyType.CtypesReturnType
-		}
-		fmt.Fprintf(buf, "    lib.%s_Get%s.argtypes = [c_size_t]\n", prefix, field.Name)
-		fmt.Fprintf(buf, "    lib.%s_Get%s.restype = %s\n", prefix, field.Name, restype)
+		// Getter
+		fmt.Fprintf(buf, "    lib.%s_Get%s.argtypes = [%s]\n", prefix, field.Name, strings.Join(append([]string{"c_size_t"}, bufferOutArgtypes(field.Type)...), ", "))
+		fmt.Fprintf(buf, "    lib.%s_Get%s.restype = %s\n", prefix, field.Name, restype(pyType))
 
 		// Setter (skip for complex types)
 		if !pyType.IsHandle && field.Type.Kind != core.KindSlice && field.Type.Kind != core.KindMap {
@@ -340,7 +527,7 @@ func (a *Plugin) writeStructSetup(buf *bytes.Buffer, st core.ParsedStruct) {
 
 	// Methods
 	for _, method := range st.Methods {
-		if method.IsVariadic {
+		if method.IsVariadic || valueResults(method.Results) > 1 {
 			continue
 		}
 
@@ -350,11 +537,12 @@ func (a *Plugin) writeStructSetup(buf *bytes.Buffer, st core.ParsedStruct) {
 		argtypes := []string{"c_size_t"}
 
 		for _, param := range method.Params {
-			pyType, err := a.mapper.MapType(param.Type)
+			pyType, err := a.mapper.MapParam(param.Type)
 			if err != nil {
 				continue
 			}
 			argtypes = append(argtypes, pyType.CtypesType)
+			argtypes = append(argtypes, bufferArgtypes(param.Type)...)
 		}
 
 		// Check for error return
@@ -366,6 +554,9 @@ func (a *Plugin) writeStructSetup(buf *bytes.Buffer, st core.ParsedStruct) {
 				returnType = &result.Type
 			}
 		}
+		if returnType != nil {
+			argtypes = append(argtypes, bufferOutArgtypes(*returnType)...)
+		}
 
 		fmt.Fprintf(buf, "    lib.%s.argtypes = [%s]\n", cFuncName, strings.Join(argtypes, ", "))
 
@@ -375,17 +566,25 @@ func (a *Plugin) writeStructSetup(buf *bytes.Buffer, st core.ParsedStruct) {
 				fmt.Fprintf(buf, "    lib.%s.restype = None\n", cFuncName)
 				continue
 			}
-			// Use CtypesReturnType if available
-			restype := pyType.CtypesType
-			if pyType.CtypesReturnType != "" {
-				restype = pyType.CtypesReturnType
+			fmt.Fprintf(buf, "    lib.%s.restype = %s\n", cFuncName, restype(pyType))
+			if returnType.Kind == core.KindIter {
+				a.writeIterSetup(buf, cFuncName, *returnType)
+			}
+			if a.async {
+				writeAsyncSetup(buf, cFuncName, argtypes, restype(pyType))
 			}
-			fmt.Fprintf(buf, "    lib.%s.restype = %s\n", cFuncName, restype)
 		} else {
 			fmt.Fprintf(buf, "    lib.%s.restype = None\n", cFuncName)
+			if a.async {
+				writeAsyncSetup(buf, cFuncName, argtypes, "")
+			}
 		}
 	}
 
+	if a.pkg.HasData(st) {
+		a.writeDataSetup(buf, prefix)
+	}
+
 	buf.WriteString("\n")
 }
 
@@ -405,6 +604,34 @@ def _decode_string(ptr: Optional[int]) -> Optional[str]:
     # Cast void pointer to char pointer and decode
     return ctypes.cast(ptr, c_char_p).value.decode('utf-8')
 
+# Live wrappers by handle. The library interns handles per Go pointer, so a
+# pointer crossing the boundary again finds the object already wrapping it.
+_instances: "weakref.WeakValueDictionary[int, Any]" = weakref.WeakValueDictionary()
+
+def _release_handle(free: Any, handle: int, parent: Any = None) -> None:
+    """Release a handle once its last Python reference is gone.
+
+    The finalizer holding these arguments keeps parent alive until then, so
+    a value inside a Go struct never outlives the struct's wrapper.
+    """
+    free(handle)
+
+def _as_dict(data: Any) -> Dict[str, Any]:
+    """Accept a snapshot dataclass wherever a dict of fields is expected."""
+    if dataclasses.is_dataclass(data) and not isinstance(data, type):
+        return {f.name: getattr(data, f.name) for f in dataclasses.fields(data)}
+    return data
+
+def _as_float(value: Any) -> Any:
+    """Restore floats that JSON encoded without a fraction, in lists and dicts too."""
+    if isinstance(value, list):
+        return [_as_float(item) for item in value]
+    if isinstance(value, dict):
+        return {key: _as_float(item) for key, item in value.items()}
+    if isinstance(value, int) and not isinstance(value, bool):
+        return float(value)
+    return value
+
 def _check_error(error_ptr: ctypes.Array) -> None:
     """Check for and raise any error from a C function call."""
     # error_ptr is POINTER(c_char_p), we need to check the underlying pointer
@@ -417,19 +644,48 @@ def _check_error(error_ptr: ctypes.Array) -> None:
         raise RuntimeError(error_msg)
 
 `)
+	if a.pkg.ReturnsIterators() {
+		a.writeIterHelpers(buf)
+	}
+	if a.pkg.UsesBuffers() {
+		a.writeBufferHelpers(buf)
+	}
+	if a.async {
+		a.writeAsyncHelpers(buf)
+	}
 }
 
-// writeFunction writes a wrapper for a package-level function
+// writeFunction writes a wrapper for a package-level function, followed by
+// its async variant when enabled
 func (a *Plugin) writeFunction(buf *bytes.Buffer, fn core.ParsedFunc) error {
-	cFuncName := a.pkg.Name + "_" + fn.Name
+	if err := a.writeFunctionWrapper(buf, fn, false); err != nil {
+		return err
+	}
+	if a.async {
+		return a.writeFunctionWrapper(buf, fn, true)
+	}
+	return nil
+}
+
+// writeFunctionWrapper writes the wrapper of a package-level function, or
+// the coroutine awaiting its _Async export
+func (a *Plugin) writeFunctionWrapper(buf *bytes.Buffer, fn core.ParsedFunc, async bool) error {
+	if valueResults(fn.Results) > 1 {
+		return errMultipleResults
+	}
+
+	cFuncName := a.pkg.FuncSymbol(fn.Name)
 	pyFuncName := toSnakeCase(fn.Name)
+	if async {
+		pyFuncName += "_async"
+	}
 
 	// Collect parameter info
 	var params []paramInfo
 	var hasError bool
 
 	for i, param := range fn.Params {
-		pyType, err := a.mapper.MapType(param.Type)
+		pyType, err := a.mapper.MapParam(param.Type)
 		if err != nil {
 			return err
 		}
@@ -457,6 +713,9 @@ func (a *Plugin) writeFunction(buf *bytes.Buffer, fn core.ParsedFunc) error {
 	// Build function signature
 	var typeHints []string
 	for _, p := range params {
+		if p.goType.IsContext() {
+			continue
+		}
 		typeHints = append(typeHints, fmt.Sprintf("%s: %s", p.name, p.pyType.PyType))
 	}
 
@@ -468,26 +727,40 @@ func (a *Plugin) writeFunction(buf *bytes.Buffer, fn core.ParsedFunc) error {
 		}
 		// For struct pointers, use the struct name as the type hint
 		if pyType.IsHandle && returnType.Type.Kind == core.KindPointer && returnType.Type.ElemType != nil {
-			returnHint = returnType.Type.ElemType.Name
+			returnHint = a.mapper.className(*returnType.Type.ElemType)
 		} else {
 			returnHint = pyType.PyType
 		}
 	}
 
 	// Write function
-	fmt.Fprintf(buf, "\ndef %s(%s) -> %s:\n", pyFuncName, strings.Join(typeHints, ", "), returnHint)
+	signature := fmt.Sprintf("%s(%s) -> %s", pyFuncName, strings.Join(typeHints, ", "), returnHint)
+	a.bind(fn.Name, core.DeclFunction, pyFuncName, asyncKeyword(async)+signature)
+	fmt.Fprintf(buf, "\n%sdef %s:\n", asyncKeyword(async), signature)
 
 	// Docstring
 	if fn.Doc != "" {
 		fmt.Fprintf(buf, "    \"\"\"%s\"\"\"\n", strings.TrimSpace(fn.Doc))
 	}
 
+	// Call through the C extension when it converts every type
+	if !async {
+		if export, ok := a.nativeCall(cFuncName, false, fn.Params, fn.Results); ok {
+			a.writeNativeCall(buf, "    ", export, false, params, returnType)
+			buf.WriteString("\n")
+			return nil
+		}
+	}
+
 	buf.WriteString("    lib = get_library()\n")
 
 	// Convert input parameters
+	bufferArgs := make(map[string][]string)
 	for _, p := range params {
 		if p.goType.Kind == core.KindString {
 			fmt.Fprintf(buf, "    _%s = _encode_string(%s)\n", p.name, p.name)
+		} else if p.goType.IsBuffer() {
+			bufferArgs[p.name] = writeBufferArg(buf, "    ", p.name, p.goType)
 		}
 	}
 
@@ -499,8 +772,12 @@ func (a *Plugin) writeFunction(buf *bytes.Buffer, fn core.ParsedFunc) error {
 	// Build call arguments
 	var callArgs []string
 	for _, p := range params {
-		if p.goType.Kind == core.KindString {
+		if p.goType.IsContext() {
+			callArgs = append(callArgs, contextArg(async))
+		} else if p.goType.Kind == core.KindString {
 			callArgs = append(callArgs, "_"+p.name)
+		} else if args, ok := bufferArgs[p.name]; ok {
+			callArgs = append(callArgs, args...)
 		} else if p.pyType.IsHandle {
 			callArgs = append(callArgs, p.name+"._handle")
 		} else {
@@ -510,37 +787,20 @@ func (a *Plugin) writeFunction(buf *bytes.Buffer, fn core.ParsedFunc) error {
 	if hasError {
 		callArgs = append(callArgs, "_error")
 	}
-
-	// Make the call
 	if returnType != nil {
-		fmt.Fprintf(buf, "    _result = lib.%s(%s)\n", cFuncName, strings.Join(callArgs, ", "))
-	} else {
-		fmt.Fprintf(buf, "    lib.%s(%s)\n", cFuncName, strings.Join(callArgs, ", "))
+		callArgs = append(callArgs, writeBufferOut(buf, "    ", returnType.Type)...)
 	}
 
-	// Check error
-	if hasError {
-		buf.WriteString("    _check_error(_error)\n")
-	}
-
-	// Return result
-	if returnType != nil {
-		pyType, _ := a.mapper.MapType(returnType.Type)
-		if returnType.Type.Kind == core.KindString {
-			buf.WriteString("    _ret = _decode_string(_result)\n")
-			buf.WriteString("    lib.Free_String(_result)\n")
-			buf.WriteString("    return _ret\n")
-		} else if pyType.IsHandle {
-			// Get the class name (strip pointer prefix if present)
-			className := returnType.Type.Name
-			if returnType.Type.Kind == core.KindPointer && returnType.Type.ElemType != nil {
-				className = returnType.Type.ElemType.Name
-			}
-			fmt.Fprintf(buf, "    return %s._from_handle(_result)\n", className)
-		} else {
-			buf.WriteString("    return _result\n")
-		}
-	}
+	a.writeCall(buf, "    ", wrapperCall{
+		symbol:      cFuncName,
+		args:        callArgs,
+		keep:        bufferOwners(params),
+		result:      returnType,
+		hasError:    hasError,
+		hint:        returnHint,
+		async:       async,
+		cancellable: core.TakesContext(fn.Params),
+	})
 
 	buf.WriteString("\n")
 	return nil
@@ -549,7 +809,9 @@ func (a *Plugin) writeFunction(buf *bytes.Buffer, fn core.ParsedFunc) error {
 // writeClass writes a wrapper class for a struct
 func (a *Plugin) writeClass(buf *bytes.Buffer, st core.ParsedStruct) error {
 	className := st.Name
+	prefix := a.pkg.StructPrefix(st.Name)
 
+	a.bind(st.Name, core.DeclStruct, className, "class "+className)
 	fmt.Fprintf(buf, "\nclass %s:\n", className)
 
 	// Docstring
@@ -558,39 +820,40 @@ func (a *Plugin) writeClass(buf *bytes.Buffer, st core.ParsedStruct) error {
 	} else {
 		fmt.Fprintf(buf, "    \"\"\"Wrapper for Go %s struct.\"\"\"\n", st.Name)
 	}
+	buf.WriteString("\n")
+	if a.pkg.HasData(st) {
+		a.writeDataClass(buf, st)
+	}
 
 	// Constructor
 	buf.WriteString(`
     def __init__(self):
         """Create a new instance."""
-        lib = get_library()
-        self._handle = lib.` + className + `_New()
-        self._owned = True
+        self._attach(get_library().` + prefix + `_New())
 
     @classmethod
-    def _from_handle(cls, handle: int) -> "` + className + `":
-        """Create an instance from an existing handle."""
+    def _from_handle(cls, handle: int, parent: Any = None) -> "` + className + `":
+        """Create an instance owning a handle; parent is kept alive with it."""
+        instance = _instances.get(handle)
+        if instance is not None:
+            # The live instance already holds a reference to the handle
+            get_library().` + prefix + `_Free(handle)
+            return instance
         instance = object.__new__(cls)
-        instance._handle = handle
-        instance._owned = False
+        instance._attach(handle, parent)
         return instance
 
-    def __del__(self):
-        """Release the handle when garbage collected."""
-        if hasattr(self, '_owned') and self._owned and hasattr(self, '_handle'):
-            try:
-                lib = get_library()
-                lib.` + className + `_Free(self._handle)
-            except:
-                pass
+    def _attach(self, handle: int, parent: Any = None) -> None:
+        self._handle = handle
+        self._finalizer = weakref.finalize(self, _release_handle, get_library().` + prefix + `_Free, handle, parent)
+        _instances[handle] = self
 
     def close(self) -> None:
         """Explicitly release the handle."""
-        if self._owned and self._handle:
-            lib = get_library()
-            lib.` + className + `_Free(self._handle)
-            self._handle = 0
-            self._owned = False
+        if _instances.get(self._handle) is self:
+            del _instances[self._handle]
+        self._finalizer()
+        self._handle = 0
 
     def __enter__(self) -> "` + className + `":
         return self
@@ -600,6 +863,10 @@ func (a *Plugin) writeClass(buf *bytes.Buffer, st core.ParsedStruct) error {
 
 `)
 
+	if a.pkg.HasData(st) {
+		a.writeDataMethods(buf, st)
+	}
+
 	// Properties for exported fields
 	for _, field := range st.Fields {
 		if !field.Exported {
@@ -608,42 +875,77 @@ func (a *Plugin) writeClass(buf *bytes.Buffer, st core.ParsedStruct) error {
 
 		pyType, err := a.mapper.MapType(field.Type)
 		if err != nil {
-			if a.verbose {
-				fmt.Printf("Skipping field %s.%s: %v\n", st.Name, field.Name, err)
-			}
+			a.skip(field.Pos, st.Name+"."+field.Name, core.DeclField, err)
 			continue
 		}
 
 		propName := toSnakeCase(field.Name)
-		getFuncName := className + "_Get" + field.Name
-		setFuncName := className + "_Set" + field.Name
+		settable := !pyType.IsHandle && field.Type.Kind != core.KindSlice && field.Type.Kind != core.KindMap
+		propSignature := propName + ": " + pyType.PyType
+		if !settable {
+			propSignature += " (read-only)"
+		}
+		a.bind(st.Name+"."+field.Name, core.DeclField, className+"."+propName, propSignature)
+		getFuncName := prefix + "_Get" + field.Name
+		setFuncName := prefix + "_Set" + field.Name
 
 		// Getter
 		buf.WriteString("    @property\n")
 		fmt.Fprintf(buf, "    def %s(self) -> %s:\n", propName, pyType.PyType)
 		fmt.Fprintf(buf, "        \"\"\"Get %s.\"\"\"\n", field.Name)
-		buf.WriteString("        lib = get_library()\n")
+		getter := []core.ParsedResult{{Type: field.Type, Ownership: field.Ownership}}
+		_, native := a.nativeCall(getFuncName, true, nil, getter)
+		if !native {
+			buf.WriteString("        lib = get_library()\n")
+		}
 
-		if field.Type.Kind == core.KindString {
+		if native {
+			a.writeNativeReturn(buf, "        ", "_native."+getFuncName+"(self._handle)", &getter[0])
+		} else if field.Type.IsBuffer() {
+			args := append([]string{"self._handle"}, writeBufferOut(buf, "        ", field.Type)...)
+			fmt.Fprintf(buf, "        _result = lib.%s(%s)\n", getFuncName, strings.Join(args, ", "))
+			fmt.Fprintf(buf, "        return %s\n", bufferResult(field.Type))
+		} else if field.Type.Kind == core.KindString {
 			fmt.Fprintf(buf, "        _result = lib.%s(self._handle)\n", getFuncName)
 			buf.WriteString("        _ret = _decode_string(_result)\n")
 			buf.WriteString("        lib.Free_String(_result)\n")
 			buf.WriteString("        return _ret\n")
+		} else if pyType.IsHandle {
+			// Struct fields are children of self, pointed-to structs are owned
+			fmt.Fprintf(buf, "        _result = lib.%s(self._handle)\n", getFuncName)
+			className := a.mapper.className(field.Type)
+			if field.Type.Kind == core.KindPointer && field.Type.ElemType != nil {
+				className = a.mapper.className(*field.Type.ElemType)
+			}
+			if field.Ownership == core.OwnershipChild {
+				fmt.Fprintf(buf, "        return %s._from_handle(_result, self)\n", className)
+			} else {
+				fmt.Fprintf(buf, "        return %s._from_handle(_result)\n", className)
+			}
 		} else {
 			fmt.Fprintf(buf, "        return lib.%s(self._handle)\n", getFuncName)
 		}
 		buf.WriteString("\n")
 
 		// Setter (skip for complex types)
-		if !pyType.IsHandle && field.Type.Kind != core.KindSlice && field.Type.Kind != core.KindMap {
+		if settable {
 			fmt.Fprintf(buf, "    @%s.setter\n", propName)
 			fmt.Fprintf(buf, "    def %s(self, value: %s) -> None:\n", propName, pyType.PyType)
 			fmt.Fprintf(buf, "        \"\"\"Set %s.\"\"\"\n", field.Name)
-			buf.WriteString("        lib = get_library()\n")
+			setter := []core.ParsedParam{{Name: "value", Type: field.Type}}
+			_, native := a.nativeCall(setFuncName, true, setter, nil)
+			if !native {
+				buf.WriteString("        lib = get_library()\n")
+			}
 
-			if field.Type.Kind == core.KindString {
+			if native {
+				fmt.Fprintf(buf, "        _native.%s(self._handle, value)\n", setFuncName)
+			} else if field.Type.Kind == core.KindString {
 				buf.WriteString("        _value = _encode_string(value)\n")
 				fmt.Fprintf(buf, "        lib.%s(self._handle, _value)\n", setFuncName)
+			} else if field.Type.IsBuffer() {
+				args := writeBufferArg(buf, "        ", "value", field.Type)
+				fmt.Fprintf(buf, "        lib.%s(self._handle, %s)\n", setFuncName, strings.Join(args, ", "))
 			} else {
 				fmt.Fprintf(buf, "        lib.%s(self._handle, value)\n", setFuncName)
 			}
@@ -652,35 +954,55 @@ func (a *Plugin) writeClass(buf *bytes.Buffer, st core.ParsedStruct) error {
 	}
 
 	// Methods
+	var bound []core.ParsedMethod
 	for _, method := range st.Methods {
 		if method.IsVariadic {
-			if a.verbose {
-				fmt.Printf("Skipping variadic method: %s.%s\n", st.Name, method.Name)
-			}
+			a.skip(method.Pos, st.Name+"."+method.Name, core.DeclMethod, errVariadic)
 			continue
 		}
 		if err := a.writeMethod(buf, st, method); err != nil {
-			if a.verbose {
-				fmt.Printf("Skipping method %s.%s: %v\n", st.Name, method.Name, err)
-			}
+			a.skip(method.Pos, st.Name+"."+method.Name, core.DeclMethod, err)
 			continue
 		}
+		bound = append(bound, method)
 	}
 
+	a.writeSpecialMethods(buf, st, bound)
+
 	return nil
 }
 
-// writeMethod writes a method wrapper
+// writeMethod writes a method wrapper, followed by its async variant when
+// enabled
 func (a *Plugin) writeMethod(buf *bytes.Buffer, st core.ParsedStruct, method core.ParsedMethod) error {
-	cFuncName := st.Name + "_" + method.Name
+	if err := a.writeMethodWrapper(buf, st, method, false); err != nil {
+		return err
+	}
+	if a.async {
+		return a.writeMethodWrapper(buf, st, method, true)
+	}
+	return nil
+}
+
+// writeMethodWrapper writes the wrapper of a method, or the coroutine
+// awaiting its _Async export
+func (a *Plugin) writeMethodWrapper(buf *bytes.Buffer, st core.ParsedStruct, method core.ParsedMethod, async bool) error {
+	if valueResults(method.Results) > 1 {
+		return errMultipleResults
+	}
+
+	cFuncName := a.pkg.StructPrefix(st.Name) + "_" + method.Name
 	pyMethodName := toSnakeCase(method.Name)
+	if async {
+		pyMethodName += "_async"
+	}
 
 	// Collect parameter info
 	var params []paramInfo
 	var hasError bool
 
 	for i, param := range method.Params {
-		pyType, err := a.mapper.MapType(param.Type)
+		pyType, err := a.mapper.MapParam(param.Type)
 		if err != nil {
 			return err
 		}
@@ -709,6 +1031,9 @@ func (a *Plugin) writeMethod(buf *bytes.Buffer, st core.ParsedStruct, method cor
 	var typeHints []string
 	typeHints = append(typeHints, "self")
 	for _, p := range params {
+		if p.goType.IsContext() {
+			continue
+		}
 		typeHints = append(typeHints, fmt.Sprintf("%s: %s", p.name, p.pyType.PyType))
 	}
 
@@ -720,26 +1045,40 @@ func (a *Plugin) writeMethod(buf *bytes.Buffer, st core.ParsedStruct, method cor
 		}
 		// For struct pointers, use the struct name as the type hint
 		if pyType.IsHandle && returnType.Type.Kind == core.KindPointer && returnType.Type.ElemType != nil {
-			returnHint = returnType.Type.ElemType.Name
+			returnHint = a.mapper.className(*returnType.Type.ElemType)
 		} else {
 			returnHint = pyType.PyType
 		}
 	}
 
 	// Write method
-	fmt.Fprintf(buf, "    def %s(%s) -> %s:\n", pyMethodName, strings.Join(typeHints, ", "), returnHint)
+	signature := fmt.Sprintf("%s(%s) -> %s", pyMethodName, strings.Join(typeHints, ", "), returnHint)
+	a.bind(st.Name+"."+method.Name, core.DeclMethod, st.Name+"."+pyMethodName, asyncKeyword(async)+signature)
+	fmt.Fprintf(buf, "    %sdef %s:\n", asyncKeyword(async), signature)
 
 	// Docstring
 	if method.Doc != "" {
 		fmt.Fprintf(buf, "        \"\"\"%s\"\"\"\n", strings.TrimSpace(method.Doc))
 	}
 
+	// Call through the C extension when it converts every type
+	if !async {
+		if export, ok := a.nativeCall(cFuncName, true, method.Params, method.Results); ok {
+			a.writeNativeCall(buf, "        ", export, true, params, returnType)
+			buf.WriteString("\n")
+			return nil
+		}
+	}
+
 	buf.WriteString("        lib = get_library()\n")
 
 	// Convert input parameters
+	bufferArgs := make(map[string][]string)
 	for _, p := range params {
 		if p.goType.Kind == core.KindString {
 			fmt.Fprintf(buf, "        _%s = _encode_string(%s)\n", p.name, p.name)
+		} else if p.goType.IsBuffer() {
+			bufferArgs[p.name] = writeBufferArg(buf, "        ", p.name, p.goType)
 		}
 	}
 
@@ -752,8 +1091,12 @@ func (a *Plugin) writeMethod(buf *bytes.Buffer, st core.ParsedStruct, method cor
 	var callArgs []string
 	callArgs = append(callArgs, "self._handle")
 	for _, p := range params {
-		if p.goType.Kind == core.KindString {
+		if p.goType.IsContext() {
+			callArgs = append(callArgs, contextArg(async))
+		} else if p.goType.Kind == core.KindString {
 			callArgs = append(callArgs, "_"+p.name)
+		} else if args, ok := bufferArgs[p.name]; ok {
+			callArgs = append(callArgs, args...)
 		} else if p.pyType.IsHandle {
 			callArgs = append(callArgs, p.name+"._handle")
 		} else {
@@ -763,40 +1106,102 @@ func (a *Plugin) writeMethod(buf *bytes.Buffer, st core.ParsedStruct, method cor
 	if hasError {
 		callArgs = append(callArgs, "_error")
 	}
-
-	// Make the call
 	if returnType != nil {
-		fmt.Fprintf(buf, "        _result = lib.%s(%s)\n", cFuncName, strings.Join(callArgs, ", "))
+		callArgs = append(callArgs, writeBufferOut(buf, "        ", returnType.Type)...)
+	}
+
+	a.writeCall(buf, "        ", wrapperCall{
+		symbol:      cFuncName,
+		args:        callArgs,
+		keep:        bufferOwners(params),
+		result:      returnType,
+		hasError:    hasError,
+		hint:        returnHint,
+		async:       async,
+		cancellable: core.TakesContext(method.Params),
+	})
+
+	buf.WriteString("\n")
+	return nil
+}
+
+// wrapperCall describes the call of an export by a wrapper
+type wrapperCall struct {
+	symbol      string
+	args        []string
+	keep        []string // Locals the export reads through addresses in args
+	result      *core.ParsedResult
+	hasError    bool
+	hint        string // Python type hint of the result
+	async       bool   // Start the _Async export and await it
+	cancellable bool   // The function takes a context.Context
+}
+
+// writeCall writes the call of an export and the return of its converted
+// result. Async wrappers start the _Async export instead and convert the
+// result in _finish once the call is done.
+func (a *Plugin) writeCall(buf *bytes.Buffer, indent string, call wrapperCall) {
+	captures := call.result != nil && call.result.Ownership != core.OwnershipBorrowed
+	body := indent
+	if call.async {
+		if call.result != nil {
+			pyType, _ := a.mapper.MapType(call.result.Type)
+			fmt.Fprintf(buf, "%s_out = %s()\n", indent, restype(pyType))
+		}
+		fmt.Fprintf(buf, "%sdef _finish() -> %s:\n", indent, call.hint)
+		body = indent + "    "
+		if captures {
+			fmt.Fprintf(buf, "%s_result = _out.value\n", body)
+		}
+	} else if captures {
+		fmt.Fprintf(buf, "%s_result = lib.%s(%s)\n", indent, call.symbol, strings.Join(call.args, ", "))
 	} else {
-		fmt.Fprintf(buf, "        lib.%s(%s)\n", cFuncName, strings.Join(callArgs, ", "))
+		fmt.Fprintf(buf, "%slib.%s(%s)\n", indent, call.symbol, strings.Join(call.args, ", "))
 	}
 
 	// Check error
-	if hasError {
-		buf.WriteString("        _check_error(_error)\n")
+	if call.hasError {
+		fmt.Fprintf(buf, "%s_check_error(_error)\n", body)
 	}
 
 	// Return result
-	if returnType != nil {
-		pyType, _ := a.mapper.MapType(returnType.Type)
-		if returnType.Type.Kind == core.KindString {
-			buf.WriteString("        _ret = _decode_string(_result)\n")
-			buf.WriteString("        lib.Free_String(_result)\n")
-			buf.WriteString("        return _ret\n")
-		} else if pyType.IsHandle {
-			// Get the class name (strip pointer prefix if present)
-			className := returnType.Type.Name
-			if returnType.Type.Kind == core.KindPointer && returnType.Type.ElemType != nil {
-				className = returnType.Type.ElemType.Name
-			}
-			fmt.Fprintf(buf, "        return %s._from_handle(_result)\n", className)
-		} else {
-			buf.WriteString("        return _result\n")
-		}
+	if call.result != nil {
+		a.writeReturn(buf, body, call.symbol, *call.result)
+	} else if call.async && !call.hasError {
+		fmt.Fprintf(buf, "%sreturn None\n", body)
 	}
 
-	buf.WriteString("\n")
-	return nil
+	if call.async {
+		a.writeAwait(buf, indent, call)
+	}
+}
+
+// writeReturn writes the return of the result in _result, converted to
+// its Python value
+func (a *Plugin) writeReturn(buf *bytes.Buffer, indent, symbol string, result core.ParsedResult) {
+	pyType, _ := a.mapper.MapType(result.Type)
+	switch {
+	case result.Type.Kind == core.KindIter:
+		a.writeIterReturn(buf, indent, symbol, result.Type)
+	case result.Type.IsBuffer():
+		fmt.Fprintf(buf, "%sreturn %s\n", indent, bufferResult(result.Type))
+	case result.Type.Kind == core.KindString:
+		fmt.Fprintf(buf, "%s_ret = _decode_string(_result)\n", indent)
+		fmt.Fprintf(buf, "%slib.Free_String(_result)\n", indent)
+		fmt.Fprintf(buf, "%sreturn _ret\n", indent)
+	case pyType.IsHandle && result.Ownership == core.OwnershipBorrowed:
+		// The method returned its receiver
+		fmt.Fprintf(buf, "%sreturn self\n", indent)
+	case pyType.IsHandle:
+		// Get the class name (strip pointer prefix if present)
+		className := a.mapper.className(result.Type)
+		if result.Type.Kind == core.KindPointer && result.Type.ElemType != nil {
+			className = a.mapper.className(*result.Type.ElemType)
+		}
+		fmt.Fprintf(buf, "%sreturn %s._from_handle(_result)\n", indent, className)
+	default:
+		fmt.Fprintf(buf, "%sreturn _result\n", indent)
+	}
 }
 
 // paramInfo holds information about a function parameter
@@ -824,32 +1229,182 @@ func toSnakeCase(s string) string {
 
 // Build generates Python bindings and creates a distributable Python package
 func (a *Plugin) Build(pkg *core.ParsedPackage, inputPath string, opts *core.BuildOptions) error {
-	// First, build the CGO shared library (Python needs it)
-	if opts.Verbose {
-		fmt.Println("Building CGO shared library for Python bindings...")
+	if err := requireSharedLink(opts); err != nil {
+		return err
 	}
 
-	// Get CGO plugin and build the shared library
-	cgoPlugin, err := factory.Get("cgo", opts.Verbose)
+	// Generate Python bindings
+	if opts.Verbose {
+		fmt.Println("Generating Python bindings...")
+	}
+	code, err := a.generate(pkg, nil, pkg.Name)
 	if err != nil {
-		return fmt.Errorf("failed to get CGO plugin: %w", err)
+		return fmt.Errorf("generation error: %w", err)
+	}
+	if err := opts.HandleDiagnostics(a.diags); err != nil {
+		return err
+	}
+
+	// Build the CGO shared library (Python needs it) unless it was built
+	// for another plugin already
+	libFile := opts.SharedLibrary
+	if libFile == "" {
+		if opts.Verbose {
+			fmt.Println("Building CGO shared library for Python bindings...")
+		}
+
+		// Get CGO plugin and build the shared library
+		cgoPlugin, err := a.libraryPlugin(opts.Verbose)
+		if err != nil {
+			return err
+		}
+
+		if err := cgoPlugin.Build(pkg, inputPath, libraryOptions(opts)); err != nil {
+			return fmt.Errorf("failed to build CGO library: %w", err)
+		}
 	}
 
-	if err := cgoPlugin.Build(pkg, inputPath, opts); err != nil {
-		return fmt.Errorf("failed to build CGO library: %w", err)
+	// Create Python package structure
+	pythonPkgName := strings.ReplaceAll(pkg.Name, "-", "_")
+	pyPkg := pyPackage{
+		name:    pythonPkgName,
+		doc:     "Python bindings for " + pkg.Name,
+		imports: "from .bindings import *",
+		modules: []pyModule{{file: "bindings.py", code: code}},
+		meta:    a.resolveMetadata(pythonPkgName, inputPath),
+	}
+	if a.backend == "capi" {
+		pyPkg.extensions = []pyExtension{{name: a.extensionName(), source: a.extensionSource()}}
+	}
+
+	libName := opts.LibraryName
+	if libName == "" {
+		libName = "lib" + pkg.Name
+	}
+
+	if libFile == "" {
+		libFile = filepath.Join(opts.OutputDir, libName+getSharedLibExtension())
+	}
+
+	return a.writePackage(pyPkg, libName, libFile, opts)
+}
+
+// BuildBundle builds one shared library for several packages and a Python
+// package with one module per Go package
+func (a *Plugin) BuildBundle(pkgs []*core.ParsedPackage, inputPaths []string, opts *core.BuildOptions) error {
+	if err := requireSharedLink(opts); err != nil {
+		return err
 	}
 
-	// Generate Python bindings
 	if opts.Verbose {
 		fmt.Println("Generating Python bindings...")
 	}
-	code, err := a.Generate(pkg)
+	libName := core.BundleLibraryName(pkgs, opts.LibraryName)
+	names := make([]string, len(pkgs))
+	modules := make([]pyModule, len(pkgs))
+	var extensions []pyExtension
+	var diags []core.Diagnostic
+	for i, pkg := range pkgs {
+		code, err := a.GenerateBundleModule(pkg, pkgs, libName)
+		if err != nil {
+			return fmt.Errorf("generation error for %s: %w", pkg.Name, err)
+		}
+		names[i] = pkg.Name
+		modules[i] = pyModule{file: pkg.Name + ".py", code: code}
+		if a.backend == "capi" {
+			extensions = append(extensions, pyExtension{name: a.extensionName(), source: a.extensionSource()})
+		}
+		diags = append(diags, a.diags...)
+	}
+	a.diags = diags
+	if err := opts.HandleDiagnostics(diags); err != nil {
+		return err
+	}
+
+	libFile := opts.SharedLibrary
+	if libFile == "" {
+		if opts.Verbose {
+			fmt.Println("Building CGO shared library for Python bindings...")
+		}
+
+		cgoPlugin, err := a.libraryPlugin(opts.Verbose)
+		if err != nil {
+			return err
+		}
+		bundler, ok := cgoPlugin.(core.Bundler)
+		if !ok {
+			return fmt.Errorf("CGO plugin cannot build bundles")
+		}
+		if err := bundler.BuildBundle(pkgs, inputPaths, libraryOptions(opts)); err != nil {
+			return fmt.Errorf("failed to build CGO library: %w", err)
+		}
+		libFile = filepath.Join(opts.OutputDir, libName+getSharedLibExtension())
+	}
+
+	pythonPkgName := strings.ReplaceAll(strings.TrimPrefix(libName, "lib"), "-", "_")
+	pyPkg := pyPackage{
+		name:       pythonPkgName,
+		doc:        "Python bindings for " + strings.Join(names, ", "),
+		imports:    "from . import " + strings.Join(names, ", "),
+		modules:    modules,
+		extensions: extensions,
+		meta:       a.resolveMetadata(pythonPkgName, inputPaths[0]),
+	}
+
+	return a.writePackage(pyPkg, libName, libFile, opts)
+}
+
+// libraryPlugin returns the CGO plugin building the library the bindings
+// load, exporting the _Async variants the async wrappers await
+func (a *Plugin) libraryPlugin(verbose bool) (core.Plugin, error) {
+	cgoPlugin, err := factory.Get("cgo", verbose)
 	if err != nil {
-		return fmt.Errorf("generation error: %w", err)
+		return nil, fmt.Errorf("failed to get CGO plugin: %w", err)
 	}
+	if a.async {
+		if err := core.ApplyOptions(cgoPlugin, map[string]string{"async": "true"}); err != nil {
+			return nil, err
+		}
+	}
+	return cgoPlugin, nil
+}
 
-	// Create Python package structure
-	pythonPkgName := strings.ReplaceAll(pkg.Name, "-", "_")
+// libraryOptions returns the options of the CGO library build. Diagnostics
+// are left to the Python bindings, which wrap the library.
+func libraryOptions(opts *core.BuildOptions) *core.BuildOptions {
+	libOpts := *opts
+	libOpts.Diagnostics = nil
+	return &libOpts
+}
+
+// requireSharedLink rejects static builds; ctypes loads a shared library
+func requireSharedLink(opts *core.BuildOptions) error {
+	if opts.Link != "" && opts.Link != core.LinkShared {
+		return fmt.Errorf("python plugin requires a shared library; --link %s is not supported", opts.Link)
+	}
+	return nil
+}
+
+// pyModule is a generated module of a Python package
+type pyModule struct {
+	file string
+	code []byte
+}
+
+// pyPackage is a Python package built around a shared library
+type pyPackage struct {
+	name       string // Import name
+	doc        string // Docstring of __init__.py
+	imports    string // Import statement of __init__.py
+	modules    []pyModule
+	extensions []pyExtension // C extensions of the capi backend
+	meta       packageMetadata
+}
+
+// writePackage lays out the Python package around the shared library
+// already built at libFile
+func (a *Plugin) writePackage(pyPkg pyPackage, libName, libFile string, opts *core.BuildOptions) error {
+	pythonPkgName := pyPkg.name
 	pkgDir := filepath.Join(opts.OutputDir, pythonPkgName)
 	libDir := filepath.Join(pkgDir, "lib")
 
@@ -858,59 +1413,64 @@ func (a *Plugin) Build(pkg *core.ParsedPackage, inputPath string, opts *core.Bui
 	}
 
 	// Write Python bindings
-	bindingsFile := filepath.Join(pkgDir, "bindings.py")
-	if err := os.WriteFile(bindingsFile, code, 0644); err != nil {
-		return fmt.Errorf("write error: %w", err)
+	for _, module := range pyPkg.modules {
+		bindingsFile := filepath.Join(pkgDir, module.file)
+		if err := os.WriteFile(bindingsFile, module.code, 0644); err != nil {
+			return fmt.Errorf("write error: %w", err)
+		}
+		fmt.Printf("Generated Python bindings: %s\n", bindingsFile)
 	}
-	fmt.Printf("Generated Python bindings: %s\n", bindingsFile)
 
-	// Write __init__.py
-	initContent := fmt.Sprintf(`"""Python bindings for %s"""
-from .bindings import *
-`, pkg.Name)
-	initFile := filepath.Join(pkgDir, "__init__.py")
-	if err := os.WriteFile(initFile, []byte(initContent), 0644); err != nil {
-		return fmt.Errorf("write error: %w", err)
+	// Compile the C extensions, which tie the wheel to one interpreter
+	interpreter := ""
+	if len(pyPkg.extensions) > 0 {
+		cfg, err := loadPythonConfig(a.python)
+		if err != nil {
+			return err
+		}
+		for _, ext := range pyPkg.extensions {
+			if err := compileExtension(ext, pkgDir, cfg, opts); err != nil {
+				return err
+			}
+		}
+		interpreter = cfg.tag
 	}
 
 	// Copy shared library to lib directory
-	libName := opts.LibraryName
-	if libName == "" {
-		libName = "lib" + pkg.Name
+	dstLib := filepath.Join(libDir, libName+getSharedLibExtension())
+	if err := copyFile(libFile, dstLib); err != nil {
+		return fmt.Errorf("failed to copy library: %w", err)
 	}
-	libExt := getSharedLibExtension()
-	srcLib := filepath.Join(opts.OutputDir, libName+libExt)
-	dstLib := filepath.Join(libDir, libName+libExt)
 
-	if err := copyFile(srcLib, dstLib); err != nil {
-		return fmt.Errorf("failed to copy library: %w", err)
+	// Write __init__.py with the version and the build info of the library
+	initFile := filepath.Join(pkgDir, "__init__.py")
+	initContent := initModule(pyPkg.doc, pyPkg.imports, pyPkg.meta.Version, dstLib)
+	if err := os.WriteFile(initFile, []byte(initContent), 0644); err != nil {
+		return fmt.Errorf("write error: %w", err)
 	}
 
 	// Generate pyproject.toml based on build system
-	pyprojectContent := generatePyprojectToml(pythonPkgName, opts.BuildSystem)
+	pyprojectContent := generatePyprojectToml(pyPkg.meta, pythonPkgName, a.buildSystem)
 	pyprojectFile := filepath.Join(opts.OutputDir, "pyproject.toml")
 	if err := os.WriteFile(pyprojectFile, []byte(pyprojectContent), 0644); err != nil {
 		return fmt.Errorf("write error: %w", err)
 	}
 	fmt.Printf("Generated pyproject.toml: %s\n", pyprojectFile)
 
+	// Build the wheel, which needs no Python toolchain
+	wheelFile, err := writeWheel(filepath.Join(opts.OutputDir, "dist"), pkgDir, pythonPkgName, dstLib, interpreter, pyPkg.meta)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Built wheel: %s\n", wheelFile)
+
 	// Print instructions
 	fmt.Printf("\nPython package created at: %s\n", opts.OutputDir)
 	fmt.Println("\nTo install the package:")
+	fmt.Printf("  pip install %s\n", wheelFile)
+	fmt.Println("\nTo install it for development:")
 	fmt.Printf("  cd %s\n", opts.OutputDir)
 	fmt.Println("  pip install -e .")
-	fmt.Println("\nTo build a distributable package:")
-	switch opts.BuildSystem {
-	case "hatch":
-		fmt.Println("  hatch build")
-	case "poetry":
-		fmt.Println("  poetry build")
-	case "uv":
-		fmt.Println("  uv build")
-	default:
-		fmt.Println("  pip install build")
-		fmt.Println("  python -m build")
-	}
 
 	return nil
 }
@@ -948,43 +1508,31 @@ func copyFile(src, dst string) error {
 }
 
 // generatePyprojectToml generates the pyproject.toml content based on build system
-func generatePyprojectToml(pkgName, buildSystem string) string {
+func generatePyprojectToml(meta packageMetadata, pkgName, buildSystem string) string {
+	project := pyprojectProject(meta)
 	switch buildSystem {
 	case "hatch":
 		return fmt.Sprintf(`[build-system]
 requires = ["hatchling"]
 build-backend = "hatchling.build"
 
-[project]
-name = "%s"
-version = "0.1.0"
-description = "Python bindings for %s"
-requires-python = ">=3.8"
-
+%s
 [tool.hatch.build.targets.wheel]
 packages = ["%s"]
 
 [tool.hatch.build.targets.wheel.shared-data]
 "%s/lib" = "lib"
-`, pkgName, pkgName, pkgName, pkgName)
+`, project, pkgName, pkgName)
 
 	case "poetry":
 		return fmt.Sprintf(`[build-system]
-requires = ["poetry-core"]
+requires = ["poetry-core>=2.0"]
 build-backend = "poetry.core.masonry.api"
 
+%s
 [tool.poetry]
-name = "%s"
-version = "0.1.0"
-description = "Python bindings for %s"
-authors = []
-
-[tool.poetry.dependencies]
-python = ">=3.8"
-
-[tool.poetry.packages]
-include = "%s"
-`, pkgName, pkgName, pkgName)
+packages = [{include = "%s"}]
+`, project, pkgName)
 
 	case "uv":
 		// uv is compatible with standard pyproject.toml (setuptools or hatch)
@@ -992,32 +1540,22 @@ include = "%s"
 requires = ["hatchling"]
 build-backend = "hatchling.build"
 
-[project]
-name = "%s"
-version = "0.1.0"
-description = "Python bindings for %s"
-requires-python = ">=3.8"
-
+%s
 [tool.hatch.build.targets.wheel]
 packages = ["%s"]
-`, pkgName, pkgName, pkgName)
+`, project, pkgName)
 
 	default: // setuptools
 		return fmt.Sprintf(`[build-system]
 requires = ["setuptools>=61.0"]
 build-backend = "setuptools.build_meta"
 
-[project]
-name = "%s"
-version = "0.1.0"
-description = "Python bindings for %s"
-requires-python = ">=3.8"
-
+%s
 [tool.setuptools.packages.find]
 where = ["."]
 
 [tool.setuptools.package-data]
-"%s" = ["lib/*"]
-`, pkgName, pkgName, pkgName)
+"%s" = ["lib/*", "*.so", "*.pyd"]
+`, project, pkgName)
 	}
 }
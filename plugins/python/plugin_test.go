@@ -16,6 +16,8 @@ package python
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -49,6 +51,46 @@ var _ = Describe("Plugin", func() {
 		})
 	})
 
+	Describe("Options", func() {
+		It("declares build-system with supported choices", func() {
+			specs := plugin.Options()
+			Expect(specs[0].Name).To(Equal("build-system"))
+			Expect(specs[0].Default).To(Equal("setuptools"))
+			Expect(specs[0].Choices).To(ConsistOf("setuptools", "hatch", "poetry", "uv"))
+		})
+
+		It("defaults to setuptools", func() {
+			Expect(plugin.buildSystem).To(Equal("setuptools"))
+		})
+
+		It("applies a configured build system", func() {
+			Expect(core.ApplyOptions(plugin, map[string]string{"build-system": "poetry"})).To(Succeed())
+			Expect(plugin.buildSystem).To(Equal("poetry"))
+		})
+
+		It("applies package metadata", func() {
+			Expect(core.ApplyOptions(plugin, map[string]string{
+				"name":         "geo-bindings",
+				"version":      "2.0.0",
+				"authors":      "Ada <ada@example.com>\nGrace",
+				"dependencies": "numpy>=1.20,<2",
+			})).To(Succeed())
+			Expect(plugin.metadata).To(Equal(packageMetadata{
+				Name:           "geo-bindings",
+				Version:        "2.0.0",
+				Authors:        []string{"Ada <ada@example.com>", "Grace"},
+				Dependencies:   []string{"numpy>=1.20,<2"},
+				RequiresPython: ">=3.8",
+			}))
+		})
+
+		It("rejects unsupported build systems", func() {
+			err := core.ApplyOptions(plugin, map[string]string{"build-system": "maven"})
+			Expect(err).To(HaveOccurred())
+			Expect(plugin.buildSystem).To(Equal("setuptools"))
+		})
+	})
+
 	Describe("Generate", func() {
 		It("generates valid Python code for simple package", func() {
 			pkg := &core.ParsedPackage{
@@ -99,7 +141,11 @@ var _ = Describe("Plugin", func() {
 			codeStr := string(code)
 			Expect(codeStr).To(ContainSubstring("class Point"))
 			Expect(codeStr).To(ContainSubstring("def __init__"))
-			Expect(codeStr).To(ContainSubstring("def __del__"))
+			Expect(codeStr).To(ContainSubstring("weakref.finalize(self, _release_handle, get_library().Point_Free, handle, parent)"))
+			Expect(codeStr).NotTo(ContainSubstring("def __del__"))
+			Expect(codeStr).To(ContainSubstring("instance = _instances.get(handle)"))
+			Expect(codeStr).To(ContainSubstring("def __eq__(self, other: object) -> bool:"))
+			Expect(codeStr).To(ContainSubstring("return hash(self._handle)"))
 			Expect(codeStr).To(ContainSubstring("@property"))
 			Expect(codeStr).To(ContainSubstring("def x(self)"))
 		})
@@ -133,6 +179,347 @@ var _ = Describe("Plugin", func() {
 			Expect(codeStr).To(ContainSubstring("def distance(self)"))
 		})
 
+		It("follows the ownership of returned handles", func() {
+			point := core.ParsedType{Kind: core.KindStruct, Name: "Point"}
+			pointPtr := core.ParsedType{Kind: core.KindPointer, Name: "*Point", ElemType: &point}
+			pkg := &core.ParsedPackage{
+				Name:       "test",
+				ImportPath: "github.com/test/test",
+				Functions: []core.ParsedFunc{
+					{Name: "NewPoint", Results: []core.ParsedResult{{Type: pointPtr, Ownership: core.OwnershipOwned}}},
+				},
+				Structs: []core.ParsedStruct{
+					{
+						Name: "Point",
+						Methods: []core.ParsedMethod{
+							{Name: "Scale", ReceiverType: "Point", ReceiverIsPtr: true, Results: []core.ParsedResult{{Type: pointPtr, Ownership: core.OwnershipBorrowed}}},
+						},
+					},
+					{
+						Name: "Line",
+						Fields: []core.ParsedField{
+							{Name: "Start", Type: point, Exported: true, Ownership: core.OwnershipChild},
+							{Name: "End", Type: pointPtr, Exported: true, Ownership: core.OwnershipOwned},
+						},
+					},
+				},
+			}
+
+			code, err := plugin.Generate(pkg)
+			Expect(err).NotTo(HaveOccurred())
+
+			codeStr := string(code)
+			Expect(codeStr).To(ContainSubstring("def new_point() -> Point:\n    lib = get_library()\n    _result = lib.test_NewPoint()\n    return Point._from_handle(_result)\n"))
+			Expect(codeStr).To(ContainSubstring("        lib.Point_Scale(self._handle)\n        return self\n"))
+			Expect(codeStr).To(ContainSubstring("_result = lib.Line_GetStart(self._handle)\n        return Point._from_handle(_result, self)\n"))
+			Expect(codeStr).To(ContainSubstring("_result = lib.Line_GetEnd(self._handle)\n        return Point._from_handle(_result)\n"))
+		})
+
+		It("converts struct data in one call", func() {
+			point := core.ParsedType{Kind: core.KindStruct, Name: "Point"}
+			str := core.ParsedType{Kind: core.KindString, Name: "string"}
+			pkg := &core.ParsedPackage{
+				Name:       "test",
+				ImportPath: "github.com/test/test",
+				Structs: []core.ParsedStruct{
+					{Name: "Point", Fields: []core.ParsedField{{Name: "X", Type: core.ParsedType{Kind: core.KindPrimitive, Name: "int"}, Exported: true}}},
+					{Name: "Reading", Fields: []core.ParsedField{
+						{Name: "Tags", Type: core.ParsedType{Kind: core.KindSlice, Name: "[]string", ElemType: &str}, Exported: true},
+						{Name: "Pos", Type: point, Exported: true},
+						{Name: "Owner", Type: core.ParsedType{Kind: core.KindPointer, Name: "*Point", ElemType: &point}, Exported: true},
+						{Name: "Level", Type: core.ParsedType{Kind: core.KindPrimitive, Name: "float64"}, Exported: true},
+						{Name: "Samples", Type: core.ParsedType{Kind: core.KindSlice, Name: "[]float32", ElemType: &core.ParsedType{Kind: core.KindPrimitive, Name: "float32"}}, Exported: true},
+					}},
+					{Name: "Link", Fields: []core.ParsedField{
+						{Name: "Target", Type: core.ParsedType{Kind: core.KindPointer, Name: "*Point", ElemType: &point}, Exported: true},
+					}},
+				},
+			}
+
+			code, err := plugin.Generate(pkg)
+			Expect(err).NotTo(HaveOccurred())
+
+			codeStr := string(code)
+			Expect(codeStr).To(ContainSubstring("    @dataclasses.dataclass(frozen=True)\n    class Data:\n        \"\"\"Snapshot of the data fields of Reading.\"\"\"\n        tags: tuple\n        pos: Point.Data\n"))
+			Expect(codeStr).To(ContainSubstring(`return {"tags": data["Tags"], "pos": Point._dict_from_json(data["Pos"]), "level": _as_float(data["Level"]), "samples": _as_float(data["Samples"])}`))
+			Expect(codeStr).To(ContainSubstring(`return Reading.Data(tags=tuple(data["Tags"] or ()), pos=Point._data_from_json(data["Pos"]), level=_as_float(data["Level"]), samples=tuple(_as_float(data["Samples"]) or ()))`))
+			Expect(codeStr).To(ContainSubstring(`_json["Pos"] = Point._json_from_dict(_as_dict(value))`))
+			Expect(codeStr).To(ContainSubstring("_result = lib.Reading_FromJSON(_encode_string(json.dumps(cls._json_from_dict(_as_dict(data)))), _error)"))
+			Expect(codeStr).To(ContainSubstring("def snapshot(self) -> Reading.Data:"))
+			Expect(codeStr).To(ContainSubstring("lib.Reading_ToJSON.restype = c_void_p"))
+			Expect(codeStr).NotTo(ContainSubstring("owner:"))
+
+			// Structs holding only handles get no conversions rather than an empty dict
+			link := codeStr[strings.Index(codeStr, "class Link:"):]
+			Expect(link).NotTo(ContainSubstring("def to_dict"))
+			Expect(link).NotTo(ContainSubstring("class Data:"))
+			Expect(codeStr).NotTo(ContainSubstring("Link_ToJSON"))
+		})
+
+		It("maps Go methods onto Python special methods by signature", func() {
+			point := core.ParsedType{Kind: core.KindStruct, Name: "Point"}
+			intType := core.ParsedType{Kind: core.KindPrimitive, Name: "int"}
+			boolType := core.ParsedType{Kind: core.KindPrimitive, Name: "bool"}
+			str := core.ParsedType{Kind: core.KindString, Name: "string"}
+			pkg := &core.ParsedPackage{
+				Name:       "test",
+				ImportPath: "github.com/test/test",
+				Structs: []core.ParsedStruct{
+					{
+						Name: "Point",
+						Methods: []core.ParsedMethod{
+							{Name: "String", ReceiverType: "Point", Results: []core.ParsedResult{{Type: str}}},
+							{Name: "Equal", ReceiverType: "Point", Params: []core.ParsedParam{{Name: "o", Type: point}}, Results: []core.ParsedResult{{Type: boolType}}},
+							{Name: "Less", ReceiverType: "Point", Params: []core.ParsedParam{{Name: "o", Type: core.ParsedType{Kind: core.KindPointer, Name: "*Point", ElemType: &point}}}, Results: []core.ParsedResult{{Type: boolType}}},
+							{Name: "Get", ReceiverType: "Point", Params: []core.ParsedParam{{Name: "i", Type: intType}}, Results: []core.ParsedResult{{Type: intType}}},
+						},
+					},
+					{
+						Name: "Path",
+						Methods: []core.ParsedMethod{
+							{Name: "Len", ReceiverType: "Path", Results: []core.ParsedResult{{Type: intType}}},
+							{Name: "At", ReceiverType: "Path", Params: []core.ParsedParam{{Name: "i", Type: intType}}, Results: []core.ParsedResult{{Type: point}}},
+							{Name: "Less", ReceiverType: "Path", Params: []core.ParsedParam{{Name: "i", Type: intType}, {Name: "j", Type: intType}}, Results: []core.ParsedResult{{Type: boolType}}},
+						},
+					},
+				},
+			}
+
+			code, err := plugin.Generate(pkg)
+			Expect(err).NotTo(HaveOccurred())
+
+			codeStr := string(code)
+			pointClass := codeStr[strings.Index(codeStr, "class Point:"):strings.Index(codeStr, "class Path:")]
+			Expect(pointClass).To(ContainSubstring("    def __str__(self) -> str:\n        return self.string()\n"))
+			Expect(pointClass).To(ContainSubstring(`return f"<Point {self.string()}>"`))
+			Expect(pointClass).To(ContainSubstring("        return self.equal(other)\n"))
+			Expect(pointClass).To(ContainSubstring("    __hash__ = None"))
+			Expect(pointClass).To(ContainSubstring("    def __lt__(self, other: object) -> bool:"))
+			Expect(pointClass).NotTo(ContainSubstring("__len__"))
+			Expect(pointClass).NotTo(ContainSubstring("__getitem__"), "indexing needs Len to check bounds")
+
+			pathClass := codeStr[strings.Index(codeStr, "class Path:"):]
+			Expect(pathClass).To(ContainSubstring("    def __len__(self) -> int:\n        return self.len()\n"))
+			Expect(pathClass).To(ContainSubstring("        if not 0 <= index < len(self):\n"))
+			Expect(pathClass).To(ContainSubstring("        return self.at(index)\n"))
+			Expect(pathClass).To(ContainSubstring("return self._handle == other._handle"))
+			Expect(pathClass).NotTo(ContainSubstring("__lt__"), "sort.Interface Less takes indices")
+		})
+
+		It("wraps returned iterators in generators", func() {
+			intType := core.ParsedType{Kind: core.KindPrimitive, Name: "int"}
+			str := core.ParsedType{Kind: core.KindString, Name: "string"}
+			point := core.ParsedType{Kind: core.KindStruct, Name: "Point"}
+			pkg := &core.ParsedPackage{
+				Name:       "test",
+				ImportPath: "github.com/test/test",
+				Functions: []core.ParsedFunc{
+					{Name: "Names", Results: []core.ParsedResult{{Type: core.ParsedType{Kind: core.KindIter, Name: "iter.Seq2[int, string]", KeyType: &intType, ElemType: &str}}}},
+				},
+				Structs: []core.ParsedStruct{{
+					Name: "Path",
+					Methods: []core.ParsedMethod{
+						{Name: "All", ReceiverType: "Path", Results: []core.ParsedResult{{Type: core.ParsedType{Kind: core.KindIter, Name: "iter.Seq[Point]", ElemType: &point}}}},
+					},
+				}, {Name: "Point"}},
+			}
+
+			code, err := plugin.Generate(pkg)
+			Expect(err).NotTo(HaveOccurred())
+
+			codeStr := string(code)
+			Expect(codeStr).To(ContainSubstring("lib.Iter_Free.argtypes = [c_size_t]"))
+			Expect(codeStr).To(ContainSubstring("lib.test_Names_Next.argtypes = [c_size_t, POINTER(c_longlong), POINTER(c_void_p)]"))
+			Expect(codeStr).To(ContainSubstring("def _iterate(handle: int, pull: Any) -> Iterator[Any]:"))
+			Expect(codeStr).To(ContainSubstring("def names() -> Iterator[Tuple[int, str]]:"))
+			Expect(codeStr).To(ContainSubstring("        if not lib.test_Names_Next(it, byref(_key), byref(_value)):\n"))
+			Expect(codeStr).To(ContainSubstring("        return True, (_key.value, _value_str)\n    return _iterate(_result, _pull)\n"))
+			Expect(codeStr).To(ContainSubstring("            return True, Point._from_handle(_value.value)\n"))
+			Expect(codeStr).To(ContainSubstring("    def __iter__(self) -> Iterator[Point]:\n        return self.all()\n"))
+		})
+
+		It("passes numeric slices and arrays through the buffer protocol", func() {
+			float64Type := core.ParsedType{Kind: core.KindPrimitive, Name: "float64"}
+			float32Type := core.ParsedType{Kind: core.KindPrimitive, Name: "float32"}
+			floats := core.ParsedType{Kind: core.KindSlice, Name: "[]float64", ElemType: &float64Type}
+			row := core.ParsedType{Kind: core.KindSlice, Name: "[]float32", ElemType: &float32Type}
+			rows := core.ParsedType{Kind: core.KindSlice, Name: "[][]float32", ElemType: &row}
+			triple := core.ParsedType{Kind: core.KindArray, Name: "[3]float64", ElemType: &float64Type, Size: 3}
+			pkg := &core.ParsedPackage{
+				Name:       "test",
+				ImportPath: "github.com/test/test",
+				Functions: []core.ParsedFunc{
+					{Name: "Sum", Params: []core.ParsedParam{{Name: "xs", Type: floats, Borrowed: true}}, Results: []core.ParsedResult{{Type: float64Type}}},
+					{Name: "RowSums", Params: []core.ParsedParam{{Name: "m", Type: rows, Borrowed: true}}, Results: []core.ParsedResult{{Type: row}}},
+					{Name: "Grid", Results: []core.ParsedResult{{Type: rows}}},
+					{Name: "Triple", Params: []core.ParsedParam{{Name: "v", Type: triple}}, Results: []core.ParsedResult{{Type: triple}}},
+				},
+			}
+
+			code, err := plugin.Generate(pkg)
+			Expect(err).NotTo(HaveOccurred())
+
+			codeStr := string(code)
+			Expect(codeStr).To(ContainSubstring("    import numpy as _np\n"))
+			Expect(codeStr).To(ContainSubstring("lib.test_Sum.argtypes = [c_void_p, c_longlong]"))
+			Expect(codeStr).To(ContainSubstring("lib.test_RowSums.argtypes = [c_void_p, c_void_p, c_longlong, POINTER(c_longlong)]"))
+			Expect(codeStr).To(ContainSubstring("def sum(xs: Any) -> float:\n    lib = get_library()\n    _xs, _xs_ptr, _xs_len = _buffer_arg(xs, \"float64\")\n"))
+			Expect(codeStr).To(ContainSubstring("_m, _m_ptr, _m_lens, _m_rows = _rows_arg(m, \"float32\")"))
+			Expect(codeStr).To(ContainSubstring("return _buffer_result(_result, _out_len.value, \"float32\")"))
+			Expect(codeStr).To(ContainSubstring("return _rows_result(_result, _out_lens.value, _out_rows.value, \"float32\")"))
+			Expect(codeStr).To(ContainSubstring("raise ValueError(f\"v must have 3 elements, got {_v_len}\")"))
+			Expect(codeStr).To(ContainSubstring("return _buffer_result(_result, 3, \"float64\")"))
+		})
+
+		It("passes byte slices as uint8 buffers", func() {
+			byteType := core.ParsedType{Kind: core.KindPrimitive, Name: "byte"}
+			raw := core.ParsedType{Kind: core.KindSlice, Name: "[]byte", ElemType: &byteType}
+			pkg := &core.ParsedPackage{
+				Name:       "test",
+				ImportPath: "github.com/test/test",
+				Functions:  []core.ParsedFunc{{Name: "Echo", Params: []core.ParsedParam{{Name: "b", Type: raw}}, Results: []core.ParsedResult{{Type: raw}}}},
+			}
+
+			code, err := plugin.Generate(pkg)
+			Expect(err).NotTo(HaveOccurred())
+
+			codeStr := string(code)
+			Expect(codeStr).To(ContainSubstring("_b, _b_ptr, _b_len = _buffer_arg(b, \"uint8\")"))
+			Expect(codeStr).To(ContainSubstring("return _buffer_result(_result, _out_len.value, \"uint8\")"))
+		})
+
+		It("skips the buffer helpers for packages without numeric slices", func() {
+			code, err := plugin.Generate(&core.ParsedPackage{Name: "test", ImportPath: "github.com/test/test"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(code)).NotTo(ContainSubstring("_buffer_arg"))
+			Expect(string(code)).NotTo(ContainSubstring("numpy"))
+		})
+
+		It("generates coroutines awaiting goroutines in async mode", func() {
+			ctx := core.ParsedType{Kind: core.KindStruct, Name: "Context", PackagePath: "context"}
+			str := core.ParsedType{Kind: core.KindString, Name: "string"}
+			pkg := &core.ParsedPackage{
+				Name:       "test",
+				ImportPath: "github.com/test/test",
+				Functions: []core.ParsedFunc{
+					{Name: "Fetch", Params: []core.ParsedParam{{Name: "ctx", Type: ctx}, {Name: "url", Type: str}}, Results: []core.ParsedResult{{Type: str}, {Type: core.ParsedType{Kind: core.KindError, Name: "error"}}}},
+				},
+				Structs: []core.ParsedStruct{{Name: "Client", Methods: []core.ParsedMethod{{Name: "Close", ReceiverType: "Client", ReceiverIsPtr: true}}}},
+			}
+
+			code, err := plugin.Generate(pkg)
+			Expect(err).NotTo(HaveOccurred())
+			codeStr := string(code)
+			Expect(codeStr).To(ContainSubstring("def fetch(url: str) -> str:"))
+			Expect(codeStr).To(ContainSubstring("_result = lib.test_Fetch(0, _url, _error)"))
+			Expect(codeStr).NotTo(ContainSubstring("asyncio"))
+			Expect(codeStr).NotTo(ContainSubstring("_async"))
+
+			Expect(core.ApplyOptions(plugin, map[string]string{"async": "true"})).To(Succeed())
+			code, err = plugin.Generate(pkg)
+			Expect(err).NotTo(HaveOccurred())
+			codeStr = string(code)
+			Expect(codeStr).To(ContainSubstring("import asyncio\n"))
+			Expect(codeStr).To(ContainSubstring("lib.Context_New.restype = c_size_t"))
+			Expect(codeStr).To(ContainSubstring("lib.test_Fetch_Async.argtypes = [c_size_t, c_char_p, POINTER(c_char_p), POINTER(c_void_p), _AsyncDone, c_size_t]"))
+			Expect(codeStr).To(ContainSubstring("async def _go_call(start: Any, finish: Any, cancellable: bool, *keep: Any) -> Any:"))
+			Expect(codeStr).To(ContainSubstring(`async def fetch_async(url: str) -> str:
+    lib = get_library()
+    _url = _encode_string(url)
+    _error = (c_char_p * 1)()
+    _out = c_void_p()
+    def _finish() -> str:
+        _result = _out.value
+        _check_error(_error)
+        _ret = _decode_string(_result)
+        lib.Free_String(_result)
+        return _ret
+    return await _go_call(lambda _ctx, _call: lib.test_Fetch_Async(_ctx, _url, _error, byref(_out), _async_done, _call), _finish, True)
+`))
+			Expect(codeStr).To(ContainSubstring("    async def close_async(self) -> None:\n"))
+			Expect(codeStr).To(ContainSubstring("lib.Client_Close_Async(self._handle, _async_done, _call), _finish, False)"))
+		})
+
+		It("calls exports through a C extension with the capi backend", func() {
+			ctx := core.ParsedType{Kind: core.KindStruct, Name: "Context", PackagePath: "context"}
+			str := core.ParsedType{Kind: core.KindString, Name: "string"}
+			intType := core.ParsedType{Kind: core.KindPrimitive, Name: "int"}
+			float64Type := core.ParsedType{Kind: core.KindPrimitive, Name: "float64"}
+			client := core.ParsedType{Kind: core.KindStruct, Name: "Client"}
+			clientPtr := core.ParsedType{Kind: core.KindPointer, Name: "*Client", ElemType: &client}
+			pkg := &core.ParsedPackage{
+				Name:       "test",
+				ImportPath: "github.com/test/test",
+				Functions: []core.ParsedFunc{
+					{Name: "Fetch", Params: []core.ParsedParam{{Name: "ctx", Type: ctx}, {Name: "url", Type: str}}, Results: []core.ParsedResult{{Type: str}, {Type: core.ParsedType{Kind: core.KindError, Name: "error"}}}},
+					{Name: "Sum", Params: []core.ParsedParam{{Name: "xs", Type: core.ParsedType{Kind: core.KindSlice, Name: "[]float64", ElemType: &float64Type}}}, Results: []core.ParsedResult{{Type: float64Type}}},
+				},
+				Structs: []core.ParsedStruct{{
+					Name:   "Client",
+					Fields: []core.ParsedField{{Name: "Port", Type: intType, Exported: true}},
+					Methods: []core.ParsedMethod{
+						{Name: "Peer", ReceiverType: "Client", ReceiverIsPtr: true, Results: []core.ParsedResult{{Type: clientPtr, Ownership: core.OwnershipOwned}}},
+					},
+				}},
+			}
+
+			Expect(core.ApplyOptions(plugin, map[string]string{"backend": "capi"})).To(Succeed())
+			// generate cannot write the extension the bindings import
+			_, err := plugin.Generate(pkg)
+			Expect(err).To(MatchError(errGenerateCapi))
+
+			code, err := plugin.generate(pkg, nil, pkg.Name)
+			Expect(err).NotTo(HaveOccurred())
+			codeStr := string(code)
+			Expect(codeStr).To(ContainSubstring("    from . import _test_native as _native\n"))
+			Expect(codeStr).To(ContainSubstring("_native.set_loader(get_library)\n"))
+			Expect(codeStr).To(ContainSubstring("    _native.bind([cast(getattr(lib, name), c_void_p).value for name in _native.SYMBOLS])\n"))
+			Expect(codeStr).To(ContainSubstring("def fetch(url: str) -> str:\n    return _native.test_Fetch(url)\n"))
+			Expect(codeStr).To(ContainSubstring("    def port(self) -> int:\n        \"\"\"Get Port.\"\"\"\n        return _native.Client_GetPort(self._handle)\n"))
+			Expect(codeStr).To(ContainSubstring("        _native.Client_SetPort(self._handle, value)\n"))
+			Expect(codeStr).To(ContainSubstring("        return Client._from_handle(_native.Client_Peer(self._handle))\n"))
+			// Buffers are left to ctypes
+			Expect(codeStr).To(ContainSubstring("    _result = lib.test_Sum(_xs_ptr, _xs_len)\n"))
+
+			source := string(plugin.extensionSource())
+			Expect(source).To(ContainSubstring("#include <Python.h>"))
+			Expect(source).To(ContainSubstring("    SYM_Free_String,\n    SYM_test_Fetch,\n    SYM_Client_GetPort,\n    SYM_Client_SetPort,\n    SYM_Client_Peer,\n    SYM_COUNT\n"))
+			Expect(source).NotTo(ContainSubstring("test_Sum"))
+			Expect(source).To(ContainSubstring("typedef char * (*test_Fetch_fn)(uintptr_t, char *, char **);"))
+			Expect(source).To(ContainSubstring(`    if (goanywhere_arity("test_Fetch", nargs, 1) < 0)
+        return NULL;
+    if (goanywhere_string(args[0], &a0) < 0)
+        return NULL;
+    Py_BEGIN_ALLOW_THREADS
+    result = ((test_Fetch_fn)goanywhere_symbols[SYM_test_Fetch])((uintptr_t)0, (char *)a0, &error);
+    Py_END_ALLOW_THREADS
+    if (error != NULL) {
+        goanywhere_free_string(result);
+        return goanywhere_error(error);
+    }
+    return goanywhere_string_result(result);
+`))
+			Expect(source).To(ContainSubstring(`{"Client_Peer", (PyCFunction)(void (*)(void))native_Client_Peer, METH_FASTCALL, NULL},`))
+			Expect(source).To(ContainSubstring("PyInit__test_native(void)"))
+		})
+
+		It("keeps buffers passed to async calls alive", func() {
+			float64Type := core.ParsedType{Kind: core.KindPrimitive, Name: "float64"}
+			floats := core.ParsedType{Kind: core.KindSlice, Name: "[]float64", ElemType: &float64Type}
+			pkg := &core.ParsedPackage{
+				Name:       "test",
+				ImportPath: "github.com/test/test",
+				Functions: []core.ParsedFunc{
+					{Name: "Sum", Params: []core.ParsedParam{{Name: "xs", Type: floats, Borrowed: true}}, Results: []core.ParsedResult{{Type: float64Type}}},
+				},
+			}
+
+			Expect(core.ApplyOptions(plugin, map[string]string{"async": "true"})).To(Succeed())
+			code, err := plugin.Generate(pkg)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(code)).To(ContainSubstring("lib.test_Sum_Async(_xs_ptr, _xs_len, byref(_out), _async_done, _call), _finish, False, _xs)"))
+		})
+
 		It("handles string parameters and returns", func() {
 			pkg := &core.ParsedPackage{
 				Name:       "test",
@@ -159,6 +546,50 @@ var _ = Describe("Plugin", func() {
 			Expect(codeStr).To(ContainSubstring("_decode_string"))
 		})
 
+		It("generates bundle modules referencing sibling classes", func() {
+			point := core.ParsedType{Kind: core.KindStruct, Name: "Point", PackagePath: "github.com/test/geo"}
+			geo := &core.ParsedPackage{Name: "geo", ImportPath: "github.com/test/geo", ExportPrefix: "geo"}
+			shapes := &core.ParsedPackage{
+				Name:         "shapes",
+				ImportPath:   "github.com/test/shapes",
+				ExportPrefix: "shapes",
+				Functions: []core.ParsedFunc{{
+					Name:    "Shift",
+					Params:  []core.ParsedParam{{Name: "p", Type: core.ParsedType{Kind: core.KindPointer, Name: "*geo.Point", ElemType: &point}}},
+					Results: []core.ParsedResult{{Type: core.ParsedType{Kind: core.KindPointer, Name: "*geo.Point", ElemType: &point}}},
+				}},
+			}
+
+			code, err := plugin.GenerateBundleModule(shapes, []*core.ParsedPackage{geo, shapes}, "libgeo_shapes")
+			Expect(err).NotTo(HaveOccurred())
+
+			codeStr := string(code)
+			Expect(codeStr).To(ContainSubstring("from . import geo as _geo"))
+			Expect(codeStr).NotTo(ContainSubstring("import shapes as"))
+			Expect(codeStr).To(ContainSubstring(`lib_name = "geo_shapes"`))
+			Expect(codeStr).To(ContainSubstring("def shift(p: _geo.Point) -> _geo.Point:"))
+			Expect(codeStr).To(ContainSubstring("return _geo.Point._from_handle(_result)"))
+		})
+
+		It("uses prefixed symbol names", func() {
+			pkg := &core.ParsedPackage{
+				Name:         "test",
+				ImportPath:   "github.com/test/test",
+				ExportPrefix: "tst",
+				Functions:    []core.ParsedFunc{{Name: "Ping"}},
+				Structs:      []core.ParsedStruct{{Name: "Conn"}},
+			}
+
+			code, err := plugin.Generate(pkg)
+			Expect(err).NotTo(HaveOccurred())
+
+			codeStr := string(code)
+			Expect(codeStr).To(ContainSubstring("lib.tst_Ping"))
+			Expect(codeStr).To(ContainSubstring("lib.tst_Conn_New"))
+			Expect(codeStr).To(ContainSubstring("lib.tst_Conn_Free"))
+			Expect(codeStr).To(ContainSubstring("class Conn"))
+		})
+
 		It("handles error returns", func() {
 			pkg := &core.ParsedPackage{
 				Name:       "test",
@@ -387,6 +818,58 @@ var _ = Describe("Plugin", func() {
 		})
 	})
 
+	Describe("ReportBindings", func() {
+		It("describes the Python API and diagnostics", func() {
+			intType := core.ParsedType{Kind: core.KindPrimitive, Name: "int"}
+			pkg := &core.ParsedPackage{
+				Name: "geo",
+				Functions: []core.ParsedFunc{
+					{Name: "AddOne", Params: []core.ParsedParam{{Name: "a", Type: intType}}, Results: []core.ParsedResult{{Type: intType}}},
+					{Name: "DivMod", Results: []core.ParsedResult{{Type: intType}, {Type: intType}}},
+				},
+				Structs: []core.ParsedStruct{
+					{
+						Name: "Point",
+						Fields: []core.ParsedField{
+							{Name: "X", Type: intType, Exported: true},
+							{Name: "Tags", Type: core.ParsedType{Kind: core.KindSlice, Name: "[]int", ElemType: &intType}, Exported: true},
+						},
+						Methods: []core.ParsedMethod{
+							{Name: "Scale", ReceiverType: "Point", Params: []core.ParsedParam{{Name: "factor", Type: intType}}},
+							{Name: "Sum", ReceiverType: "Point", IsVariadic: true},
+						},
+					},
+				},
+			}
+
+			report, err := plugin.ReportBindings(pkg)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Bindings).To(Equal([]core.Binding{
+				{Decl: core.Decl{Symbol: "AddOne", Kind: core.DeclFunction}, Name: "add_one", Signature: "add_one(a: int) -> int"},
+				{Decl: core.Decl{Symbol: "Point", Kind: core.DeclStruct}, Name: "Point", Signature: "class Point"},
+				{Decl: core.Decl{Symbol: "Point.X", Kind: core.DeclField}, Name: "Point.x", Signature: "x: int"},
+				{Decl: core.Decl{Symbol: "Point.Tags", Kind: core.DeclField}, Name: "Point.tags", Signature: "tags: Any (read-only)"},
+				{Decl: core.Decl{Symbol: "Point.Scale", Kind: core.DeclMethod}, Name: "Point.scale", Signature: "scale(self, factor: int) -> None"},
+			}))
+			Expect(report.Diagnostics).To(Equal([]core.Diagnostic{
+				{Severity: core.SeverityWarning, Decl: core.Decl{Symbol: "DivMod", Kind: core.DeclFunction}, Reason: "multiple return values are not supported"},
+				{Severity: core.SeverityWarning, Decl: core.Decl{Symbol: "Point.Sum", Kind: core.DeclMethod}, Reason: "variadic parameters are not supported"},
+			}))
+		})
+
+		It("leaves functions with several results out of the library setup", func() {
+			intType := core.ParsedType{Kind: core.KindPrimitive, Name: "int"}
+			pkg := &core.ParsedPackage{
+				Name:      "geo",
+				Functions: []core.ParsedFunc{{Name: "DivMod", Results: []core.ParsedResult{{Type: intType}, {Type: intType}}}},
+			}
+
+			code, err := plugin.Generate(pkg)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(code)).NotTo(ContainSubstring("geo_DivMod"))
+		})
+	})
+
 	Describe("toSnakeCase", func() {
 		It("converts camelCase to snake_case", func() {
 			Expect(toSnakeCase("helloWorld")).To(Equal("hello_world"))
@@ -598,6 +1081,37 @@ var _ = Describe("Plugin", func() {
 		})
 	})
 
+	Describe("Build with a static link", func() {
+		It("rejects static archives", func() {
+			opts := &core.BuildOptions{OutputDir: os.TempDir(), Link: core.LinkStatic}
+			err := plugin.Build(&core.ParsedPackage{Name: "foo"}, ".", opts)
+			Expect(err).To(MatchError(ContainSubstring("--link static is not supported")))
+
+			err = plugin.BuildBundle([]*core.ParsedPackage{{Name: "foo"}}, []string{"."}, opts)
+			Expect(err).To(MatchError(ContainSubstring("--link static is not supported")))
+		})
+	})
+
+	Describe("Build with a shared library", func() {
+		It("packages the given library instead of building one", func() {
+			libDir, outputDir := GinkgoT().TempDir(), GinkgoT().TempDir()
+			libFile := filepath.Join(libDir, "libfoo"+getSharedLibExtension())
+			Expect(os.WriteFile(libFile, []byte("library"), 0644)).To(Succeed())
+
+			opts := &core.BuildOptions{OutputDir: outputDir, SharedLibrary: libFile}
+			Expect(plugin.Build(&core.ParsedPackage{Name: "foo"}, "/nonexistent", opts)).To(Succeed())
+
+			packaged, err := os.ReadFile(filepath.Join(outputDir, "foo", "lib", "libfoo"+getSharedLibExtension()))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(packaged)).To(Equal("library"))
+			Expect(filepath.Join(outputDir, "foo", "bindings.py")).To(BeAnExistingFile())
+
+			wheels, err := filepath.Glob(filepath.Join(outputDir, "dist", "foo-*-py3-none-*.whl"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(wheels).To(HaveLen(1))
+		})
+	})
+
 	Describe("getSharedLibExtension", func() {
 		It("returns platform-specific extension", func() {
 			ext := getSharedLibExtension()
@@ -606,32 +1120,34 @@ var _ = Describe("Plugin", func() {
 	})
 
 	Describe("generatePyprojectToml", func() {
+		meta := packageMetadata{Name: "mypackage", Version: "1.0.0", RequiresPython: ">=3.8"}
+
 		It("generates setuptools config", func() {
-			content := generatePyprojectToml("mypackage", "setuptools")
+			content := generatePyprojectToml(meta, "mypackage", "setuptools")
 			Expect(content).To(ContainSubstring("setuptools"))
 			Expect(content).To(ContainSubstring("mypackage"))
 		})
 
 		It("generates hatch config", func() {
-			content := generatePyprojectToml("mypackage", "hatch")
+			content := generatePyprojectToml(meta, "mypackage", "hatch")
 			Expect(content).To(ContainSubstring("hatchling"))
 			Expect(content).To(ContainSubstring("mypackage"))
 		})
 
 		It("generates poetry config", func() {
-			content := generatePyprojectToml("mypackage", "poetry")
+			content := generatePyprojectToml(meta, "mypackage", "poetry")
 			Expect(content).To(ContainSubstring("poetry"))
 			Expect(content).To(ContainSubstring("mypackage"))
 		})
 
 		It("generates uv config", func() {
-			content := generatePyprojectToml("mypackage", "uv")
+			content := generatePyprojectToml(meta, "mypackage", "uv")
 			Expect(content).To(ContainSubstring("hatchling"))
 			Expect(content).To(ContainSubstring("mypackage"))
 		})
 
 		It("defaults to setuptools for unknown system", func() {
-			content := generatePyprojectToml("mypackage", "unknown")
+			content := generatePyprojectToml(meta, "mypackage", "unknown")
 			Expect(content).To(ContainSubstring("setuptools"))
 		})
 	})
@@ -0,0 +1,313 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package python
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"debug/elf"
+	"debug/macho"
+	"encoding/base64"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/riceriley59/goanywhere/internal/version"
+)
+
+// wheelTime is the modification time of every wheel entry, so that the same
+// package always produces the same wheel. Zip cannot encode earlier times.
+var wheelTime = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// wheelFile is a file of a wheel and its contents
+type wheelFile struct {
+	name string // Slash-separated path inside the wheel
+	data []byte
+	mode fs.FileMode
+}
+
+// writeWheel zips the Python package in pkgDir into a wheel of the
+// distribution described by meta under distDir and returns its path. The
+// wheel holds the package with its shared library and the .dist-info
+// metadata; its platform tag is read from the library. Packages with C
+// extensions pass the interpreter tag they were compiled for, such as cp312,
+// and "" otherwise.
+func writeWheel(distDir, pkgDir, pythonPkgName, libFile, interpreter string, meta packageMetadata) (string, error) {
+	platform, err := platformTag(libFile)
+	if err != nil {
+		return "", err
+	}
+	tag := "py3-none-" + platform
+	if interpreter != "" {
+		tag = interpreter + "-" + interpreter + "-" + platform
+	}
+
+	var files []wheelFile
+	err = filepath.WalkDir(pkgDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == "__pycache__" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(pkgDir, path)
+		if err != nil {
+			return err
+		}
+		mode := fs.FileMode(0644)
+		if info.Mode()&0111 != 0 || strings.HasPrefix(filepath.ToSlash(rel), "lib/") {
+			mode = 0755
+		}
+		files = append(files, wheelFile{name: pythonPkgName + "/" + filepath.ToSlash(rel), data: data, mode: mode})
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("cannot read Python package: %w", err)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].name < files[j].name })
+
+	distInfo := fmt.Sprintf("%s-%s.dist-info", meta.distName(), meta.Version)
+	files = append(files,
+		wheelFile{name: distInfo + "/METADATA", data: []byte(wheelMetadata(meta)), mode: 0644},
+		wheelFile{name: distInfo + "/WHEEL", data: []byte(wheelInfo(tag)), mode: 0644},
+	)
+
+	// RECORD lists every file with its hash, and itself without one
+	var record strings.Builder
+	for _, file := range files {
+		sum := sha256.Sum256(file.data)
+		fmt.Fprintf(&record, "%s,sha256=%s,%d\n", file.name, base64.RawURLEncoding.EncodeToString(sum[:]), len(file.data))
+	}
+	fmt.Fprintf(&record, "%s/RECORD,,\n", distInfo)
+	files = append(files, wheelFile{name: distInfo + "/RECORD", data: []byte(record.String()), mode: 0644})
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, file := range files {
+		header := &zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: wheelTime}
+		header.SetMode(file.mode)
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			return "", fmt.Errorf("cannot write wheel: %w", err)
+		}
+		if _, err := w.Write(file.data); err != nil {
+			return "", fmt.Errorf("cannot write wheel: %w", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return "", fmt.Errorf("cannot write wheel: %w", err)
+	}
+
+	if err := os.MkdirAll(distDir, 0755); err != nil {
+		return "", fmt.Errorf("cannot create dist directory: %w", err)
+	}
+	wheelPath := filepath.Join(distDir, fmt.Sprintf("%s-%s-%s.whl", meta.distName(), meta.Version, tag))
+	if err := os.WriteFile(wheelPath, buf.Bytes(), 0644); err != nil {
+		return "", fmt.Errorf("write error: %w", err)
+	}
+	return wheelPath, nil
+}
+
+// wheelInfo returns the WHEEL file of a wheel with the given tag
+func wheelInfo(tag string) string {
+	return fmt.Sprintf(`Wheel-Version: 1.0
+Generator: goanywhere %s
+Root-Is-Purelib: false
+Tag: %s
+`, version.GetVersion(), tag)
+}
+
+// targetPlatform returns the GOOS and GOARCH the library is built for
+func targetPlatform() (string, string) {
+	goos, goarch := os.Getenv("GOOS"), os.Getenv("GOARCH")
+	if goos == "" {
+		goos = runtime.GOOS
+	}
+	if goarch == "" {
+		goarch = runtime.GOARCH
+	}
+	return goos, goarch
+}
+
+// platformTag returns the wheel platform tag of the library: manylinux for
+// a glibc library linking only libraries every manylinux system provides,
+// with the newest glibc symbol version it needs; otherwise the plain
+// platform tag of the target
+func platformTag(libFile string) (string, error) {
+	goos, goarch := targetPlatform()
+	switch goos {
+	case "linux":
+		arch := linuxArch(goarch)
+		if glibc, ok := manylinuxGlibc(libFile); ok {
+			return fmt.Sprintf("manylinux_%d_%d_%s", glibc[0], glibc[1], arch), nil
+		}
+		return "linux_" + arch, nil
+	case "darwin":
+		arch := map[string]string{"amd64": "x86_64", "arm64": "arm64"}[goarch]
+		if arch == "" {
+			return "", fmt.Errorf("unsupported macOS architecture %s", goarch)
+		}
+		major, minor := macOSMinimum(libFile)
+		return fmt.Sprintf("macosx_%d_%d_%s", major, minor, arch), nil
+	case "windows":
+		switch goarch {
+		case "amd64":
+			return "win_amd64", nil
+		case "arm64":
+			return "win_arm64", nil
+		case "386":
+			return "win32", nil
+		}
+		return "", fmt.Errorf("unsupported Windows architecture %s", goarch)
+	}
+	return "", fmt.Errorf("cannot build wheels for %s/%s", goos, goarch)
+}
+
+// linuxArch returns the Python name of a Go architecture on Linux
+func linuxArch(goarch string) string {
+	switch goarch {
+	case "amd64":
+		return "x86_64"
+	case "386":
+		return "i686"
+	case "arm64":
+		return "aarch64"
+	case "arm":
+		return "armv7l"
+	}
+	return goarch
+}
+
+// manylinuxLibraries are the shared libraries the manylinux policies allow
+// a wheel to link against
+var manylinuxLibraries = map[string]bool{
+	"libc.so.6":             true,
+	"libm.so.6":             true,
+	"libdl.so.2":            true,
+	"librt.so.1":            true,
+	"libpthread.so.0":       true,
+	"libgcc_s.so.1":         true,
+	"libstdc++.so.6":        true,
+	"libresolv.so.2":        true,
+	"libutil.so.1":          true,
+	"libnsl.so.1":           true,
+	"libcrypt.so.1":         true,
+	"ld-linux-x86-64.so.2":  true,
+	"ld-linux-aarch64.so.1": true,
+	"ld-linux.so.2":         true,
+}
+
+// manylinuxBaseline is the oldest glibc of the manylinux policies pip
+// installs on every supported system (manylinux2014)
+var manylinuxBaseline = [2]int{2, 17}
+
+// manylinuxGlibc returns the glibc version a manylinux tag of libFile needs,
+// or false if the library is not a glibc library or links a library outside
+// the manylinux policy
+func manylinuxGlibc(libFile string) ([2]int, bool) {
+	f, err := elf.Open(libFile)
+	if err != nil {
+		return [2]int{}, false
+	}
+	defer func() { _ = f.Close() }()
+
+	libs, err := f.ImportedLibraries()
+	if err != nil {
+		return [2]int{}, false
+	}
+	for _, lib := range libs {
+		if !manylinuxLibraries[lib] {
+			return [2]int{}, false
+		}
+	}
+
+	symbols, err := f.DynamicSymbols()
+	if err != nil {
+		return [2]int{}, false
+	}
+	needed := manylinuxBaseline
+	glibc := false
+	for _, sym := range symbols {
+		v, ok := strings.CutPrefix(sym.Version, "GLIBC_")
+		if !ok {
+			continue
+		}
+		glibc = true
+		major, minor, ok := parseVersion(v)
+		if ok && (major > needed[0] || major == needed[0] && minor > needed[1]) {
+			needed = [2]int{major, minor}
+		}
+	}
+	return needed, glibc
+}
+
+// parseVersion parses the major and minor numbers of a dotted version
+func parseVersion(v string) (int, int, bool) {
+	parts := strings.Split(v, ".")
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
+
+// lcBuildVersion is the Mach-O load command holding the minimum OS version
+const lcBuildVersion = 0x32
+
+// macOSMinimum returns the minimum macOS version of libFile, or the oldest
+// version the Go toolchain supports if the library does not say
+func macOSMinimum(libFile string) (int, int) {
+	f, err := macho.Open(libFile)
+	if err != nil {
+		return 12, 0
+	}
+	defer func() { _ = f.Close() }()
+
+	for _, load := range f.Loads {
+		raw := load.Raw()
+		if len(raw) < 16 || f.ByteOrder.Uint32(raw) != lcBuildVersion {
+			continue
+		}
+		// cmd, cmdsize, platform, then minos encoded as xxxx.yy.zz
+		minos := f.ByteOrder.Uint32(raw[12:16])
+		return int(minos >> 16), int(minos >> 8 & 0xff)
+	}
+	return 12, 0
+}
@@ -0,0 +1,141 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package python
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// readWheel returns the contents of every file of a wheel
+func readWheel(path string) map[string]string {
+	r, err := zip.OpenReader(path)
+	Expect(err).NotTo(HaveOccurred())
+	defer func() { _ = r.Close() }()
+
+	files := make(map[string]string)
+	for _, f := range r.File {
+		rc, err := f.Open()
+		Expect(err).NotTo(HaveOccurred())
+		data, err := io.ReadAll(rc)
+		Expect(err).NotTo(HaveOccurred())
+		_ = rc.Close()
+		files[f.Name] = string(data)
+	}
+	return files
+}
+
+var _ = Describe("Wheel", func() {
+	var pkgDir, distDir string
+	meta := packageMetadata{Name: "geo", Version: "0.1.0", RequiresPython: ">=3.8"}
+
+	BeforeEach(func() {
+		GinkgoT().Setenv("GOOS", "linux")
+		GinkgoT().Setenv("GOARCH", "amd64")
+
+		pkgDir = filepath.Join(GinkgoT().TempDir(), "geo")
+		distDir = filepath.Join(GinkgoT().TempDir(), "dist")
+		Expect(os.MkdirAll(filepath.Join(pkgDir, "lib"), 0755)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(pkgDir, "__pycache__"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(pkgDir, "__init__.py"), []byte("from .bindings import *\n"), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(pkgDir, "bindings.py"), []byte("# bindings\n"), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(pkgDir, "lib", "libgeo.so"), []byte("library"), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(pkgDir, "__pycache__", "bindings.pyc"), []byte("bytecode"), 0644)).To(Succeed())
+	})
+
+	It("zips the package with its metadata and library", func() {
+		wheel, err := writeWheel(distDir, pkgDir, "geo", filepath.Join(pkgDir, "lib", "libgeo.so"), "", meta)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wheel).To(Equal(filepath.Join(distDir, "geo-0.1.0-py3-none-linux_x86_64.whl")))
+
+		files := readWheel(wheel)
+		Expect(files).To(HaveKey("geo/__init__.py"))
+		Expect(files).To(HaveKey("geo/bindings.py"))
+		Expect(files).To(HaveKeyWithValue("geo/lib/libgeo.so", "library"))
+		Expect(files).NotTo(HaveKey("geo/__pycache__/bindings.pyc"))
+		Expect(files["geo-0.1.0.dist-info/METADATA"]).To(ContainSubstring("Name: geo\nVersion: 0.1.0\n"))
+		Expect(files["geo-0.1.0.dist-info/WHEEL"]).To(ContainSubstring("Root-Is-Purelib: false\nTag: py3-none-linux_x86_64\n"))
+	})
+
+	It("tags wheels with C extensions for the interpreter they were compiled for", func() {
+		wheel, err := writeWheel(distDir, pkgDir, "geo", filepath.Join(pkgDir, "lib", "libgeo.so"), "cp312", meta)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wheel).To(Equal(filepath.Join(distDir, "geo-0.1.0-cp312-cp312-linux_x86_64.whl")))
+		Expect(readWheel(wheel)["geo-0.1.0.dist-info/WHEEL"]).To(ContainSubstring("Tag: cp312-cp312-linux_x86_64\n"))
+	})
+
+	It("records the hash and size of every file", func() {
+		wheel, err := writeWheel(distDir, pkgDir, "geo", filepath.Join(pkgDir, "lib", "libgeo.so"), "", meta)
+		Expect(err).NotTo(HaveOccurred())
+
+		files := readWheel(wheel)
+		record := strings.Split(strings.TrimSpace(files["geo-0.1.0.dist-info/RECORD"]), "\n")
+		Expect(record).To(HaveLen(len(files)))
+		for _, line := range record {
+			fields := strings.Split(line, ",")
+			Expect(fields).To(HaveLen(3))
+			if fields[0] == "geo-0.1.0.dist-info/RECORD" {
+				Expect(fields[1:]).To(Equal([]string{"", ""}))
+				continue
+			}
+			data, ok := files[fields[0]]
+			Expect(ok).To(BeTrue(), fields[0])
+			sum := sha256.Sum256([]byte(data))
+			Expect(fields[1]).To(Equal("sha256=" + base64.RawURLEncoding.EncodeToString(sum[:])))
+			Expect(fields[2]).To(Equal(fmt.Sprint(len(data))))
+		}
+	})
+
+	It("writes the same wheel for the same package", func() {
+		first, err := writeWheel(distDir, pkgDir, "geo", filepath.Join(pkgDir, "lib", "libgeo.so"), "", meta)
+		Expect(err).NotTo(HaveOccurred())
+		data, err := os.ReadFile(first)
+		Expect(err).NotTo(HaveOccurred())
+
+		second, err := writeWheel(distDir, pkgDir, "geo", filepath.Join(pkgDir, "lib", "libgeo.so"), "", meta)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.ReadFile(second)).To(Equal(data))
+	})
+
+	Describe("platformTag", func() {
+		It("maps the target platform", func() {
+			for _, platform := range []struct{ goos, goarch, tag string }{
+				{"linux", "arm64", "linux_aarch64"},
+				{"windows", "amd64", "win_amd64"},
+				{"windows", "386", "win32"},
+				{"darwin", "arm64", "macosx_12_0_arm64"},
+			} {
+				GinkgoT().Setenv("GOOS", platform.goos)
+				GinkgoT().Setenv("GOARCH", platform.goarch)
+				Expect(platformTag("/nonexistent")).To(Equal(platform.tag))
+			}
+		})
+
+		It("rejects platforms without wheels", func() {
+			GinkgoT().Setenv("GOOS", "plan9")
+			_, err := platformTag("/nonexistent")
+			Expect(err).To(MatchError(ContainSubstring("cannot build wheels for plan9")))
+		})
+	})
+})
@@ -0,0 +1,71 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/riceriley59/goanywhere/internal/core"
+	"github.com/riceriley59/goanywhere/plugins/cgo"
+)
+
+var reproducibleModule = map[string]string{
+	"go.mod":   "module example.com/repro\n\ngo 1.22\n",
+	"add.go":   "package repro\n\n// Add adds two integers\nfunc Add(a, b int) int {\n\treturn a + b\n}\n",
+	"greet.go": "package repro\n\n// Greet returns a greeting\nfunc Greet(name string) string {\n\treturn \"Hello, \" + name\n}\n",
+	"point.go": "package repro\n\n// Point represents a 2D point\ntype Point struct {\n\tX int\n\tY int\n}\n",
+}
+
+var _ = Describe("Reproducible build", func() {
+	// build writes the module under dir and returns the built library
+	build := func(dir string) []byte {
+		Expect(os.MkdirAll(dir, 0755)).To(Succeed())
+		for name, content := range reproducibleModule {
+			Expect(os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)).To(Succeed())
+		}
+		pkg, err := core.NewParser(false).ParsePackage(dir)
+		Expect(err).NotTo(HaveOccurred())
+		pkg.ImportPath = "example.com/repro"
+
+		outputDir := GinkgoT().TempDir()
+		err = cgo.NewPlugin(false).Build(pkg, dir, &core.BuildOptions{
+			OutputDir:    outputDir,
+			Link:         core.LinkStatic,
+			Reproducible: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		data, err := os.ReadFile(filepath.Join(outputDir, "librepro.a"))
+		Expect(err).NotTo(HaveOccurred())
+		return data
+	}
+
+	It("produces identical output from different checkout paths", func() {
+		if _, err := exec.LookPath("cc"); err != nil {
+			Skip("no C compiler found")
+		}
+
+		root := GinkgoT().TempDir()
+		first := build(filepath.Join(root, "a", "repro"))
+		second := build(filepath.Join(root, "checkout", "nested", "repro"))
+		Expect(bytes.Equal(first, second)).To(BeTrue(), "library differs between checkout paths")
+		Expect(bytes.Contains(first, []byte(root))).To(BeFalse())
+	})
+})
@@ -0,0 +1,93 @@
+// Copyright 2026 Riley Rice
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/riceriley59/goanywhere/internal/core"
+	"github.com/riceriley59/goanywhere/plugins/cgo"
+)
+
+const smokeProgram = `#include <stdio.h>
+#include "libsimple.h"
+
+int main(void) {
+    char *greeting = simple_Greet("C");
+    printf("%lld %s\n", simple_Add(2, 3), greeting);
+    Free_String(greeting);
+    return 0;
+}
+`
+
+var _ = Describe("Static library build", func() {
+	It("links a C program against the archive", func() {
+		cc, err := exec.LookPath("cc")
+		if err != nil {
+			Skip("no C compiler found")
+		}
+		pkgConfig, err := exec.LookPath("pkg-config")
+		if err != nil {
+			Skip("pkg-config not found")
+		}
+
+		wd, _ := os.Getwd()
+		fixtureDir := filepath.Join(wd, "..", "fixtures", "simple")
+		pkg, err := core.NewParser(false).ParsePackage(fixtureDir)
+		Expect(err).NotTo(HaveOccurred())
+		pkg.ImportPath = "github.com/riceriley59/goanywhere/tests/fixtures/simple"
+
+		outputDir := GinkgoT().TempDir()
+		err = cgo.NewPlugin(false).Build(pkg, fixtureDir, &core.BuildOptions{
+			OutputDir: outputDir,
+			Link:      core.LinkStatic,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		for _, file := range []string{"libsimple.a", "libsimple.h", "libsimple.pc", "simpleConfig.cmake", "simpleTargets.cmake", cgo.ChecksumFile} {
+			Expect(filepath.Join(outputDir, file)).To(BeAnExistingFile())
+		}
+
+		// inspect shows the prototypes of the header, which spells long long as long long int
+		header, err := os.ReadFile(filepath.Join(outputDir, "libsimple.h"))
+		Expect(err).NotTo(HaveOccurred())
+		prototypes := strings.ReplaceAll(string(header), "long long int", "long long")
+		report, err := cgo.NewPlugin(false).ReportBindings(pkg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Bindings).NotTo(BeEmpty())
+		for _, binding := range report.Bindings {
+			Expect(prototypes).To(ContainSubstring("extern "+binding.Signature+"\n"), binding.Name)
+		}
+
+		flags, err := exec.Command(pkgConfig, "--cflags", "--libs", filepath.Join(outputDir, "libsimple.pc")).Output()
+		Expect(err).NotTo(HaveOccurred())
+
+		source := filepath.Join(outputDir, "main.c")
+		Expect(os.WriteFile(source, []byte(smokeProgram), 0644)).To(Succeed())
+		program := filepath.Join(outputDir, "smoke")
+		args := append([]string{source, "-o", program}, strings.Fields(string(flags))...)
+		out, err := exec.Command(cc, args...).CombinedOutput()
+		Expect(err).NotTo(HaveOccurred(), string(out))
+
+		out, err = exec.Command(program).CombinedOutput()
+		Expect(err).NotTo(HaveOccurred(), string(out))
+		Expect(string(out)).To(Equal("5 Hello, C\n"))
+	})
+})